@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/capture"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+)
+
+const capturesUsage = `Usage:
+  captures list [--config path] [--dir path] [--reason reason] [--source source] [--limit n]
+  captures dump [--config path] [--dir path] [--body] <id>...
+`
+
+// runCapturesCommand lists the captured responses or dumps them for building parser fixtures.
+// It returns the exit code: 0 on success, 1 when the captures can't be read and 2 on invalid arguments.
+func runCapturesCommand(args []string, output io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(output, capturesUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("captures "+args[0], flag.ContinueOnError)
+	flags.SetOutput(output)
+	configPath := flags.String("config", "configs/config.yaml", "path to config file")
+	directory := flags.String("dir", "", "capture directory, capture.directory of the config if empty")
+
+	switch args[0] {
+	case "list":
+		reason := flags.String("reason", "", "only the captures with the reason")
+		source := flags.String("source", "", "only the captures of the source")
+		limit := flags.Int("limit", 50, "number of the latest captures listed, all if 0")
+
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+
+		captures, err := listCaptures(*configPath, *directory)
+		if err != nil {
+			fmt.Fprintf(output, "Failed to list captures: %v\n", err)
+			return 1
+		}
+
+		printCaptures(output, filterCaptures(captures, *reason, *source, *limit))
+
+		return 0
+
+	case "dump":
+		body := flags.Bool("body", false, "print only the response body, e.g. to save it as a fixture")
+
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+
+		if flags.NArg() == 0 {
+			fmt.Fprint(output, capturesUsage)
+			return 2
+		}
+
+		dir, err := captureDirectory(*configPath, *directory)
+		if err != nil {
+			fmt.Fprintf(output, "Failed to load configuration: %v\n", err)
+			return 1
+		}
+
+		for _, id := range flags.Args() {
+			if err := dumpCapture(output, dir, id, *body); err != nil {
+				fmt.Fprintf(output, "Failed to dump capture: %v\n", err)
+				return 1
+			}
+		}
+
+		return 0
+
+	default:
+		fmt.Fprint(output, capturesUsage)
+		return 2
+	}
+}
+
+// captureDirectory returns the directory of the flag or, without it, of the configuration
+func captureDirectory(configPath, directory string) (string, error) {
+	if directory != "" {
+		return directory, nil
+	}
+
+	inspection, err := config.Inspect(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	return inspection.Config.Capture.Directory, nil
+}
+
+func listCaptures(configPath, directory string) ([]capture.Capture, error) {
+	dir, err := captureDirectory(configPath, directory)
+	if err != nil {
+		return nil, err
+	}
+
+	return capture.List(dir)
+}
+
+// filterCaptures returns up to limit latest captures with the reason and the source, oldest first
+func filterCaptures(captures []capture.Capture, reason, source string, limit int) []capture.Capture {
+	filtered := make([]capture.Capture, 0, len(captures))
+	for _, c := range captures {
+		if (reason == "" || c.Reason == reason) && (source == "" || c.Source == source) {
+			filtered = append(filtered, c)
+		}
+	}
+
+	if limit > 0 && len(filtered) > limit {
+		filtered = filtered[len(filtered)-limit:]
+	}
+
+	return filtered
+}
+
+func printCaptures(output io.Writer, captures []capture.Capture) {
+	table := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, "ID\tREASON\tSOURCE\tSTATUS\tDURATION\tSIZE\tPROXY\tERROR")
+
+	for _, c := range captures {
+		fmt.Fprintf(
+			table,
+			"%s\t%s\t%s\t%d\t%s\t%d\t%s\t%s\n",
+			c.ID,
+			c.Reason,
+			c.Source,
+			c.StatusCode,
+			c.Duration().Round(time.Millisecond),
+			c.Size,
+			c.Proxy,
+			c.Error,
+		)
+	}
+
+	_ = table.Flush()
+}
+
+func dumpCapture(output io.Writer, directory, id string, bodyOnly bool) error {
+	c, err := capture.Load(directory, id)
+	if err != nil {
+		return err
+	}
+
+	if bodyOnly {
+		_, err := io.WriteString(output, c.Body)
+		return err
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(c)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+)
+
+// runConfigCommand loads the configuration like the service, prints the effective values
+// with secrets redacted and the problems found. It returns the exit code:
+// 0 for a valid configuration, 1 for an invalid one and 2 when it can't be loaded.
+func runConfigCommand(args []string, output io.Writer) int {
+	flags := flag.NewFlagSet("config", flag.ContinueOnError)
+	flags.SetOutput(output)
+	configPath := flags.String("config", "configs/config.yaml", "path to config file")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	inspection, err := config.Inspect(*configPath)
+	if err != nil {
+		fmt.Fprintf(output, "Failed to load configuration: %v\n", err)
+		return 2
+	}
+
+	table := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, "KEY\tVALUE\tSOURCE")
+
+	for _, setting := range inspection.Settings {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", setting.Key, setting.Value, setting.Source)
+	}
+
+	_ = table.Flush()
+
+	printProblems(output, "Warnings", inspection.Warnings)
+	printProblems(output, "Errors", inspection.Errors)
+
+	if !inspection.Valid() {
+		fmt.Fprintf(output, "\nConfiguration is invalid: %d errors\n", len(inspection.Errors))
+		return 1
+	}
+
+	fmt.Fprintln(output, "\nConfiguration is valid")
+
+	return 0
+}
+
+func printProblems(output io.Writer, title string, problems []string) {
+	if len(problems) == 0 {
+		return
+	}
+
+	fmt.Fprintf(output, "\n%s:\n", title)
+
+	for _, problem := range problems {
+		fmt.Fprintf(output, "  - %s\n", problem)
+	}
+}
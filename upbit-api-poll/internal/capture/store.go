@@ -0,0 +1,397 @@
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+// Reasons of the captures
+const (
+	ReasonNewNews      = "new_news"
+	ReasonParseFailure = "parse_failure"
+	ReasonSample       = "sample"
+)
+
+const (
+	MetricCapturesTotal        = "upbit_captures_total"
+	MetricCapturesDroppedTotal = "upbit_captures_dropped_total"
+)
+
+// fileExtension is the extension of the capture files, the file name without it is the capture ID
+const fileExtension = ".json"
+
+type Metrics interface {
+	IncrementCounter(name string, labels ...string)
+}
+
+// Capture is a raw response with the reason it was captured.
+// The body is written last, so the metadata is read without it.
+type Capture struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+	Source string `json:"source"`
+	// Error is the parse failure of the response
+	Error      string    `json:"error,omitempty"`
+	CapturedAt time.Time `json:"captured_at"`
+
+	URL string `json:"url"`
+	// Proxy is the redacted httptools.ProxyID, the credentials are never captured
+	Proxy         string              `json:"proxy"`
+	Client        string              `json:"client"`
+	HeaderProfile string              `json:"header_profile,omitempty"`
+	StatusCode    int                 `json:"status_code"`
+	Headers       http.Header         `json:"headers"`
+	RequestedAt   time.Time           `json:"requested_at"`
+	ReceivedAt    time.Time           `json:"received_at"`
+	Freshness     httptools.Freshness `json:"freshness"`
+	Body          string              `json:"body"`
+
+	// Size is the size of the capture file
+	Size int64 `json:"-"`
+}
+
+// Duration is the time from sending the request to receiving the response
+func (c Capture) Duration() time.Duration {
+	return c.ReceivedAt.Sub(c.RequestedAt)
+}
+
+// Store writes the captures to a directory in the background, one file per capture.
+// The oldest samples are removed when the directory grows over the configured size,
+// the other captures only once no samples are left.
+// A disabled store drops every capture, it's safe for concurrent use.
+type Store struct {
+	config  config.Capture
+	logger  *slog.Logger
+	metrics Metrics
+
+	guard  sync.RWMutex
+	closed bool
+	writes chan Capture
+	done   chan struct{}
+
+	// files are the written captures, oldest first, only used by the writer
+	files []file
+	size  int64
+}
+
+type file struct {
+	name   string
+	reason string
+	size   int64
+}
+
+func NewStore(cfg config.Capture, logger *slog.Logger, metrics Metrics) (*Store, error) {
+	s := &Store{
+		config:  cfg,
+		logger:  logger,
+		metrics: metrics,
+		done:    make(chan struct{}),
+	}
+
+	if !cfg.Enabled {
+		s.closed = true
+		close(s.done)
+
+		return s, nil
+	}
+
+	if err := os.MkdirAll(cfg.Directory, 0o755); err != nil {
+		return nil, fmt.Errorf("create capture directory: %w", err)
+	}
+
+	files, err := captureFiles(cfg.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		s.files = append(s.files, f)
+		s.size += f.size
+	}
+
+	s.writes = make(chan Capture, cfg.QueueSize)
+
+	go s.run()
+
+	return s, nil
+}
+
+// Save queues the response to be written and returns the ID of the capture.
+// The ID is empty if the store is disabled or its queue is full.
+func (s *Store) Save(reason, source string, response httptools.Response, cause error) string {
+	s.guard.RLock()
+	defer s.guard.RUnlock()
+
+	if s.closed {
+		return ""
+	}
+
+	capture := newCapture(reason, source, response, cause, time.Now())
+
+	select {
+	case s.writes <- capture:
+		return capture.ID
+	default:
+		s.metrics.IncrementCounter(MetricCapturesDroppedTotal, "reason", reason)
+		return ""
+	}
+}
+
+// Sample saves the share of the responses given by the sample ratio
+func (s *Store) Sample(source string, response httptools.Response) {
+	if s.config.SampleRatio > 0 && rand.Float64() < s.config.SampleRatio {
+		s.Save(ReasonSample, source, response, nil)
+	}
+}
+
+// Close stops accepting captures and writes the queued ones until the context is done
+func (s *Store) Close(ctx context.Context) error {
+	s.guard.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.writes)
+	}
+	s.guard.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d captures were not written: %w", len(s.writes), ctx.Err())
+	}
+}
+
+func (s *Store) run() {
+	defer close(s.done)
+
+	for capture := range s.writes {
+		if err := s.write(capture); err != nil {
+			s.logger.Error("failed to write capture", "id", capture.ID, "error", err)
+			s.metrics.IncrementCounter(MetricCapturesDroppedTotal, "reason", capture.Reason)
+
+			continue
+		}
+
+		s.metrics.IncrementCounter(MetricCapturesTotal, "reason", capture.Reason)
+	}
+}
+
+func (s *Store) write(capture Capture) error {
+	data, err := json.MarshalIndent(capture, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	name := capture.ID + fileExtension
+	if err := os.WriteFile(filepath.Join(s.config.Directory, name), data, 0o644); err != nil {
+		return err
+	}
+
+	s.files = append(s.files, file{name: name, reason: capture.Reason, size: int64(len(data))})
+	s.size += int64(len(data))
+
+	s.rotate()
+
+	return nil
+}
+
+// rotate removes the oldest captures until the directory fits the configured size.
+// The samples are removed first, they are the least valuable and the most frequent.
+func (s *Store) rotate() {
+	maxSize := int64(s.config.MaxSizeMB) << 20
+
+	for s.size > maxSize && len(s.files) > 0 {
+		i := slices.IndexFunc(s.files, func(f file) bool {
+			return f.reason == ReasonSample
+		})
+		if i < 0 {
+			i = 0
+		}
+
+		oldest := s.files[i]
+
+		err := os.Remove(filepath.Join(s.config.Directory, oldest.name))
+		if err != nil && !os.IsNotExist(err) {
+			s.logger.Error("failed to remove capture", "file", oldest.name, "error", err)
+			return
+		}
+
+		s.files = slices.Delete(s.files, i, i+1)
+		s.size -= oldest.size
+	}
+}
+
+func newCapture(reason, source string, response httptools.Response, cause error, now time.Time) Capture {
+	capture := Capture{
+		// The ID starts with the time, so the captures sort from the oldest
+		ID: fmt.Sprintf(
+			"%s_%s_%s_%s",
+			now.UTC().Format("20060102T150405.000000000"),
+			reason,
+			source,
+			response.ID.String()[:8],
+		),
+		Reason:        reason,
+		Source:        source,
+		CapturedAt:    now,
+		URL:           response.URL,
+		Client:        response.ClientName,
+		HeaderProfile: response.HeaderProfile,
+		StatusCode:    response.StatusCode,
+		Headers:       response.Headers,
+		Body:          string(response.Body),
+		RequestedAt:   response.RequestedAt,
+		ReceivedAt:    response.ReceivedAt,
+		Freshness:     response.Freshness,
+	}
+
+	if response.ProxyAddr != "" {
+		capture.Proxy = httptools.ProxyID(response.ProxyAddr)
+	}
+
+	if cause != nil {
+		capture.Error = cause.Error()
+	}
+
+	return capture
+}
+
+// List returns the captures in the directory without their bodies, oldest first
+func List(directory string) ([]Capture, error) {
+	files, err := captureFiles(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	captures := make([]Capture, 0, len(files))
+	for _, f := range files {
+		capture, err := loadMetadata(directory, f)
+		if err != nil {
+			return nil, err
+		}
+
+		captures = append(captures, capture)
+	}
+
+	return captures, nil
+}
+
+// loadMetadata decodes the fields of the capture file up to the body, the body is neither read nor decoded
+func loadMetadata(directory string, f file) (Capture, error) {
+	id := strings.TrimSuffix(f.name, fileExtension)
+
+	reader, err := os.Open(filepath.Join(directory, f.name))
+	if err != nil {
+		return Capture{}, err
+	}
+	defer reader.Close()
+
+	decoder := json.NewDecoder(reader)
+	if _, err := decoder.Token(); err != nil {
+		return Capture{}, fmt.Errorf("decode capture %s: %w", id, err)
+	}
+
+	fields := make(map[string]json.RawMessage)
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return Capture{}, fmt.Errorf("decode capture %s: %w", id, err)
+		}
+
+		if key == "body" {
+			break
+		}
+
+		value := json.RawMessage{}
+		if err := decoder.Decode(&value); err != nil {
+			return Capture{}, fmt.Errorf("decode capture %s: %w", id, err)
+		}
+
+		fields[key.(string)] = value
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return Capture{}, err
+	}
+
+	capture := Capture{Size: f.size}
+	if err := json.Unmarshal(data, &capture); err != nil {
+		return Capture{}, fmt.Errorf("decode capture %s: %w", id, err)
+	}
+
+	return capture, nil
+}
+
+// Load reads the capture with the ID from the directory
+func Load(directory, id string) (Capture, error) {
+	if id == "" || filepath.Base(id) != id {
+		return Capture{}, fmt.Errorf("invalid capture ID %q", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(directory, id+fileExtension))
+	if err != nil {
+		return Capture{}, err
+	}
+
+	capture := Capture{Size: int64(len(data))}
+	if err := json.Unmarshal(data, &capture); err != nil {
+		return Capture{}, fmt.Errorf("decode capture %s: %w", id, err)
+	}
+
+	return capture, nil
+}
+
+// captureFiles returns the capture files of the directory, oldest first
+func captureFiles(directory string) ([]file, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("read capture directory: %w", err)
+	}
+
+	files := make([]file, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != fileExtension {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file{name: entry.Name(), reason: nameReason(entry.Name()), size: info.Size()})
+	}
+
+	slices.SortFunc(files, func(a, b file) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return files, nil
+}
+
+// nameReason returns the reason of the capture from its file name, the reason follows the time in the ID
+func nameReason(name string) string {
+	_, rest, _ := strings.Cut(name, "_")
+
+	for _, reason := range []string{ReasonNewNews, ReasonParseFailure, ReasonSample} {
+		if strings.HasPrefix(rest, reason+"_") {
+			return reason
+		}
+	}
+
+	return ""
+}
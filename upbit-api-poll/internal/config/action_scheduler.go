@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+const (
+	ScheduledActionOpenPosition = "open_position"
+	ScheduledActionReminder     = "reminder"
+)
+
+// ActionScheduler holds the configuration of actions run relative to the announced trading start time
+type ActionScheduler struct {
+	StateFile   string            `mapstructure:"state_file"   validate:"required" env:"ACTION_SCHEDULER_STATE_FILE"`
+	GracePeriod time.Duration     `mapstructure:"grace_period" validate:"gte=0"    env:"ACTION_SCHEDULER_GRACE_PERIOD"`
+	Actions     []ScheduledAction `mapstructure:"actions"      validate:"dive"     env:"-"`
+}
+
+// ScheduledAction is an action run at the trading start time shifted by the offset.
+// A negative offset runs the action before trading starts.
+type ScheduledAction struct {
+	Name   string        `mapstructure:"name"   validate:"required"`
+	Type   string        `mapstructure:"type"   validate:"required,oneof=open_position reminder"`
+	Offset time.Duration `mapstructure:"offset"`
+}
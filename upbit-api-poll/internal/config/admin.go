@@ -0,0 +1,9 @@
+package config
+
+// Admin holds the configuration of the admin HTTP API.
+// Requests must carry the token as "Authorization: Bearer <token>".
+type Admin struct {
+	Enabled bool   `mapstructure:"enabled"                                                      env:"ADMIN_ENABLED"`
+	Address string `mapstructure:"address" validate:"required_if=Enabled true"                  env:"ADMIN_ADDRESS"`
+	Token   string `mapstructure:"token"   validate:"required_if=Enabled true,omitempty,min=16" env:"ADMIN_TOKEN"`
+}
@@ -0,0 +1,13 @@
+package config
+
+// Capture holds the configuration of the store of raw responses kept for building parser fixtures
+type Capture struct {
+	Enabled   bool   `mapstructure:"enabled"                                       env:"CAPTURE_ENABLED"`
+	Directory string `mapstructure:"directory" validate:"required_if=Enabled true" env:"CAPTURE_DIRECTORY"`
+	// MaxSizeMB bounds the size of the captures, the oldest ones are removed beyond it
+	MaxSizeMB int `mapstructure:"max_size_mb" validate:"gt=0" env:"CAPTURE_MAX_SIZE_MB"`
+	// SampleRatio is the share of the successful responses captured besides new news and parse failures
+	SampleRatio float64 `mapstructure:"sample_ratio" validate:"gte=0,lte=1" env:"CAPTURE_SAMPLE_RATIO"`
+	// QueueSize is the number of captures waiting to be written, the captures beyond it are dropped
+	QueueSize int `mapstructure:"queue_size" validate:"gt=0" env:"CAPTURE_QUEUE_SIZE"`
+}
@@ -0,0 +1,15 @@
+package config
+
+// DefaultListingPatterns are the parts of notice titles announcing a new listing
+var DefaultListingPatterns = []string{
+	"Market Support for",
+	"신규 거래지원 안내",
+	"디지털 자산 추가",
+	"상장 안내",
+}
+
+// Classifier holds the patterns telling listing news from other news
+type Classifier struct {
+	// ListingPatterns match a title announcing a listing if it contains any of them
+	ListingPatterns []string `mapstructure:"listing_patterns" validate:"required,min=1,dive,required" env:"-"`
+}
@@ -7,73 +7,208 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
 	"github.com/go-playground/validator/v10"
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
+const (
+	envPrefix     = "UPBITAP"
+	redactedValue = "[REDACTED]"
+)
+
 type Config struct {
 	UpbitAPI            UpbitAPI            `mapstructure:"upbit_api"             validate:"required"`
 	WebsocketSucker     WebsocketSucker     `mapstructure:"websocket_sucker"      validate:"required"`
 	ProxyRotatingPoller ProxyRotatingPoller `mapstructure:"proxy_rotating_poller" validate:"required"`
-	Telegram            Telegram            `mapstructure:"telegram"              validate:"required"`
+	Telegram            Telegram            `mapstructure:"telegram"`
+	Notifier            Notifier            `mapstructure:"notifier"              validate:"required"`
 	Logger              Logger              `mapstructure:"logger"                validate:"required"`
 	GRPC                GRPC                `mapstructure:"grpc"                  validate:"required"`
+	SymbolRegistry      SymbolRegistry      `mapstructure:"symbol_registry"       validate:"required"`
+	Trading             Trading             `mapstructure:"trading"               validate:"required"`
+	Gate                Gate                `mapstructure:"gate"`
+	ActionScheduler     ActionScheduler     `mapstructure:"action_scheduler"      validate:"required"`
+	Admin               Admin               `mapstructure:"admin"`
+	Server              Server              `mapstructure:"server"                validate:"required"`
+	Tracing             Tracing             `mapstructure:"tracing"               validate:"required"`
+	Capture             Capture             `mapstructure:"capture"               validate:"required"`
+	Classifier          Classifier          `mapstructure:"classifier"            validate:"required"`
+	Secrets             Secrets             `mapstructure:"secrets"`
 }
 
 // Validate checks if the configuration is valid
 func (c Config) Validate() error {
 	validate := validator.New()
 
+	_ = validate.RegisterValidation("url_template", func(field validator.FieldLevel) bool {
+		return httptools.CheckURLTemplate(field.Field().String()) == nil
+	})
+
+	_ = validate.RegisterValidation("header_profile", func(field validator.FieldLevel) bool {
+		lines, ok := field.Field().Interface().([]string)
+		if !ok {
+			return false
+		}
+
+		_, err := httptools.ParseHeaders(lines)
+
+		return err == nil
+	})
+
+	// Commands are only accepted from the listed users, an empty list would let anyone in the chats issue them
+	validate.RegisterStructValidation(func(level validator.StructLevel) {
+		commands := level.Current().Interface().(TelegramCommands)
+		if commands.Enabled && len(commands.AuthorizedUserIDs) == 0 {
+			level.ReportError(commands.AuthorizedUserIDs, "AuthorizedUserIDs", "AuthorizedUserIDs", "required_if", "Enabled true")
+		}
+	}, TelegramCommands{})
+
 	return validate.Struct(&c)
 }
 
 func (c Config) String() string {
-	c.Telegram.AuthorizationToken = "[REDACTED]"
+	c = c.Redacted()
 	c.ProxyRotatingPoller.Proxies = []httptools.Proxy{}
 
-
 	b, _ := json.Marshal(c)
 
 	return strings.ReplaceAll(string(b), `"`, `'`)
 }
 
+// Redacted returns a copy of the configuration with the secrets replaced
+func (c Config) Redacted() Config {
+	c.Telegram.AuthorizationToken = redact(c.Telegram.AuthorizationToken)
+	c.WebsocketSucker.APIKey = redact(c.WebsocketSucker.APIKey)
+	c.Notifier.Discord.WebhookURL = redact(c.Notifier.Discord.WebhookURL)
+	c.Notifier.Slack.WebhookURL = redact(c.Notifier.Slack.WebhookURL)
+	c.Notifier.Webhook.URL = redact(c.Notifier.Webhook.URL)
+	c.Admin.Token = redact(c.Admin.Token)
+	c.Gate.APIKey = redact(c.Gate.APIKey)
+	c.Gate.APISecret = redact(c.Gate.APISecret)
+
+	headers := make(map[string]string, len(c.Notifier.Webhook.Headers))
+	for name := range c.Notifier.Webhook.Headers {
+		headers[name] = redactedValue
+	}
+	c.Notifier.Webhook.Headers = headers
+
+	proxies := make([]httptools.Proxy, 0, len(c.ProxyRotatingPoller.Proxies))
+	for _, proxy := range c.ProxyRotatingPoller.Proxies {
+		proxy.Username = redact(proxy.Username)
+		proxy.Password = redact(proxy.Password)
+		proxies = append(proxies, proxy)
+	}
+	c.ProxyRotatingPoller.Proxies = proxies
+
+	return c
+}
+
+// redact hides a set secret, an unset one is kept empty
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	return redactedValue
+}
+
 func MustParseConfig(configPath string) Config {
 	_ = godotenv.Load()
-	v := viper.New()
 
-	configDir := setConfigName(v, configPath)
+	cfg, err := load(configPath, false)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
 
-	v.SetConfigType("yaml")
-	v.AddConfigPath(configDir)
+	return cfg
+}
 
-	setDefaults(v)
+// load reads the configuration from the file, the defaults and the environment and validates it.
+// Unless the file is required, a missing or unreadable file is only logged.
+func load(configPath string, requireFile bool) (Config, error) {
+	v, err := read(configPath)
+	if err != nil {
+		if requireFile {
+			return Config{}, fmt.Errorf("read config file: %w", err)
+		}
 
-	if err := v.ReadInConfig(); err != nil {
 		log.Printf(
 			"Warning reading config file: %s, will use defaults and environment variables\n",
 			err,
 		)
 	}
 
-	v.SetEnvPrefix("UPBITAP")
+	cfg, err := decode(v)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// read prepares a viper with the defaults, the file and the environment. The error of reading
+// the file is returned with the viper, which still holds the defaults and the environment.
+func read(configPath string) (*viper.Viper, error) {
+	v := viper.New()
+
+	configDir := setConfigName(v, configPath)
+
+	v.SetConfigType("yaml")
+	v.AddConfigPath(configDir)
+
+	setDefaults(v)
+
+	err := v.ReadInConfig()
+
+	v.SetEnvPrefix(envPrefix)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
 	bindEnvVars(v)
 
-	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
-		log.Fatalf("Unable to decode config into struct: %v", err)
+	return v, err
+}
+
+func decode(v *viper.Viper) (Config, error) {
+	if err := resolveSecrets(v); err != nil {
+		return Config{}, fmt.Errorf("unable to resolve secrets: %w", err)
 	}
 
-	if err := cfg.Validate(); err != nil {
-		log.Fatalf("Invalid configuration: %v", err)
+	var cfg Config
+	if err := v.Unmarshal(&cfg, withDateDecoding); err != nil {
+		return Config{}, fmt.Errorf("unable to decode config into struct: %w", err)
 	}
 
-	return cfg
+	return cfg, nil
+}
+
+// withDateDecoding decodes the dates written in YAML without quotes, which are parsed
+// as timestamps, into strings like "2025-10-03"
+func withDateDecoding(c *mapstructure.DecoderConfig) {
+	c.DecodeHook = mapstructure.ComposeDecodeHookFunc(
+		func(from reflect.Type, to reflect.Type, data any) (any, error) {
+			moment, ok := data.(time.Time)
+			if !ok || to.Kind() != reflect.String {
+				return data, nil
+			}
+
+			if moment.Equal(moment.Truncate(24 * time.Hour)) {
+				return moment.Format(time.DateOnly), nil
+			}
+
+			return moment.Format(time.RFC3339), nil
+		},
+		c.DecodeHook,
+	)
 }
 
 func setConfigName(v *viper.Viper, configPath string) string {
@@ -94,6 +229,25 @@ func setConfigName(v *viper.Viper, configPath string) string {
 }
 
 func setDefaults(v *viper.Viper) {
+	v.SetDefault("upbit_api.locale", "ko")
+	v.SetDefault("upbit_api.cache_busting.announcements", httptools.CacheBustingNone)
+	v.SetDefault("upbit_api.cache_busting.announcement_by_id", httptools.CacheBustingNone)
+	v.SetDefault("upbit_api.cache_busting.notice_by_id", httptools.CacheBustingNone)
+	v.SetDefault("upbit_api.look_ahead", 2)
+	v.SetDefault("upbit_api.reconcile_interval", "5m")
+	v.SetDefault("proxy_rotating_poller.headers.mode", httptools.HeaderRotationPerClient)
+	v.SetDefault("telegram.enabled", true)
+	v.SetDefault("telegram.commands.enabled", false)
+	v.SetDefault("notifier.source", "upbit.api.poller")
+	v.SetDefault("notifier.timeout", "30s")
+	v.SetDefault("notifier.queue.capacity", 256)
+	v.SetDefault("notifier.queue.send_interval", "1s")
+	v.SetDefault("notifier.queue.max_retries", 5)
+	v.SetDefault("notifier.queue.retry_delay", "1s")
+	v.SetDefault("notifier.queue.coalesce_window", "1m")
+	v.SetDefault("notifier.queue.flush_timeout", "10s")
+	v.SetDefault("notifier.messages.language", "en")
+	v.SetDefault("notifier.messages.mode", "verbose")
 	v.SetDefault("logger.level", "info")
 	v.SetDefault("logger.format", "json")
 	v.SetDefault("logger.add_source", false)
@@ -102,6 +256,33 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("grpc.call_timeout", "10s")
 	v.SetDefault("app.metrics_check_period", "5m")
 	v.SetDefault("app.single_proxy_max_rps", 0.2)
+	v.SetDefault("symbol_registry.upbit_markets_endpoint", "https://api.upbit.com/v1/market/all")
+	v.SetDefault("symbol_registry.refresh_interval", "10m")
+	v.SetDefault("trading.enabled", true)
+	v.SetDefault("trading.usdt_amount", 10.0)
+	v.SetDefault("trading.leverage", "20")
+	v.SetDefault("action_scheduler.state_file", "data/scheduled_actions.json")
+	v.SetDefault("action_scheduler.grace_period", "1m")
+	v.SetDefault("admin.enabled", false)
+	v.SetDefault("admin.address", "127.0.0.1:8081")
+	v.SetDefault("server.addresses", []string{":8080"})
+	v.SetDefault("server.ready_max_age", "1m")
+	v.SetDefault("server.executor_check_interval", "30s")
+	v.SetDefault("server.pprof.enabled", false)
+	v.SetDefault("server.pprof.address", "127.0.0.1:6060")
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.exporter", TracingExporterOTLP)
+	v.SetDefault("tracing.endpoint", "localhost:4317")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.sample_ratio", 1.0)
+	v.SetDefault("tracing.service_name", "upbit-api-poll")
+	v.SetDefault("capture.enabled", false)
+	v.SetDefault("capture.directory", "data/captures")
+	v.SetDefault("capture.max_size_mb", 256)
+	v.SetDefault("capture.sample_ratio", 0.001)
+	v.SetDefault("capture.queue_size", 64)
+	v.SetDefault("classifier.listing_patterns", DefaultListingPatterns)
+	v.SetDefault("secrets.dir", "/run/secrets")
 }
 
 func bindEnvVars(v *viper.Viper) {
@@ -0,0 +1,8 @@
+package config
+
+// Gate holds the API credentials of the Gate.io account the positions are opened on.
+// They are secrets, set them with the env vars or the secret files rather than the config file.
+type Gate struct {
+	APIKey    string `mapstructure:"api_key"    env:"GATE_API_KEY"`
+	APISecret string `mapstructure:"api_secret" env:"GATE_API_SECRET"`
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/go-playground/validator/v10"
+	"github.com/joho/godotenv"
+	"github.com/spf13/viper"
+)
+
+// Source tells where the effective value of a setting comes from
+type Source string
+
+const (
+	SourceSecret  Source = "secret"
+	SourceEnv     Source = "env"
+	SourceFile    Source = "file"
+	SourceDefault Source = "default"
+	SourceUnset   Source = "unset"
+)
+
+// Setting is an effective value of the configuration, secrets are redacted
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+// Inspection is the effective configuration with the problems found in it.
+// The service doesn't start with errors, warnings are worth a look.
+type Inspection struct {
+	Config   Config
+	Settings []Setting
+	Errors   []string
+	Warnings []string
+}
+
+// Valid reports whether the service can start with the configuration
+func (i Inspection) Valid() bool {
+	return len(i.Errors) == 0
+}
+
+// Inspect loads the configuration exactly like the service does and collects all problems
+// instead of failing on the first one. Only a configuration which can't be decoded is an error.
+func Inspect(configPath string) (Inspection, error) {
+	_ = godotenv.Load()
+
+	inspection := Inspection{}
+
+	v, err := read(configPath)
+	if err != nil {
+		inspection.Warnings = append(
+			inspection.Warnings,
+			fmt.Sprintf("config file is not read, defaults and environment variables are used: %s", err),
+		)
+	}
+
+	cfg, err := decode(v)
+	if err != nil {
+		return Inspection{}, err
+	}
+
+	inspection.Config = cfg
+	inspection.Settings = settings(v, reflect.ValueOf(cfg.Redacted()), reflect.TypeOf(cfg), "")
+
+	var validationErrors validator.ValidationErrors
+	if err := cfg.Validate(); errors.As(err, &validationErrors) {
+		for _, fieldError := range validationErrors {
+			inspection.Errors = append(
+				inspection.Errors,
+				fmt.Sprintf("%s fails the %q rule", fieldError.Namespace(), fieldError.Tag()),
+			)
+		}
+	} else if err != nil {
+		inspection.Errors = append(inspection.Errors, err.Error())
+	}
+
+	inspection.checkProxiesCapacity(cfg)
+	inspection.checkWorkSchedule(cfg.ProxyRotatingPoller.WorkSchedule)
+
+	return inspection, nil
+}
+
+func settings(v *viper.Viper, value reflect.Value, reflectedType reflect.Type, prefix string) []Setting {
+	var result []Setting
+
+	for i := 0; i < reflectedType.NumField(); i++ {
+		field := reflectedType.Field(i)
+
+		key := field.Tag.Get("mapstructure")
+		if prefix != "" {
+			key = strings.Join([]string{prefix, key}, ".")
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			result = append(result, settings(v, value.Field(i), field.Type, key)...)
+			continue
+		}
+
+		result = append(result, Setting{
+			Key:    key,
+			Value:  fmt.Sprint(value.Field(i).Interface()),
+			Source: source(v, key, field.Tag.Get("env")),
+		})
+	}
+
+	return result
+}
+
+// source mirrors the lookup order of the loader: the secret files, the environment, the file and the defaults
+func source(v *viper.Viper, key, envTag string) Source {
+	if path, _ := secretFile(v.GetString("secrets.dir"), key, envTag); path != "" {
+		return SourceSecret
+	}
+
+	for _, envVar := range envVarNames(key, envTag) {
+		if os.Getenv(envVar) != "" {
+			return SourceEnv
+		}
+	}
+
+	switch {
+	case v.InConfig(key):
+		return SourceFile
+	case v.IsSet(key):
+		return SourceDefault
+	default:
+		return SourceUnset
+	}
+}
+
+// checkProxiesCapacity checks that the proxies can sustain the target RPS on every endpoint
+func (i *Inspection) checkProxiesCapacity(cfg Config) {
+	poller := cfg.ProxyRotatingPoller
+
+	addresses := make(map[string]int, len(poller.Proxies))
+	for _, proxy := range poller.Proxies {
+		addresses[proxy.Redacted()]++
+	}
+
+	for address, count := range addresses {
+		if count > 1 {
+			i.Warnings = append(i.Warnings, fmt.Sprintf("proxy %s is listed %d times", address, count))
+		}
+	}
+
+	endpoints := []struct {
+		key               string
+		singleProxyMaxRPS float64
+	}{
+		{"upbit_api.announcements_single_ip_max_rps", cfg.UpbitAPI.AnnouncementsSingleIPMaxRPS},
+		{"upbit_api.announcement_by_id_single_ip_max_rps", cfg.UpbitAPI.AnnouncementByIDSingleIPMaxRPS},
+		{"upbit_api.notice_by_id_single_ip_max_rps", cfg.UpbitAPI.NoticeByIDSingleIPMaxRPS},
+	}
+
+	profiles := workScheduleProfiles(poller.WorkSchedule)
+
+	for _, endpoint := range endpoints {
+		if endpoint.singleProxyMaxRPS <= 0 {
+			continue
+		}
+
+		maxRPS := float64(len(addresses)) * endpoint.singleProxyMaxRPS
+		for _, profile := range profiles {
+			if profile.TargetRPS > maxRPS {
+				i.Warnings = append(i.Warnings, fmt.Sprintf(
+					"RPS profile %s target_rps %.2f exceeds %.2f RPS of the proxies with %s, it is limited to it",
+					profile.ProfileName(),
+					profile.TargetRPS,
+					maxRPS,
+					endpoint.key,
+				))
+			}
+		}
+
+		if poller.TargetRPS <= 0 {
+			continue
+		}
+
+		required := int(math.Ceil(poller.TargetRPS / endpoint.singleProxyMaxRPS))
+		if len(addresses) < required {
+			i.Errors = append(i.Errors, fmt.Sprintf(
+				"target_rps %.2f with %s %.2f requires %d proxies, %d configured",
+				poller.TargetRPS,
+				endpoint.key,
+				endpoint.singleProxyMaxRPS,
+				required,
+				len(addresses),
+			))
+		}
+	}
+}
+
+// workScheduleProfiles returns the windows of the work schedule with a target RPS
+func workScheduleProfiles(workSchedule entity.WorkSchedule) []entity.TimeWindow {
+	var windows []entity.TimeWindow
+	for _, schedule := range workSchedule.Schedule {
+		windows = append(windows, schedule.AllWindows()...)
+	}
+
+	for _, dated := range workSchedule.Dates {
+		windows = append(windows, dated.Windows...)
+	}
+
+	return slices.DeleteFunc(windows, func(window entity.TimeWindow) bool {
+		return window.TargetRPS <= 0
+	})
+}
+
+// checkWorkSchedule finds the dates scheduled twice and a schedule in which the pollers never work
+func (i *Inspection) checkWorkSchedule(workSchedule entity.WorkSchedule) {
+	dates := make(map[string]bool, len(workSchedule.Dates))
+	for _, dated := range workSchedule.Dates {
+		if dates[dated.Date] {
+			i.Warnings = append(i.Warnings, fmt.Sprintf(
+				"work schedule of %s is set more than once, the first one is used",
+				dated.Date,
+			))
+		}
+
+		dates[dated.Date] = true
+	}
+
+	if err := validator.New().Struct(workSchedule); err != nil {
+		// Reported by the validation
+		return
+	}
+
+	if _, err := workSchedule.NextWorkSessionAt(time.Now()); errors.Is(err, entity.ErrNoWorkSession) {
+		i.Warnings = append(i.Warnings, "work schedule has no sessions within a year, the pollers never work")
+	}
+}
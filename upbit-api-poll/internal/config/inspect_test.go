@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+func TestInspect(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	content := `
+websocket_sucker:
+  api_key: "file-secret"
+notifier:
+  webhook:
+    url: "https://hooks.example.com/services/hook-token"
+telegram:
+  commands:
+    enabled: true
+    authorized_user_ids: []
+upbit_api:
+  notice_by_id_single_ip_max_rps: 0.5
+proxy_rotating_poller:
+  target_rps: 2
+  proxies:
+    - {username: "user", password: "password", host: "proxy.example.com", port: 8080}
+    - {username: "user", password: "password", host: "proxy.example.com", port: 8080}
+  work_schedule:
+    time_zone: "Asia/Seoul"
+    schedule:
+      monday: {start_time: "23:00", end_time: "08:00", profile: "overnight", target_rps: 1}
+      wednesday:
+        windows:
+          - {start_time: "09:00", end_time: "18:00", profile: "business", target_rps: 3}
+      tuesday: {start_time: "8:00pm", end_time: "23:00"}
+    dates:
+      - {date: 2025-10-03, name: "National Foundation Day"}
+      - {date: "2025-10-03"}
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("UPBITAP_TRADING_LEVERAGE", "5")
+
+	inspection, err := Inspect(configPath)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+
+	sources := map[string]Setting{}
+	for _, setting := range inspection.Settings {
+		sources[setting.Key] = setting
+	}
+
+	expected := map[string]Setting{
+		"websocket_sucker.api_key": {"websocket_sucker.api_key", redactedValue, SourceFile},
+		"notifier.webhook.url":     {"notifier.webhook.url", redactedValue, SourceFile},
+		"trading.leverage":         {"trading.leverage", "5", SourceEnv},
+		"logger.level":             {"logger.level", "info", SourceDefault},
+		"admin.token":              {"admin.token", "", SourceUnset},
+	}
+	for key, setting := range expected {
+		if sources[key] != setting {
+			t.Errorf("setting %s = %+v, expected %+v", key, sources[key], setting)
+		}
+	}
+
+	if proxies := sources["proxy_rotating_poller.proxies"].Value; strings.Contains(proxies, "password") {
+		t.Errorf("proxy credentials are printed: %s", proxies)
+	}
+
+	contains := func(problems []string, part string) bool {
+		return slices.ContainsFunc(problems, func(problem string) bool { return strings.Contains(problem, part) })
+	}
+
+	if inspection.Valid() {
+		t.Error("Inspect() should find errors")
+	}
+
+	for _, part := range []string{
+		"notice_by_id_single_ip_max_rps 0.50 requires 4 proxies, 1 configured",
+		"Config.ProxyRotatingPoller.WorkSchedule.Schedule[tuesday].StartTime",
+		"Config.Telegram.Commands.AuthorizedUserIDs",
+	} {
+		if !contains(inspection.Errors, part) {
+			t.Errorf("errors %q don't contain %q", inspection.Errors, part)
+		}
+	}
+
+	for _, part := range []string{
+		"proxy.example.com:8080 is listed 2 times",
+		"work schedule of 2025-10-03 is set more than once",
+		"RPS profile business target_rps 3.00 exceeds 0.50 RPS of the proxies with upbit_api.notice_by_id_single_ip_max_rps",
+	} {
+		if !contains(inspection.Warnings, part) {
+			t.Errorf("warnings %q don't contain %q", inspection.Warnings, part)
+		}
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := Config{
+		Telegram:        Telegram{AuthorizationToken: "token"},
+		WebsocketSucker: WebsocketSucker{URL: "wss://example.com", APIKey: "key"},
+	}
+	cfg.ProxyRotatingPoller.Proxies = []httptools.Proxy{{Username: "user", Password: "password", Host: "proxy"}}
+	cfg.Notifier.Webhook.URL = "https://hooks.example.com/services/hook-credential"
+	cfg.Notifier.Webhook.Headers = map[string]string{"Authorization": "Bearer secret"}
+
+	for _, secret := range []string{"token", "key", "user", "password", "secret", "hook-credential"} {
+		if strings.Contains(cfg.String(), secret) {
+			t.Errorf("String() contains the secret %q", secret)
+		}
+	}
+
+	redacted := cfg.Redacted()
+	if redacted.WebsocketSucker.URL != cfg.WebsocketSucker.URL || redacted.Admin.Token != "" {
+		t.Errorf("Redacted() should keep the other values: %+v", redacted)
+	}
+
+	if cfg.ProxyRotatingPoller.Proxies[0].Password != "password" {
+		t.Error("Redacted() changed the original proxies")
+	}
+}
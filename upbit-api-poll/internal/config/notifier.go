@@ -0,0 +1,37 @@
+package config
+
+import (
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/messages"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/notifier"
+)
+
+// Notifier holds the configuration of the notification backends besides Telegram.
+// Every message is sent to all enabled backends.
+type Notifier struct {
+	Source   string               `mapstructure:"source"   validate:"required" env:"NOTIFIER_SOURCE"`
+	Timeout  time.Duration        `mapstructure:"timeout"  validate:"required" env:"NOTIFIER_TIMEOUT"`
+	Queue    notifier.QueueConfig `mapstructure:"queue"    validate:"required"`
+	Messages messages.Config      `mapstructure:"messages" validate:"required"`
+	Discord  DiscordNotifier      `mapstructure:"discord"`
+	Slack    SlackNotifier        `mapstructure:"slack"`
+	Webhook  WebhookNotifier      `mapstructure:"webhook"`
+}
+
+type DiscordNotifier struct {
+	Enabled    bool   `mapstructure:"enabled"                                                          env:"NOTIFIER_DISCORD_ENABLED"`
+	WebhookURL string `mapstructure:"webhook_url" validate:"required_if=Enabled true,omitempty,url" env:"NOTIFIER_DISCORD_WEBHOOK_URL"`
+}
+
+type SlackNotifier struct {
+	Enabled    bool   `mapstructure:"enabled"                                                          env:"NOTIFIER_SLACK_ENABLED"`
+	WebhookURL string `mapstructure:"webhook_url" validate:"required_if=Enabled true,omitempty,url" env:"NOTIFIER_SLACK_WEBHOOK_URL"`
+}
+
+// WebhookNotifier posts messages as {"text", "html", "sent_at"} JSON to an arbitrary endpoint
+type WebhookNotifier struct {
+	Enabled bool              `mapstructure:"enabled"                                                  env:"NOTIFIER_WEBHOOK_ENABLED"`
+	URL     string            `mapstructure:"url"     validate:"required_if=Enabled true,omitempty,url" env:"NOTIFIER_WEBHOOK_URL"`
+	Headers map[string]string `mapstructure:"headers"                                                  env:"-"`
+}
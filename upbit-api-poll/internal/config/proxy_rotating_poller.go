@@ -10,4 +10,6 @@ type ProxyRotatingPoller struct {
 	Proxies      []httptools.Proxy             `mapstructure:"proxies"       validate:"required,dive" env:"PROXY_ROTATING_POLLER_PROXIES"`
 	WorkSchedule entity.WorkSchedule           `mapstructure:"work_schedule" validate:"required"      env:"PROXY_ROTATING_POLLER_WORK_SCHEDULE"`
 	Retries      httptools.ClientRetriesConfig `mapstructure:"retries"       validate:"required"      env:"PROXY_ROTATING_POLLER_RETRIES"`
+	// Headers are the browser header profiles of the proxy clients
+	Headers httptools.HeaderRotation `mapstructure:"headers" env:"PROXY_ROTATING_POLLER_HEADERS"`
 }
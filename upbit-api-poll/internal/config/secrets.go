@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// secretFileSuffix marks the env var holding the path of the file with the value,
+// like UPBITAP_TELEGRAM_AUTHORIZATION_TOKEN_FILE=/run/secrets/telegram_token
+const secretFileSuffix = "_FILE"
+
+// Secrets holds the directory with the files of the credentials, like the one Docker and Kubernetes
+// mount the secrets to. A file named like the key, e.g. telegram.authorization_token, sets the value.
+type Secrets struct {
+	Dir string `mapstructure:"dir" env:"SECRETS_DIR"`
+}
+
+// resolveSecrets sets the values read from the secret files. The file named by the <ENV VAR>_FILE
+// variable takes the place of the env var itself. The file in the secrets directory is used
+// unless the env var is set, it takes precedence over the config file.
+func resolveSecrets(v *viper.Viper) error {
+	envMappings, err := createEnvMappings(reflect.TypeOf(Config{}))
+	if err != nil {
+		return err
+	}
+
+	secretsDir := v.GetString("secrets.dir")
+
+	var errs []error
+
+	for configKey, envVar := range envMappings {
+		path, err := secretFile(secretsDir, configKey, envVar)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if path == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("read secret %s: %w", configKey, err))
+			continue
+		}
+
+		v.Set(configKey, strings.TrimRight(string(content), "\r\n"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// secretFile returns the path of the file with the value of the key, or an empty path
+// if the value doesn't come from a file.
+func secretFile(secretsDir, configKey, envTag string) (string, error) {
+	envVars := envVarNames(configKey, envTag)
+
+	envSet := ""
+	for _, envVar := range envVars {
+		if os.Getenv(envVar) != "" {
+			envSet = envVar
+			break
+		}
+	}
+
+	for _, envVar := range envVars {
+		path := os.Getenv(envVar + secretFileSuffix)
+		if path == "" {
+			continue
+		}
+
+		if envSet != "" {
+			return "", fmt.Errorf("both %s and %s are set", envSet, envVar+secretFileSuffix)
+		}
+
+		return path, nil
+	}
+
+	if secretsDir == "" || envSet != "" {
+		return "", nil
+	}
+
+	path := filepath.Join(secretsDir, configKey)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", nil
+	}
+
+	return path, nil
+}
+
+// envVarNames returns the env vars which set the key: the prefixed name of the key
+// and the name from the env tag
+func envVarNames(configKey, envTag string) []string {
+	envVars := []string{envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(configKey, ".", "_"))}
+	if envTag != "" && envTag != "-" {
+		envVars = append(envVars, envTag)
+	}
+
+	return envVars
+}
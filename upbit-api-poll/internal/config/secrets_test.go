@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+
+	configPath := filepath.Join(dir, "config.yaml")
+	content := `
+telegram:
+  authorization_token: "from-config-file"
+websocket_sucker:
+  api_key: "from-config-file"
+admin:
+  token: "from-config-file"
+`
+	write := func(path, content string) {
+		t.Helper()
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	secretsDir := filepath.Join(dir, "secrets")
+	if err := os.Mkdir(secretsDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	write(configPath, content)
+	write(filepath.Join(secretsDir, "telegram.authorization_token"), "from-secrets-dir\n")
+	write(filepath.Join(secretsDir, "admin.token"), "from-secrets-dir\n")
+	write(filepath.Join(secretsDir, "gate.api_secret"), "from-secrets-dir\n")
+	write(filepath.Join(dir, "api_key"), "from-file-env\n")
+
+	t.Setenv("UPBITAP_SECRETS_DIR", secretsDir)
+	t.Setenv("UPBITAP_WEBSOCKET_SUCKER_API_KEY_FILE", filepath.Join(dir, "api_key"))
+	t.Setenv("UPBITAP_ADMIN_TOKEN", "from-env")
+
+	v, err := read(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := decode(v)
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+
+	if cfg.Telegram.AuthorizationToken != "from-secrets-dir" {
+		t.Errorf("telegram token = %q, expected the one from the secrets directory", cfg.Telegram.AuthorizationToken)
+	}
+
+	if cfg.WebsocketSucker.APIKey != "from-file-env" {
+		t.Errorf("API key = %q, expected the one from the _FILE env var", cfg.WebsocketSucker.APIKey)
+	}
+
+	if cfg.Admin.Token != "from-env" {
+		t.Errorf("admin token = %q, expected the env var to take precedence over the secrets directory", cfg.Admin.Token)
+	}
+
+	if cfg.Gate.APISecret != "from-secrets-dir" {
+		t.Errorf("gate API secret = %q, expected the one from the secrets directory without a config file entry", cfg.Gate.APISecret)
+	}
+
+	if got := source(v, "telegram.authorization_token", "TELEGRAM_AUTHORIZATION_TOKEN"); got != SourceSecret {
+		t.Errorf("source() = %s, expected %s", got, SourceSecret)
+	}
+
+	t.Setenv("UPBITAP_WEBSOCKET_SUCKER_API_KEY", "from-env")
+
+	if _, err := decode(v); err == nil {
+		t.Error("decode() should fail when both the env var and the _FILE env var are set")
+	}
+}
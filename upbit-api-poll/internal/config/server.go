@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+// Server holds the configuration of the listeners serving /metrics, /healthz and /readyz
+type Server struct {
+	Addresses []string `mapstructure:"addresses" validate:"required,min=1,dive,hostname_port" env:"SERVER_ADDRESSES"`
+	// ReadyMaxAge is the age of the last successful response of a working source after which the service is not ready
+	ReadyMaxAge time.Duration `mapstructure:"ready_max_age" validate:"gt=0" env:"SERVER_READY_MAX_AGE"`
+	// ExecutorCheckInterval is how often the order executor is checked to be reachable
+	ExecutorCheckInterval time.Duration `mapstructure:"executor_check_interval" validate:"gt=0" env:"SERVER_EXECUTOR_CHECK_INTERVAL"`
+	Pprof                 Pprof         `mapstructure:"pprof"`
+}
+
+// Pprof holds the configuration of the listener serving the profiles under /debug/pprof/.
+// It is separate from the public listeners and must not be exposed.
+type Pprof struct {
+	Enabled bool   `mapstructure:"enabled"                                                              env:"SERVER_PPROF_ENABLED"`
+	Address string `mapstructure:"address" validate:"required_if=Enabled true,omitempty,hostname_port" env:"SERVER_PPROF_ADDRESS"`
+}
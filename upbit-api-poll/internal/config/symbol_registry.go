@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+// SymbolRegistry holds the configuration of the known-symbol registry used to validate tickers
+type SymbolRegistry struct {
+	UpbitMarketsEndpoint string        `mapstructure:"upbit_markets_endpoint" validate:"required,url" env:"SYMBOL_REGISTRY_UPBIT_MARKETS_ENDPOINT"`
+	RefreshInterval      time.Duration `mapstructure:"refresh_interval"       validate:"required"     env:"SYMBOL_REGISTRY_REFRESH_INTERVAL"`
+	AssetAliases         []AssetAlias  `mapstructure:"asset_aliases"          validate:"dive"         env:"-"`
+}
+
+// AssetAlias maps an asset name which is not (yet) in Upbit's market list to its ticker
+type AssetAlias struct {
+	Name   string `mapstructure:"name"   validate:"required"`
+	Ticker string `mapstructure:"ticker" validate:"required"`
+}
+
+func (s SymbolRegistry) AssetAliasesMap() map[string]string {
+	aliases := make(map[string]string, len(s.AssetAliases))
+	for _, alias := range s.AssetAliases {
+		aliases[alias.Name] = alias.Ticker
+	}
+
+	return aliases
+}
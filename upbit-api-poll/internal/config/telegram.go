@@ -1,8 +1,31 @@
 package config
 
+import "slices"
+
 // Telegram holds the configuration for Telegram integration
 type Telegram struct {
-	BotID              string `mapstructure:"bot_id"              validate:"required" env:"TELEGRAM_BOT_ID"`
-	AuthorizationToken string `mapstructure:"authorization_token" validate:"required" env:"TELEGRAM_AUTHORIZATION_TOKEN"`
-	GroupID            int64  `mapstructure:"group_id"            validate:"required" env:"TELEGRAM_GROUP_ID"`
+	Enabled            bool             `mapstructure:"enabled"                                                env:"TELEGRAM_ENABLED"`
+	BotID              string           `mapstructure:"bot_id"              validate:"required_if=Enabled true" env:"TELEGRAM_BOT_ID"`
+	AuthorizationToken string           `mapstructure:"authorization_token" validate:"required_if=Enabled true" env:"TELEGRAM_AUTHORIZATION_TOKEN"`
+	GroupID            int64            `mapstructure:"group_id"            validate:"required_if=Enabled true" env:"TELEGRAM_GROUP_ID"`
+	Commands           TelegramCommands `mapstructure:"commands"`
+}
+
+// TelegramCommands holds the configuration of the operator commands accepted by the bot.
+// Commands are accepted in the group and the authorized chats, only from the authorized users.
+// The users are required when the commands are enabled.
+type TelegramCommands struct {
+	Enabled           bool    `mapstructure:"enabled"             env:"TELEGRAM_COMMANDS_ENABLED"`
+	AuthorizedChatIDs []int64 `mapstructure:"authorized_chat_ids" env:"-"`
+	AuthorizedUserIDs []int64 `mapstructure:"authorized_user_ids" env:"-"`
+}
+
+// ChatAuthorized reports whether commands are accepted in the chat
+func (t Telegram) ChatAuthorized(chatID int64) bool {
+	return chatID == t.GroupID || slices.Contains(t.Commands.AuthorizedChatIDs, chatID)
+}
+
+// UserAuthorized reports whether the user can issue commands, nobody can without authorized users
+func (t Telegram) UserAuthorized(userID int64) bool {
+	return slices.Contains(t.Commands.AuthorizedUserIDs, userID)
 }
@@ -0,0 +1,22 @@
+package config
+
+// Tracing exporters
+const (
+	// TracingExporterOTLP sends the spans to an OTLP collector over gRPC
+	TracingExporterOTLP = "otlp"
+	// TracingExporterStdout writes the spans to the standard output
+	TracingExporterStdout = "stdout"
+)
+
+// Tracing holds the configuration of the OpenTelemetry traces of the detection
+type Tracing struct {
+	Enabled  bool   `mapstructure:"enabled"                                                               env:"TRACING_ENABLED"`
+	Exporter string `mapstructure:"exporter" validate:"required_if=Enabled true,omitempty,oneof=otlp stdout" env:"TRACING_EXPORTER"`
+	// Endpoint is the host:port of the OTLP collector
+	Endpoint string `mapstructure:"endpoint" validate:"required_if=Exporter otlp" env:"TRACING_ENDPOINT"`
+	// Insecure connects to the collector without TLS
+	Insecure bool `mapstructure:"insecure" env:"TRACING_INSECURE"`
+	// SampleRatio is the share of the traced poll ticks, a news event is traced with the poll tick which fetched it
+	SampleRatio float64 `mapstructure:"sample_ratio" validate:"gte=0,lte=1" env:"TRACING_SAMPLE_RATIO"`
+	ServiceName string  `mapstructure:"service_name" validate:"required"    env:"TRACING_SERVICE_NAME"`
+}
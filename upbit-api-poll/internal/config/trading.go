@@ -0,0 +1,9 @@
+package config
+
+// Trading holds the sizing of the orders opened on listing news
+type Trading struct {
+	// Enabled is the initial state, trading can be switched at runtime by operators
+	Enabled    bool    `mapstructure:"enabled"                                env:"TRADING_ENABLED"`
+	USDTAmount float64 `mapstructure:"usdt_amount" validate:"required,gt=0" env:"TRADING_USDT_AMOUNT"`
+	Leverage   string  `mapstructure:"leverage"    validate:"required"      env:"TRADING_LEVERAGE"`
+}
@@ -1,10 +1,52 @@
 package config
 
+import (
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+// The endpoints are URL templates with the {id}, {page}, {per_page}, {locale} and {buster} variables.
+// A %d written for fmt.Sprintf is still accepted: the ID of the by-ID endpoints and per_page of the announcements.
 type UpbitAPI struct {
-	AnnouncementsEndpoint          string  `mapstructure:"announcements_endpoint"               validate:"required" env:"UPBIT_API_ANNOUNCEMENTS_ENDPOINT"`
-	AnnouncementsSingleIPMaxRPS    float64 `mapstructure:"announcements_single_ip_max_rps"      validate:"required" env:"UPBIT_API_ANNOUNCEMENTS_SINGLE_IP_MAX_RPS"`
-	AnnouncementByIDEndpoint       string  `mapstructure:"announcement_by_id_endpoint"          validate:"required" env:"UPBIT_API_ANNOUNCEMENT_BY_ID_ENDPOINT"`
-	AnnouncementByIDSingleIPMaxRPS float64 `mapstructure:"announcement_by_id_single_ip_max_rps" validate:"required" env:"UPBIT_API_ANNOUNCEMENT_BY_ID_SINGLE_IP_MAX_RPS"`
-	NoticeByIDEndpoint             string  `mapstructure:"notice_by_id_endpoint"                validate:"required" env:"UPBIT_API_NOTICE_BY_ID_ENDPOINT"`
-	NoticeByIDSingleIPMaxRPS       float64 `mapstructure:"notice_by_id_single_ip_max_rps"       validate:"required" env:"UPBIT_API_NOTICE_BY_ID_SINGLE_IP_MAX_RPS"`
+	AnnouncementsEndpoint          string  `mapstructure:"announcements_endpoint"               validate:"required,url_template" env:"UPBIT_API_ANNOUNCEMENTS_ENDPOINT"`
+	AnnouncementsSingleIPMaxRPS    float64 `mapstructure:"announcements_single_ip_max_rps"      validate:"required"              env:"UPBIT_API_ANNOUNCEMENTS_SINGLE_IP_MAX_RPS"`
+	AnnouncementByIDEndpoint       string  `mapstructure:"announcement_by_id_endpoint"          validate:"required,url_template" env:"UPBIT_API_ANNOUNCEMENT_BY_ID_ENDPOINT"`
+	AnnouncementByIDSingleIPMaxRPS float64 `mapstructure:"announcement_by_id_single_ip_max_rps" validate:"required"              env:"UPBIT_API_ANNOUNCEMENT_BY_ID_SINGLE_IP_MAX_RPS"`
+	NoticeByIDEndpoint             string  `mapstructure:"notice_by_id_endpoint"                validate:"required,url_template" env:"UPBIT_API_NOTICE_BY_ID_ENDPOINT"`
+	NoticeByIDSingleIPMaxRPS       float64 `mapstructure:"notice_by_id_single_ip_max_rps"       validate:"required"              env:"UPBIT_API_NOTICE_BY_ID_SINGLE_IP_MAX_RPS"`
+	// Locale fills the {locale} variable of the endpoints
+	Locale string `mapstructure:"locale" validate:"required" env:"UPBIT_API_LOCALE"`
+	// CacheBusting selects the cache-busting strategy of every endpoint
+	CacheBusting CacheBusting `mapstructure:"cache_busting" validate:"required"`
+	// LookAhead is the number of IDs after the cursor probed by the by-ID fetchers
+	LookAhead int `mapstructure:"look_ahead" validate:"gte=0,lte=50" env:"UPBIT_API_LOOK_AHEAD"`
+	// ReconcileInterval is how often the by-ID fetchers are checked against the announcements list, 0 disables it
+	ReconcileInterval time.Duration `mapstructure:"reconcile_interval" validate:"gte=0" env:"UPBIT_API_RECONCILE_INTERVAL"`
+}
+
+// CacheBusting are the cache-busting strategies of the endpoints: none, random or timestamp
+type CacheBusting struct {
+	Announcements    string `mapstructure:"announcements"      validate:"oneof=none random timestamp" env:"UPBIT_API_CACHE_BUSTING_ANNOUNCEMENTS"`
+	AnnouncementByID string `mapstructure:"announcement_by_id" validate:"oneof=none random timestamp" env:"UPBIT_API_CACHE_BUSTING_ANNOUNCEMENT_BY_ID"`
+	NoticeByID       string `mapstructure:"notice_by_id"       validate:"oneof=none random timestamp" env:"UPBIT_API_CACHE_BUSTING_NOTICE_BY_ID"`
+}
+
+// AnnouncementsURL returns the template of the announcements endpoint with the first page
+func (u UpbitAPI) AnnouncementsURL() httptools.URLTemplate {
+	return httptools.NewURLTemplate(u.AnnouncementsEndpoint, httptools.VarPerPage).
+		With(httptools.VarPage, 1).
+		With(httptools.VarLocale, u.Locale)
+}
+
+// AnnouncementByIDURL returns the template of the announcement by ID endpoint
+func (u UpbitAPI) AnnouncementByIDURL() httptools.URLTemplate {
+	return httptools.NewURLTemplate(u.AnnouncementByIDEndpoint, httptools.VarID).
+		With(httptools.VarLocale, u.Locale)
+}
+
+// NoticeByIDURL returns the template of the notice by ID endpoint
+func (u UpbitAPI) NoticeByIDURL() httptools.URLTemplate {
+	return httptools.NewURLTemplate(u.NoticeByIDEndpoint, httptools.VarID).
+		With(httptools.VarLocale, u.Locale)
 }
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Watch reloads the configuration whenever the file changes. A valid configuration which differs
+// from the current one is passed to onChange with the previous one, a reload which fails
+// to read or validate is passed to onError and the current configuration is kept.
+// onChange returns the configuration it applied, the next reload is compared with it,
+// so the changes which failed to apply are retried. Changes after the context is done are ignored.
+func Watch(
+	ctx context.Context,
+	configPath string,
+	current Config,
+	onChange func(previous, next Config) Config,
+	onError func(error),
+) error {
+	v := viper.New()
+
+	configDir := setConfigName(v, configPath)
+
+	v.SetConfigType("yaml")
+	v.AddConfigPath(configDir)
+
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	guard := sync.Mutex{}
+
+	v.OnConfigChange(func(fsnotify.Event) {
+		guard.Lock()
+		defer guard.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		next, err := load(configPath, true)
+		if err != nil {
+			onError(err)
+			return
+		}
+
+		if len(Diff(current, next)) == 0 {
+			return
+		}
+
+		current = onChange(current, next)
+	})
+	v.WatchConfig()
+
+	return nil
+}
+
+// Diff returns the keys of the settings which differ between the configurations,
+// like "trading.usdt_amount". Lists and maps are compared as a whole.
+func Diff(previous, next Config) []string {
+	return diff(reflect.ValueOf(previous), reflect.ValueOf(next), "")
+}
+
+func diff(previous, next reflect.Value, prefix string) []string {
+	var keys []string
+
+	for i := 0; i < previous.NumField(); i++ {
+		field := previous.Type().Field(i)
+
+		key := field.Tag.Get("mapstructure")
+		if prefix != "" {
+			key = strings.Join([]string{prefix, key}, ".")
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, diff(previous.Field(i), next.Field(i), key)...)
+			continue
+		}
+
+		if !reflect.DeepEqual(previous.Field(i).Interface(), next.Field(i).Interface()) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+func TestDiff(t *testing.T) {
+	previous := Config{
+		Trading:    Trading{USDTAmount: 10, Leverage: "20"},
+		Classifier: Classifier{ListingPatterns: []string{"Market Support for"}},
+	}
+	previous.ProxyRotatingPoller.Proxies = []httptools.Proxy{{Host: "proxy.example.com", Port: 8080}}
+
+	if keys := Diff(previous, previous); len(keys) != 0 {
+		t.Errorf("Diff() of equal configs = %v", keys)
+	}
+
+	next := previous
+	next.Trading.USDTAmount = 20
+	next.Classifier.ListingPatterns = []string{"Market Support for", "상장 안내"}
+	next.ProxyRotatingPoller.Proxies = []httptools.Proxy{{Host: "proxy.example.com", Port: 8081}}
+	next.Notifier.Queue.Capacity = 1
+
+	expected := []string{
+		"proxy_rotating_poller.proxies",
+		"notifier.queue.capacity",
+		"trading.usdt_amount",
+		"classifier.listing_patterns",
+	}
+
+	if keys := Diff(previous, next); !slices.Equal(keys, expected) {
+		t.Errorf("Diff() = %v, want %v", keys, expected)
+	}
+}
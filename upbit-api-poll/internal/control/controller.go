@@ -0,0 +1,345 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/core"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+var (
+	ErrUnknownSource = errors.New("unknown source")
+	ErrNoCursor      = errors.New("source has no notice ID cursor")
+)
+
+// Controller exposes runtime controls and state of the service to operators.
+// Every change is audit logged.
+type Controller struct {
+	sources   []Source
+	monitor   *core.NewsMonitor
+	scheduler *core.ActionScheduler
+	trading   *core.TradingSwitch
+	logger    *slog.Logger
+}
+
+// Source is a named poller of news
+type Source struct {
+	Name   string
+	Poller *httptools.ProxyRotatingPoller
+	// Fetcher is nil for sources without a notice ID cursor
+	Fetcher Fetcher
+}
+
+// Fetcher is a fetcher polling notices by ID
+type Fetcher interface {
+	Cursor() int
+	SetCursor(id int)
+	RecentResponses(n int) []httptools.Response
+}
+
+// SourceStatus is the state of a source at the moment
+type SourceStatus struct {
+	Name      string  `json:"name"`
+	URL       string  `json:"url"`
+	TargetRPS float64 `json:"target_rps"`
+	MaxRPS    float64 `json:"max_rps"`
+	// Profile is the RPS profile of the work schedule, polled with the effective RPS
+	Profile        string  `json:"profile"`
+	EffectiveRPS   float64 `json:"effective_rps"`
+	Paused         bool    `json:"paused"`
+	Proxies        int     `json:"proxies"`
+	HealthyProxies int     `json:"healthy_proxies"`
+	// Cursor is the next notice ID polled, zero for sources without a cursor
+	Cursor int `json:"cursor,omitempty"`
+}
+
+// Actor identifies the operator who issued a command
+type Actor struct {
+	Channel string
+	ID      string
+	Name    string
+}
+
+func NewController(
+	monitor *core.NewsMonitor,
+	scheduler *core.ActionScheduler,
+	trading *core.TradingSwitch,
+	logger *slog.Logger,
+	sources ...Source,
+) *Controller {
+	return &Controller{
+		sources:   sources,
+		monitor:   monitor,
+		scheduler: scheduler,
+		trading:   trading,
+		logger:    logger,
+	}
+}
+
+// Sources returns the status of all sources
+func (c *Controller) Sources() []SourceStatus {
+	statuses := make([]SourceStatus, 0, len(c.sources))
+	for _, source := range c.sources {
+		statuses = append(statuses, status(source))
+	}
+
+	return statuses
+}
+
+// Proxies returns the health of the proxies of the source
+func (c *Controller) Proxies(name string) ([]httptools.ProxyHealth, error) {
+	source, err := c.findOne(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return source.Poller.ProxiesHealth(), nil
+}
+
+// RecentResponses returns up to n latest responses polled by the source, newest first
+func (c *Controller) RecentResponses(name string, n int) ([]httptools.Response, error) {
+	source, err := c.findOne(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if source.Fetcher == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoCursor, source.Name)
+	}
+
+	return source.Fetcher.RecentResponses(n), nil
+}
+
+// SetCursor makes the source poll the notice with the ID next
+func (c *Controller) SetCursor(actor Actor, name string, id int) error {
+	source, err := c.findOne(name)
+	if err == nil && source.Fetcher == nil {
+		err = fmt.Errorf("%w: %s", ErrNoCursor, source.Name)
+	}
+
+	previous := 0
+	if err == nil {
+		previous = source.Fetcher.Cursor()
+		source.Fetcher.SetCursor(id)
+	}
+
+	c.audit(actor, "set_cursor", err, "source", name, "id", id, "previous", previous)
+
+	return err
+}
+
+// Pause pauses the named source or all sources if the name is empty.
+// It returns the names of the sources which were paused by the call.
+func (c *Controller) Pause(actor Actor, name string) ([]string, error) {
+	changed, err := c.forEach(name, func(poller *httptools.ProxyRotatingPoller) bool {
+		return poller.Pause()
+	})
+
+	c.audit(actor, "pause", err, "source", name, "changed", changed)
+
+	return changed, err
+}
+
+// Resume resumes the named source or all sources if the name is empty.
+// It returns the names of the sources which were resumed by the call.
+func (c *Controller) Resume(actor Actor, name string) ([]string, error) {
+	changed, err := c.forEach(name, func(poller *httptools.ProxyRotatingPoller) bool {
+		return poller.Resume()
+	})
+
+	c.audit(actor, "resume", err, "source", name, "changed", changed)
+
+	return changed, err
+}
+
+// SetTargetRPS changes the target RPS of the named source or all sources if the name is empty.
+func (c *Controller) SetTargetRPS(actor Actor, name string, rps float64) error {
+	sources, err := c.find(name)
+	if err == nil {
+		for _, source := range sources {
+			if err = source.Poller.SetTargetRPS(rps); err != nil {
+				err = fmt.Errorf("%s: %w", source.Name, err)
+				break
+			}
+		}
+	}
+
+	c.audit(actor, "set_target_rps", err, "source", name, "rps", rps)
+
+	return err
+}
+
+// SetSingleProxyMaxRPS changes the highest RPS of one proxy of the named source
+func (c *Controller) SetSingleProxyMaxRPS(actor Actor, name string, rps float64) error {
+	source, err := c.findOne(name)
+	if err == nil {
+		err = source.Poller.SetSingleProxyMaxRPS(rps)
+	}
+
+	c.audit(actor, "set_single_proxy_max_rps", err, "source", name, "rps", rps)
+
+	return err
+}
+
+// AddProxies adds the proxies to the pool of the named source
+func (c *Controller) AddProxies(actor Actor, name string, proxies []httptools.Proxy) error {
+	source, err := c.findOne(name)
+	if err == nil {
+		err = source.Poller.AddProxies(proxies...)
+	}
+
+	redacted := make([]string, 0, len(proxies))
+	for _, proxy := range proxies {
+		redacted = append(redacted, proxy.Redacted())
+	}
+
+	c.audit(actor, "add_proxies", err, "source", name, "proxies", redacted)
+
+	return err
+}
+
+// RemoveProxies removes the proxies with the redacted addresses from the pool of the named source
+// and waits until their requests in flight are completed.
+func (c *Controller) RemoveProxies(ctx context.Context, actor Actor, name string, addresses []string) error {
+	source, err := c.findOne(name)
+	if err == nil {
+		err = source.Poller.RemoveProxies(ctx, addresses...)
+	}
+
+	c.audit(actor, "remove_proxies", err, "source", name, "proxies", addresses)
+
+	return err
+}
+
+// TradingEnabled reports whether positions are opened on listing news
+func (c *Controller) TradingEnabled() bool {
+	return c.trading.Enabled()
+}
+
+// SetTrading switches trading on or off
+func (c *Controller) SetTrading(actor Actor, enabled bool) {
+	previous := c.trading.SetEnabled(enabled)
+
+	c.audit(actor, "set_trading", nil, "enabled", enabled, "previous", previous)
+}
+
+// RecentEvents returns up to n latest news events, newest first
+func (c *Controller) RecentEvents(n int) []entity.NewsEvent {
+	return c.monitor.RecentEvents(n)
+}
+
+// ScheduledJobs returns the pending scheduled actions
+func (c *Controller) ScheduledJobs() []core.ScheduledJob {
+	return c.scheduler.Pending()
+}
+
+// Audit records an operator action which does not change state, like a denied command
+func (c *Controller) Audit(actor Actor, action string, err error, args ...any) {
+	c.audit(actor, action, err, args...)
+}
+
+func (c *Controller) audit(actor Actor, action string, err error, args ...any) {
+	attrs := append([]any{
+		"audit", true,
+		"channel", actor.Channel,
+		"actor_id", actor.ID,
+		"actor", actor.Name,
+		"action", action,
+	}, args...)
+
+	if err != nil {
+		c.logger.Warn("operator action failed", append(attrs, "error", err)...)
+		return
+	}
+
+	c.logger.Info("operator action", attrs...)
+}
+
+func (c *Controller) forEach(name string, fn func(*httptools.ProxyRotatingPoller) bool) ([]string, error) {
+	sources, err := c.find(name)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if fn(source.Poller) {
+			changed = append(changed, source.Name)
+		}
+	}
+
+	return changed, nil
+}
+
+// find returns the named source or all sources if the name is empty
+func (c *Controller) find(name string) ([]Source, error) {
+	if name == "" {
+		return c.sources, nil
+	}
+
+	i := slices.IndexFunc(c.sources, func(source Source) bool {
+		return source.Name == name
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("%w %q, known: %v", ErrUnknownSource, name, c.names())
+	}
+
+	return c.sources[i : i+1], nil
+}
+
+// findOne returns the named source, the name can be omitted if there is only one source
+func (c *Controller) findOne(name string) (Source, error) {
+	sources, err := c.find(name)
+	if err != nil {
+		return Source{}, err
+	}
+
+	if len(sources) != 1 {
+		return Source{}, fmt.Errorf("%w: specify one of %v", ErrUnknownSource, c.names())
+	}
+
+	return sources[0], nil
+}
+
+func (c *Controller) names() []string {
+	names := make([]string, 0, len(c.sources))
+	for _, source := range c.sources {
+		names = append(names, source.Name)
+	}
+
+	return names
+}
+
+func status(source Source) SourceStatus {
+	proxies := source.Poller.ProxiesHealth()
+
+	healthy := 0
+	for _, proxy := range proxies {
+		if proxy.Healthy() {
+			healthy++
+		}
+	}
+
+	cursor := 0
+	if source.Fetcher != nil {
+		cursor = source.Fetcher.Cursor()
+	}
+
+	return SourceStatus{
+		Name:           source.Name,
+		URL:            source.Poller.URL(),
+		TargetRPS:      source.Poller.TargetRPS(),
+		MaxRPS:         source.Poller.MaxRPS(),
+		Profile:        source.Poller.Profile(),
+		EffectiveRPS:   source.Poller.EffectiveTargetRPS(),
+		Paused:         source.Poller.Paused(),
+		Proxies:        len(proxies),
+		HealthyProxies: healthy,
+		Cursor:         cursor,
+	}
+}
@@ -0,0 +1,142 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+)
+
+// executorCheckTimeout bounds a single check of the order executor
+const executorCheckTimeout = 5 * time.Second
+
+// ExecutorCheck returns an error if the order executor is not reachable
+type ExecutorCheck func(ctx context.Context) error
+
+// Health reports the liveness and the readiness of the service. The service is ready when every
+// working source had a recent successful response and the order executor is reachable.
+type Health struct {
+	config   config.Server
+	sources  []Source
+	executor ExecutorCheck
+
+	guard sync.Mutex
+	// executorErr is the result of the last check of the executor, nil before the first one
+	executorErr     error
+	executorChecked bool
+}
+
+// Readiness is the result of GET /readyz
+type Readiness struct {
+	Ready  bool    `json:"ready"`
+	Checks []Check `json:"checks"`
+}
+
+// Check is the state of a source or the executor
+type Check struct {
+	Name   string `json:"name"`
+	Ready  bool   `json:"ready"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func NewHealth(cfg config.Server, executor ExecutorCheck, sources ...Source) *Health {
+	return &Health{
+		config:   cfg,
+		sources:  sources,
+		executor: executor,
+	}
+}
+
+// RunExecutorChecks checks the executor on the configured interval until the context is done
+func (h *Health) RunExecutorChecks(ctx context.Context) {
+	ticker := time.NewTicker(h.config.ExecutorCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		h.checkExecutor(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Health) checkExecutor(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, executorCheckTimeout)
+	defer cancel()
+
+	err := h.executor(ctx)
+
+	h.guard.Lock()
+	defer h.guard.Unlock()
+
+	h.executorErr = err
+	h.executorChecked = true
+}
+
+// Readiness checks the sources and returns the last result of the executor check
+func (h *Health) Readiness(now time.Time) Readiness {
+	readiness := Readiness{Ready: true}
+
+	for _, source := range h.sources {
+		check := Check{Name: "source:" + source.Name, Ready: true}
+
+		switch lastSuccessAt := source.Poller.LastSuccessAt(); {
+		case !source.Poller.Working():
+			check.Detail = "not working, paused or out of the work schedule"
+		case lastSuccessAt.IsZero():
+			check.Ready = false
+			check.Detail = "no successful response yet"
+		case now.Sub(lastSuccessAt) > h.config.ReadyMaxAge:
+			check.Ready = false
+			check.Detail = fmt.Sprintf("last successful response %s ago", now.Sub(lastSuccessAt).Round(time.Second))
+		}
+
+		readiness.Checks = append(readiness.Checks, check)
+	}
+
+	h.guard.Lock()
+	executor := Check{Name: "executor", Ready: h.executorChecked && h.executorErr == nil}
+	switch {
+	case !h.executorChecked:
+		executor.Detail = "not checked yet"
+	case h.executorErr != nil:
+		executor.Detail = h.executorErr.Error()
+	}
+	h.guard.Unlock()
+
+	readiness.Checks = append(readiness.Checks, executor)
+
+	for _, check := range readiness.Checks {
+		readiness.Ready = readiness.Ready && check.Ready
+	}
+
+	return readiness
+}
+
+// Register adds the /healthz and /readyz routes to the mux
+func (h *Health) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", h.healthz)
+	mux.HandleFunc("GET /readyz", h.readyz)
+}
+
+// healthz reports that the service is alive, it's answered as long as the process serves requests
+func (h *Health) healthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Health) readyz(w http.ResponseWriter, _ *http.Request) {
+	readiness := h.Readiness(time.Now())
+
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, readiness)
+}
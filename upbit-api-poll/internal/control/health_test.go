@@ -0,0 +1,95 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+func newTestPoller(t *testing.T) *httptools.ProxyRotatingPoller {
+	t.Helper()
+
+	poller, err := httptools.NewProxyRotatingPollerBuilder().
+		WithURL("https://example.com/notices/{id}").
+		WithTargetRPS(1).
+		WithSingleProxyMaxRPS(1).
+		WithProxies(httptools.Proxy{Username: "user", Password: "secret", Host: "proxy.example.com", Port: 8080}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	return poller
+}
+
+func TestHealth_Readiness(t *testing.T) {
+	cfg := config.Server{ReadyMaxAge: time.Minute, ExecutorCheckInterval: time.Minute}
+	source := Source{Name: "notice_by_id", Poller: newTestPoller(t)}
+
+	executorErr := errors.New("connection refused")
+	health := NewHealth(cfg, func(context.Context) error { return executorErr }, source)
+
+	readiness := health.Readiness(time.Now())
+	if readiness.Ready {
+		t.Fatalf("Readiness() = %+v, expected not ready before the first response and executor check", readiness)
+	}
+
+	health.checkExecutor(context.Background())
+
+	if check := readiness.Checks[0]; check.Ready || check.Detail != "no successful response yet" {
+		t.Errorf("source check = %+v, expected not ready without responses", check)
+	}
+
+	if check := health.Readiness(time.Now()).Checks[1]; check.Ready || check.Detail != "connection refused" {
+		t.Errorf("executor check = %+v, expected the executor error", check)
+	}
+
+	// A paused source isn't polled, so it doesn't make the service unready
+	source.Poller.Pause()
+	executorErr = nil
+	health.checkExecutor(context.Background())
+
+	if readiness := health.Readiness(time.Now()); !readiness.Ready {
+		t.Errorf("Readiness() = %+v, expected ready with the source paused", readiness)
+	}
+}
+
+func TestServer_Handler(t *testing.T) {
+	health := NewHealth(
+		config.Server{ReadyMaxAge: time.Minute},
+		func(context.Context) error { return nil },
+		Source{Name: "notice_by_id", Poller: newTestPoller(t)},
+	)
+
+	metrics := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "metrics")
+	})
+
+	server := NewServer(config.Server{}, health, metrics, slog.New(slog.DiscardHandler))
+	handler := server.Handler()
+
+	if recorder := serve(handler, http.MethodGet, "/healthz", "", ""); recorder.Code != http.StatusOK {
+		t.Errorf("GET /healthz = %d", recorder.Code)
+	}
+
+	recorder := serve(handler, http.MethodGet, "/readyz", "", "")
+	if recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), `"name":"source:notice_by_id"`) {
+		t.Errorf("GET /readyz = %d %s, expected 503 without responses", recorder.Code, recorder.Body)
+	}
+
+	if recorder := serve(handler, http.MethodGet, "/metrics", "", ""); recorder.Body.String() != "metrics" {
+		t.Errorf("GET /metrics = %s", recorder.Body)
+	}
+
+	if recorder := serve(handler, http.MethodGet, "/debug/pprof/", "", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("GET /debug/pprof/ = %d, expected pprof only on its own listener", recorder.Code)
+	}
+}
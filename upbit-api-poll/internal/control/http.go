@@ -0,0 +1,363 @@
+package control
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	defaultListed = 20
+	maxListed     = 100
+
+	// maxResponseBody is the number of body bytes returned for every recent response
+	maxResponseBody = 64 << 10
+
+	adminShutdownTimeout = 5 * time.Second
+)
+
+// HTTPAPI serves the controller over HTTP for operators. Every request must be authorized with the token.
+type HTTPAPI struct {
+	config     config.Admin
+	controller *Controller
+	logger     *slog.Logger
+}
+
+// Status is the state of the service returned by GET /admin/status
+type Status struct {
+	Sources        []SourceStatus `json:"sources"`
+	TradingEnabled bool           `json:"trading_enabled"`
+	ScheduledJobs  int            `json:"scheduled_jobs"`
+}
+
+// RecentResponse is a polled response with the proxy credentials removed and the body truncated
+type RecentResponse struct {
+	ID          string      `json:"id"`
+	URL         string      `json:"url"`
+	RequestedAt time.Time   `json:"requested_at"`
+	ReceivedAt  time.Time   `json:"received_at"`
+	StatusCode  int         `json:"status_code"`
+	Headers     http.Header `json:"headers"`
+	Proxy       string      `json:"proxy"`
+	// Freshness is estimated from the Age, Date and Cf-Cache-Status headers
+	Freshness     httptools.Freshness `json:"freshness"`
+	ClientName    string              `json:"client_name"`
+	Body          string              `json:"body"`
+	BodyTruncated bool                `json:"body_truncated,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func NewHTTPAPI(cfg config.Admin, controller *Controller, logger *slog.Logger) *HTTPAPI {
+	return &HTTPAPI{
+		config:     cfg,
+		controller: controller,
+		logger:     logger,
+	}
+}
+
+// Run serves the API on the configured address until the context is done.
+func (a *HTTPAPI) Run(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              a.config.Address,
+		Handler:           a.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), adminShutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			a.logger.Error("failed to shut down admin API", "error", err)
+		}
+	}()
+
+	a.logger.Info("Admin API enabled", "address", a.config.Address)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve admin API: %w", err)
+	}
+
+	return nil
+}
+
+// Handler returns the authorized handler of the API routes
+func (a *HTTPAPI) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /admin/status", a.status)
+	mux.HandleFunc("GET /admin/events", a.events)
+	mux.HandleFunc("GET /admin/jobs", a.jobs)
+	mux.HandleFunc("GET /admin/sources/{source}/proxies", a.proxies)
+	mux.HandleFunc("GET /admin/sources/{source}/responses", a.responses)
+
+	mux.HandleFunc("POST /admin/pause", a.pauseOrResume)
+	mux.HandleFunc("POST /admin/resume", a.pauseOrResume)
+	mux.HandleFunc("POST /admin/sources/{source}/pause", a.pauseOrResume)
+	mux.HandleFunc("POST /admin/sources/{source}/resume", a.pauseOrResume)
+	mux.HandleFunc("POST /admin/sources/{source}/rps", a.setTargetRPS)
+	mux.HandleFunc("POST /admin/sources/{source}/proxy_rps", a.setSingleProxyMaxRPS)
+	mux.HandleFunc("POST /admin/sources/{source}/proxies", a.addProxies)
+	mux.HandleFunc("DELETE /admin/sources/{source}/proxies/{proxy}", a.removeProxy)
+	mux.HandleFunc("POST /admin/sources/{source}/cursor", a.setCursor)
+	mux.HandleFunc("POST /admin/trading", a.setTrading)
+
+	return a.authorize(mux)
+}
+
+func (a *HTTPAPI) authorize(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + a.config.Token)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := []byte(r.Header.Get("Authorization"))
+		if a.config.Token == "" || subtle.ConstantTimeCompare(provided, expected) != 1 {
+			a.controller.Audit(actor(r), "request_denied", nil, "method", r.Method, "path", r.URL.Path)
+			writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *HTTPAPI) status(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, Status{
+		Sources:        a.controller.Sources(),
+		TradingEnabled: a.controller.TradingEnabled(),
+		ScheduledJobs:  len(a.controller.ScheduledJobs()),
+	})
+}
+
+func (a *HTTPAPI) events(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.controller.RecentEvents(limit(r)))
+}
+
+func (a *HTTPAPI) jobs(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, a.controller.ScheduledJobs())
+}
+
+func (a *HTTPAPI) proxies(w http.ResponseWriter, r *http.Request) {
+	proxies, err := a.controller.Proxies(r.PathValue("source"))
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, proxies)
+}
+
+func (a *HTTPAPI) responses(w http.ResponseWriter, r *http.Request) {
+	responses, err := a.controller.RecentResponses(r.PathValue("source"), limit(r))
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	recent := make([]RecentResponse, 0, len(responses))
+	for _, response := range responses {
+		recent = append(recent, newRecentResponse(response))
+	}
+
+	writeJSON(w, http.StatusOK, recent)
+}
+
+func (a *HTTPAPI) pauseOrResume(w http.ResponseWriter, r *http.Request) {
+	action := a.controller.Pause
+	if strings.HasSuffix(r.URL.Path, "/resume") {
+		action = a.controller.Resume
+	}
+
+	changed, err := action(actor(r), r.PathValue("source"))
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string][]string{"changed": changed})
+}
+
+func (a *HTTPAPI) setTargetRPS(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		RPS float64 `json:"rps"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := a.controller.SetTargetRPS(actor(r), r.PathValue("source"), request.RPS); err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]float64{"target_rps": request.RPS})
+}
+
+func (a *HTTPAPI) setSingleProxyMaxRPS(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		RPS float64 `json:"rps"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := a.controller.SetSingleProxyMaxRPS(actor(r), r.PathValue("source"), request.RPS); err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]float64{"single_proxy_max_rps": request.RPS})
+}
+
+func (a *HTTPAPI) addProxies(w http.ResponseWriter, r *http.Request) {
+	var proxies []httptools.Proxy
+	if err := json.NewDecoder(r.Body).Decode(&proxies); err != nil || len(proxies) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("expected a non-empty list of proxies"))
+		return
+	}
+
+	validate := validator.New()
+	for _, proxy := range proxies {
+		if err := validate.Struct(proxy); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("proxy %s: %w", proxy.Redacted(), err))
+			return
+		}
+	}
+
+	if err := a.controller.AddProxies(actor(r), r.PathValue("source"), proxies); err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	a.proxies(w, r)
+}
+
+// removeProxy removes the proxy with the redacted address, waiting for its requests in flight
+// as long as the client waits for the response.
+func (a *HTTPAPI) removeProxy(w http.ResponseWriter, r *http.Request) {
+	err := a.controller.RemoveProxies(r.Context(), actor(r), r.PathValue("source"), []string{r.PathValue("proxy")})
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	a.proxies(w, r)
+}
+
+func (a *HTTPAPI) setCursor(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		ID int `json:"id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.ID <= 0 {
+		writeError(w, http.StatusBadRequest, errors.New(`expected {"id": <positive notice ID>}`))
+		return
+	}
+
+	if err := a.controller.SetCursor(actor(r), r.PathValue("source"), request.ID); err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]int{"cursor": request.ID})
+}
+
+func (a *HTTPAPI) setTrading(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Enabled *bool `json:"enabled"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Enabled == nil {
+		writeError(w, http.StatusBadRequest, errors.New(`expected {"enabled": true|false}`))
+		return
+	}
+
+	a.controller.SetTrading(actor(r), *request.Enabled)
+
+	writeJSON(w, http.StatusOK, map[string]bool{"trading_enabled": a.controller.TradingEnabled()})
+}
+
+// actor identifies the HTTP client by the remote address and the optional X-Actor header
+func actor(r *http.Request) Actor {
+	return Actor{
+		Channel: "http",
+		ID:      r.RemoteAddr,
+		Name:    r.Header.Get("X-Actor"),
+	}
+}
+
+func limit(r *http.Request) int {
+	n, err := strconv.Atoi(r.URL.Query().Get("n"))
+	if err != nil || n <= 0 {
+		return defaultListed
+	}
+
+	return min(n, maxListed)
+}
+
+func newRecentResponse(response httptools.Response) RecentResponse {
+	body := response.Body
+	truncated := len(body) > maxResponseBody
+	if truncated {
+		body = body[:maxResponseBody]
+	}
+
+	return RecentResponse{
+		ID:            response.ID.String(),
+		URL:           response.URL,
+		RequestedAt:   response.RequestedAt,
+		ReceivedAt:    response.ReceivedAt,
+		StatusCode:    response.StatusCode,
+		Headers:       response.Headers,
+		Proxy:         httptools.RedactProxyAddress(response.ProxyAddr),
+		Freshness:     response.Freshness,
+		ClientName:    response.ClientName,
+		Body:          string(body),
+		BodyTruncated: truncated,
+	}
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrUnknownSource), errors.Is(err, httptools.ErrUnknownProxy):
+		return http.StatusNotFound
+	case errors.Is(err, httptools.ErrProxyExists):
+		return http.StatusConflict
+	case errors.Is(err, ErrNoCursor), errors.Is(err, httptools.ErrTargetRPSTooHigh),
+		errors.Is(err, httptools.ErrTargetRPSRequired), errors.Is(err, httptools.ErrSingleProxyMaxRPSRequired):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,190 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/core"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+// ReloadResult is the outcome of applying a reloaded configuration
+type ReloadResult struct {
+	// Applied are the changed keys applied at runtime
+	Applied []string
+	// RestartRequired are the changed keys which take effect after a restart
+	RestartRequired []string
+}
+
+// liveKeyPrefixes are the configuration keys which ApplyConfig changes at runtime
+var liveKeyPrefixes = []string{
+	"proxy_rotating_poller.target_rps",
+	"proxy_rotating_poller.proxies",
+	"proxy_rotating_poller.work_schedule.",
+	"upbit_api.announcements_single_ip_max_rps",
+	"upbit_api.announcement_by_id_single_ip_max_rps",
+	"upbit_api.notice_by_id_single_ip_max_rps",
+	"classifier.listing_patterns",
+	"trading.",
+}
+
+// ApplyConfig applies the settings changed between the configurations which can be changed
+// at runtime: the proxies, RPS and work schedule of the sources, the listing patterns and trading.
+// A changed setting is reconciled with the live state, so changes made at runtime through
+// the admin API or Telegram don't fail the reload, settings not changed in the file keep them.
+// Changes of one source which fail don't stop the others, all errors are joined.
+func (c *Controller) ApplyConfig(ctx context.Context, actor Actor, previous, next config.Config) (ReloadResult, error) {
+	result := ReloadResult{}
+
+	changed := config.Diff(previous, next)
+	for _, key := range changed {
+		live := slices.ContainsFunc(liveKeyPrefixes, func(prefix string) bool {
+			return key == prefix || (strings.HasSuffix(prefix, ".") && strings.HasPrefix(key, prefix))
+		})
+
+		if live {
+			result.Applied = append(result.Applied, key)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+
+	var errs []error
+
+	if _, err := time.LoadLocation(next.ProxyRotatingPoller.WorkSchedule.TimeZone); err != nil {
+		// The pollers panic on an unknown time zone, the schedule is kept
+		next.ProxyRotatingPoller.WorkSchedule = previous.ProxyRotatingPoller.WorkSchedule
+		errs = append(errs, fmt.Errorf("work schedule: %w", err))
+	}
+
+	for _, source := range c.sources {
+		if err := applySourceConfig(ctx, source, previous, next); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name, err))
+		}
+	}
+
+	if !slices.Equal(previous.Classifier.ListingPatterns, next.Classifier.ListingPatterns) {
+		c.monitor.SetListingPatterns(next.Classifier.ListingPatterns)
+	}
+
+	if previous.Trading.Enabled != next.Trading.Enabled {
+		c.trading.SetEnabled(next.Trading.Enabled)
+	}
+
+	if previous.Trading.USDTAmount != next.Trading.USDTAmount || previous.Trading.Leverage != next.Trading.Leverage {
+		c.trading.SetSize(core.TradeSize{USDTAmount: next.Trading.USDTAmount, Leverage: next.Trading.Leverage})
+	}
+
+	err := errors.Join(errs...)
+
+	c.audit(
+		actor,
+		"reload_config",
+		err,
+		"applied", result.Applied,
+		"restart_required", result.RestartRequired,
+	)
+
+	return result, err
+}
+
+// applySourceConfig changes the poller. The order keeps the target RPS within
+// the capacity of the proxies at every step: a lower target is set first, then proxies are added,
+// the single proxy RPS is changed, proxies are removed, and a higher target is set last.
+func applySourceConfig(ctx context.Context, source Source, previous, next config.Config) error {
+	poller := source.Poller
+	targetRPS := next.ProxyRotatingPoller.TargetRPS
+	targetRPSChanged := previous.ProxyRotatingPoller.TargetRPS != targetRPS
+
+	var errs []error
+
+	if targetRPSChanged && targetRPS < poller.TargetRPS() {
+		errs = append(errs, poller.SetTargetRPS(targetRPS))
+	}
+
+	added, removed := make([]httptools.Proxy, 0), make([]string, 0)
+	if !slices.Equal(previous.ProxyRotatingPoller.Proxies, next.ProxyRotatingPoller.Proxies) {
+		added, removed = diffProxies(poller.ProxyAddresses(), next.ProxyRotatingPoller.Proxies)
+	}
+
+	// Proxies with changed credentials are replaced, the redacted address is the same for both
+	replaced := make([]httptools.Proxy, 0)
+	added = slices.DeleteFunc(added, func(proxy httptools.Proxy) bool {
+		if slices.Contains(removed, proxy.Redacted()) {
+			replaced = append(replaced, proxy)
+			return true
+		}
+
+		return false
+	})
+
+	if len(added) > 0 {
+		errs = append(errs, poller.AddProxies(added...))
+	}
+
+	previousSingleProxyMaxRPS, _ := singleProxyMaxRPS(previous.UpbitAPI, source.Name)
+	singleProxyMaxRPS, ok := singleProxyMaxRPS(next.UpbitAPI, source.Name)
+	if ok && singleProxyMaxRPS != previousSingleProxyMaxRPS && singleProxyMaxRPS != poller.SingleProxyMaxRPS() {
+		errs = append(errs, poller.SetSingleProxyMaxRPS(singleProxyMaxRPS))
+	}
+
+	if len(removed) > 0 {
+		if err := poller.RemoveProxies(ctx, removed...); err != nil {
+			errs = append(errs, err)
+		} else if len(replaced) > 0 {
+			errs = append(errs, poller.AddProxies(replaced...))
+		}
+	}
+
+	if targetRPSChanged && targetRPS > poller.TargetRPS() {
+		errs = append(errs, poller.SetTargetRPS(targetRPS))
+	}
+
+	if !reflect.DeepEqual(previous.ProxyRotatingPoller.WorkSchedule, next.ProxyRotatingPoller.WorkSchedule) {
+		workSchedule := next.ProxyRotatingPoller.WorkSchedule
+		poller.SetWorkSchedule(&workSchedule)
+	}
+
+	return errors.Join(errs...)
+}
+
+// diffProxies returns the proxies of the list missing in the pool and the redacted addresses
+// of the pool proxies missing in the list
+func diffProxies(live []string, next []httptools.Proxy) ([]httptools.Proxy, []string) {
+	added := make([]httptools.Proxy, 0)
+	for _, proxy := range next {
+		if !slices.Contains(live, proxy.String()) {
+			added = append(added, proxy)
+		}
+	}
+
+	removed := make([]string, 0)
+	for _, address := range live {
+		if !slices.ContainsFunc(next, func(proxy httptools.Proxy) bool { return proxy.String() == address }) {
+			removed = append(removed, httptools.RedactProxyAddress(address))
+		}
+	}
+
+	return added, removed
+}
+
+// singleProxyMaxRPS returns the max RPS of one proxy for the endpoint polled by the source
+func singleProxyMaxRPS(cfg config.UpbitAPI, source string) (float64, bool) {
+	switch source {
+	case entity.NewsSourceNoticeByID:
+		return cfg.NoticeByIDSingleIPMaxRPS, true
+	case entity.NewsSourceAnnouncementByID:
+		return cfg.AnnouncementByIDSingleIPMaxRPS, true
+	case entity.NewsSourceAnnouncements:
+		return cfg.AnnouncementsSingleIPMaxRPS, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,106 @@
+package control
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/core"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+func TestController_ApplyConfig(t *testing.T) {
+	previous := config.Config{
+		Trading: config.Trading{Enabled: true, USDTAmount: 10, Leverage: "20"},
+	}
+	previous.ProxyRotatingPoller.WorkSchedule.TimeZone = "Asia/Seoul"
+
+	trading := core.NewTradingSwitch(previous.Trading)
+	controller := NewController(nil, nil, trading, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	next := previous
+	next.Trading = config.Trading{Enabled: false, USDTAmount: 25, Leverage: "10"}
+	next.Logger.Level = "debug"
+
+	result, err := controller.ApplyConfig(context.Background(), Actor{Channel: "test"}, previous, next)
+	if err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+
+	applied := []string{"trading.enabled", "trading.usdt_amount", "trading.leverage"}
+	if !slices.Equal(result.Applied, applied) || !slices.Equal(result.RestartRequired, []string{"logger.level"}) {
+		t.Errorf("ApplyConfig() = %+v", result)
+	}
+
+	if trading.Enabled() || trading.Size() != (core.TradeSize{USDTAmount: 25, Leverage: "10"}) {
+		t.Errorf("trading is not applied: enabled %v, size %+v", trading.Enabled(), trading.Size())
+	}
+
+	next.ProxyRotatingPoller.WorkSchedule.TimeZone = "Mars/Olympus"
+	if _, err := controller.ApplyConfig(context.Background(), Actor{Channel: "test"}, previous, next); err == nil {
+		t.Error("ApplyConfig() should reject an unknown time zone")
+	}
+}
+
+func TestController_ApplyConfig_ReconcilesRuntimeChanges(t *testing.T) {
+	poller := newTestPoller(t)
+	controller := NewController(
+		nil,
+		nil,
+		core.NewTradingSwitch(config.Trading{}),
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Source{Name: "notice_by_id", Poller: poller},
+	)
+
+	configured := httptools.Proxy{Username: "user", Password: "secret", Host: "proxy.example.com", Port: 8080}
+	runtime := httptools.Proxy{Username: "user", Password: "secret", Host: "proxy2.example.com", Port: 8080}
+
+	previous := config.Config{}
+	previous.ProxyRotatingPoller.TargetRPS = 1
+	previous.ProxyRotatingPoller.Proxies = []httptools.Proxy{configured}
+	previous.ProxyRotatingPoller.WorkSchedule.TimeZone = "Asia/Seoul"
+	previous.UpbitAPI.NoticeByIDSingleIPMaxRPS = 1
+
+	// The proxy and the target RPS are changed through the admin API
+	if err := poller.AddProxies(runtime); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := poller.SetTargetRPS(2); err != nil {
+		t.Fatal(err)
+	}
+
+	next := previous
+	next.ProxyRotatingPoller.Proxies = []httptools.Proxy{configured, runtime}
+
+	if _, err := controller.ApplyConfig(context.Background(), Actor{Channel: "test"}, previous, next); err != nil {
+		t.Fatalf("ApplyConfig() of a proxy added at runtime error = %v", err)
+	}
+
+	if got := poller.TargetRPS(); got != 2 {
+		t.Errorf("target RPS is %v, want the runtime one kept as it's unchanged in the file", got)
+	}
+
+	if got := poller.ProxyAddresses(); len(got) != 2 {
+		t.Errorf("pool has %d proxies, want 2", len(got))
+	}
+
+	if err := poller.SetTargetRPS(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := poller.RemoveProxies(context.Background(), runtime.Redacted()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := controller.ApplyConfig(context.Background(), Actor{Channel: "test"}, next, previous); err != nil {
+		t.Fatalf("ApplyConfig() of a proxy removed at runtime error = %v", err)
+	}
+
+	if got := poller.ProxyAddresses(); !slices.Equal(got, []string{configured.String()}) {
+		t.Errorf("pool has %d proxies, want only the configured one", len(got))
+	}
+}
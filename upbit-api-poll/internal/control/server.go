@@ -0,0 +1,117 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"sync"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+)
+
+// Server serves the metrics and the health of the service on the public listeners
+// and, if enabled, the profiles on the separate pprof listener.
+type Server struct {
+	config  config.Server
+	health  *Health
+	metrics http.Handler
+	logger  *slog.Logger
+}
+
+func NewServer(cfg config.Server, health *Health, metrics http.Handler, logger *slog.Logger) *Server {
+	return &Server{
+		config:  cfg,
+		health:  health,
+		metrics: metrics,
+		logger:  logger,
+	}
+}
+
+// Run serves all listeners until the context is done. It returns the errors of the listeners which failed.
+func (s *Server) Run(ctx context.Context) error {
+	handler := s.Handler()
+
+	errs := make([]error, len(s.config.Addresses)+1)
+	wg := sync.WaitGroup{}
+
+	for i, address := range s.config.Addresses {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s.logger.Info("Serving metrics and health", "address", address)
+			errs[i] = listen(ctx, address, handler, s.logger)
+		}()
+	}
+
+	if s.config.Pprof.Enabled {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s.logger.Info("Serving pprof", "address", s.config.Pprof.Address)
+			errs[len(errs)-1] = listen(ctx, s.config.Pprof.Address, PprofHandler(), s.logger)
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Handler returns the handler of the public listeners
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("GET /metrics", s.metrics)
+	s.health.Register(mux)
+
+	return mux
+}
+
+// PprofHandler returns the handler of the profiles, registered explicitly instead of the default mux
+func PprofHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return mux
+}
+
+func listen(ctx context.Context, address string, handler http.Handler, logger *slog.Logger) error {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), adminShutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("failed to shut down listener", "address", address, "error", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		// The other listeners keep serving, the failure is reported at once
+		logger.Error("listener failed", "address", address, "error", err)
+
+		return fmt.Errorf("serve %s: %w", address, err)
+	}
+
+	return nil
+}
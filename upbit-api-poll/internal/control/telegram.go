@@ -0,0 +1,309 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/mymmrac/telego"
+)
+
+const (
+	// longPollingTimeout is the time in seconds Telegram holds a getUpdates request without updates
+	longPollingTimeout = 30
+
+	defaultLastEvents = 5
+	maxLastEvents     = 20
+	maxProxiesListed  = 50
+)
+
+const telegramHelp = `<b>Commands</b>
+/status - sources, RPS, proxy health and trading state
+/pause [source] - pause polling
+/resume [source] - resume polling
+/trade on|off - switch opening of positions
+/rps &lt;n&gt; [source] - change target RPS
+/last [n] - recent news events
+/proxies [source] - proxy health`
+
+// TelegramCommands handles operator commands sent to the bot from authorized chats
+type TelegramCommands struct {
+	bot        *telego.Bot
+	config     config.Telegram
+	controller *Controller
+	logger     *slog.Logger
+}
+
+func NewTelegramCommands(
+	bot *telego.Bot,
+	cfg config.Telegram,
+	controller *Controller,
+	logger *slog.Logger,
+) *TelegramCommands {
+	return &TelegramCommands{
+		bot:        bot,
+		config:     cfg,
+		controller: controller,
+		logger:     logger,
+	}
+}
+
+// Run long polls the bot updates and handles commands until the context is done.
+func (t *TelegramCommands) Run(ctx context.Context) error {
+	updates, err := t.bot.UpdatesViaLongPolling(ctx, &telego.GetUpdatesParams{
+		Timeout:        longPollingTimeout,
+		AllowedUpdates: []string{"message"},
+	})
+	if err != nil {
+		return fmt.Errorf("start long polling: %w", err)
+	}
+
+	t.logger.Info("Telegram commands enabled")
+
+	for update := range updates {
+		if update.Message != nil {
+			t.handle(ctx, update.Message)
+		}
+	}
+
+	return nil
+}
+
+func (t *TelegramCommands) handle(ctx context.Context, message *telego.Message) {
+	command, args, ok := parseCommand(message.Text)
+	if !ok {
+		return
+	}
+
+	actor := Actor{Channel: "telegram", ID: strconv.FormatInt(message.Chat.ID, 10)}
+	userID := int64(0)
+	if message.From != nil {
+		userID = message.From.ID
+		actor.ID = strconv.FormatInt(userID, 10)
+		actor.Name = message.From.Username
+	}
+
+	if !t.config.ChatAuthorized(message.Chat.ID) || !t.config.UserAuthorized(userID) {
+		t.controller.Audit(
+			actor,
+			"command_denied",
+			nil,
+			"command", command,
+			"args", args,
+			"chat_id", message.Chat.ID,
+		)
+		return
+	}
+
+	t.controller.Audit(actor, "command", nil, "command", command, "args", args, "chat_id", message.Chat.ID)
+
+	reply := t.execute(actor, command, args)
+
+	if _, err := t.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID:    telego.ChatID{ID: message.Chat.ID},
+		Text:      reply,
+		ParseMode: telego.ModeHTML,
+	}); err != nil {
+		t.logger.Error("failed to reply to command", "command", command, "error", err)
+	}
+}
+
+func (t *TelegramCommands) execute(actor Actor, command string, args []string) string {
+	switch command {
+	case "status":
+		return t.status()
+
+	case "pause", "resume":
+		return t.pauseOrResume(actor, command, arg(args, 0))
+
+	case "trade":
+		return t.trade(actor, arg(args, 0))
+
+	case "rps":
+		return t.rps(actor, args)
+
+	case "last":
+		return t.last(arg(args, 0))
+
+	case "proxies":
+		return t.proxies(arg(args, 0))
+
+	default:
+		return telegramHelp
+	}
+}
+
+func (t *TelegramCommands) status() string {
+	builder := strings.Builder{}
+	builder.WriteString("<b>Sources</b>\n")
+
+	for _, source := range t.controller.Sources() {
+		state := "▶️"
+		if source.Paused {
+			state = "⏸"
+		}
+
+		fmt.Fprintf(
+			&builder,
+			"%s <b>%s</b> %.2f/%.2f rps (%s %.2f rps), proxies %d/%d healthy\n<code>%s</code>\n",
+			state,
+			html.EscapeString(source.Name),
+			source.TargetRPS,
+			source.MaxRPS,
+			html.EscapeString(source.Profile),
+			source.EffectiveRPS,
+			source.HealthyProxies,
+			source.Proxies,
+			html.EscapeString(source.URL),
+		)
+	}
+
+	fmt.Fprintf(&builder, "\n<b>Trading:</b> %s\n", onOff(t.controller.TradingEnabled()))
+	fmt.Fprintf(&builder, "<b>Scheduled actions:</b> %d\n", len(t.controller.ScheduledJobs()))
+
+	return builder.String()
+}
+
+func (t *TelegramCommands) pauseOrResume(actor Actor, command, source string) string {
+	action := t.controller.Pause
+	if command == "resume" {
+		action = t.controller.Resume
+	}
+
+	changed, err := action(actor, source)
+	if err != nil {
+		return "❌ " + html.EscapeString(err.Error())
+	}
+
+	if len(changed) == 0 {
+		return fmt.Sprintf("Nothing to %s", command)
+	}
+
+	return fmt.Sprintf("✅ %sd: %s", command, html.EscapeString(strings.Join(changed, ", ")))
+}
+
+func (t *TelegramCommands) trade(actor Actor, state string) string {
+	switch strings.ToLower(state) {
+	case "on":
+		t.controller.SetTrading(actor, true)
+	case "off":
+		t.controller.SetTrading(actor, false)
+	case "":
+	default:
+		return "Usage: /trade on|off"
+	}
+
+	return fmt.Sprintf("<b>Trading:</b> %s", onOff(t.controller.TradingEnabled()))
+}
+
+func (t *TelegramCommands) rps(actor Actor, args []string) string {
+	rps, err := strconv.ParseFloat(arg(args, 0), 64)
+	if err != nil {
+		return "Usage: /rps &lt;n&gt; [source]"
+	}
+
+	if err := t.controller.SetTargetRPS(actor, arg(args, 1), rps); err != nil {
+		return "❌ " + html.EscapeString(err.Error())
+	}
+
+	return fmt.Sprintf("✅ Target RPS set to %.2f", rps)
+}
+
+func (t *TelegramCommands) last(count string) string {
+	n := defaultLastEvents
+	if parsed, err := strconv.Atoi(count); err == nil && parsed > 0 {
+		n = min(parsed, maxLastEvents)
+	}
+
+	events := t.controller.RecentEvents(n)
+	if len(events) == 0 {
+		return "No events received yet"
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString("<b>Recent events</b>\n")
+
+	for _, event := range events {
+		fmt.Fprintf(
+			&builder,
+			"%s [%s] %s\n",
+			event.ReceivedAt.Format(time.DateTime),
+			html.EscapeString(event.Source),
+			html.EscapeString(string(event.Title)),
+		)
+	}
+
+	return builder.String()
+}
+
+func (t *TelegramCommands) proxies(source string) string {
+	proxies, err := t.controller.Proxies(source)
+	if err != nil {
+		return "❌ " + html.EscapeString(err.Error())
+	}
+
+	builder := strings.Builder{}
+	fmt.Fprintf(&builder, "<b>Proxies</b> (%d)\n", len(proxies))
+
+	for i, proxy := range proxies {
+		if i == maxProxiesListed {
+			fmt.Fprintf(&builder, "... and %d more\n", len(proxies)-maxProxiesListed)
+			break
+		}
+
+		state := "✅"
+		if !proxy.Healthy() {
+			state = "❌"
+		}
+
+		fmt.Fprintf(
+			&builder,
+			"%s <code>%s</code> status %d, requests %d, errors %d, 429 %d, staleness %s\n",
+			state,
+			html.EscapeString(proxy.Proxy),
+			proxy.LastStatus,
+			proxy.Requests,
+			proxy.Errors,
+			proxy.TooManyRequests,
+			proxy.Staleness.Round(time.Millisecond),
+		)
+	}
+
+	return builder.String()
+}
+
+// parseCommand splits "/command@bot arg1 arg2" into the lower case command and its arguments.
+func parseCommand(text string) (string, []string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil, false
+	}
+
+	command, _, _ := strings.Cut(fields[0][1:], "@")
+	if command == "" {
+		return "", nil, false
+	}
+
+	return strings.ToLower(command), fields[1:], true
+}
+
+func arg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+
+	return ""
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "ON"
+	}
+
+	return "OFF"
+}
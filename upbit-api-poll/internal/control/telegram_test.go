@@ -0,0 +1,97 @@
+package control
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/core"
+)
+
+func TestParseCommand(t *testing.T) {
+	testCases := []struct {
+		text    string
+		command string
+		args    []string
+		ok      bool
+	}{
+		{"/status", "status", []string{}, true},
+		{"/RPS@upbit_poller_bot 2.5  notice_by_id", "rps", []string{"2.5", "notice_by_id"}, true},
+		{"  /trade off", "trade", []string{"off"}, true},
+		{"status", "", nil, false},
+		{"/", "", nil, false},
+		{"", "", nil, false},
+	}
+
+	for _, tc := range testCases {
+		command, args, ok := parseCommand(tc.text)
+		if command != tc.command || ok != tc.ok || !slices.Equal(args, tc.args) {
+			t.Errorf(
+				"parseCommand(%q) = %q, %q, %v, want %q, %q, %v",
+				tc.text,
+				command,
+				args,
+				ok,
+				tc.command,
+				tc.args,
+				tc.ok,
+			)
+		}
+	}
+}
+
+func TestTelegramAuthorization(t *testing.T) {
+	cfg := config.Telegram{
+		GroupID: -100,
+		Commands: config.TelegramCommands{
+			AuthorizedChatIDs: []int64{42},
+		},
+	}
+
+	if !cfg.ChatAuthorized(-100) || !cfg.ChatAuthorized(42) || cfg.ChatAuthorized(7) {
+		t.Error("only the group and the authorized chats should be authorized")
+	}
+
+	if cfg.UserAuthorized(7) {
+		t.Error("no user should be authorized without authorized users")
+	}
+
+	cfg.Commands.AuthorizedUserIDs = []int64{1}
+	if !cfg.UserAuthorized(1) || cfg.UserAuthorized(7) {
+		t.Error("only the authorized users should be authorized")
+	}
+}
+
+func TestTelegramCommands_Execute(t *testing.T) {
+	controller := NewController(
+		nil,
+		nil,
+		core.NewTradingSwitch(config.Trading{Enabled: true}),
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+	commands := NewTelegramCommands(nil, config.Telegram{}, controller, controller.logger)
+	actor := Actor{Channel: "test"}
+
+	if reply := commands.execute(actor, "trade", []string{"off"}); reply != "<b>Trading:</b> OFF" {
+		t.Errorf("/trade off = %q", reply)
+	}
+
+	if controller.TradingEnabled() {
+		t.Error("trading should be switched off")
+	}
+
+	if reply := commands.execute(actor, "rps", []string{"abc"}); reply != "Usage: /rps &lt;n&gt; [source]" {
+		t.Errorf("/rps abc = %q", reply)
+	}
+
+	if reply := commands.execute(actor, "unknown", nil); reply != telegramHelp {
+		t.Errorf("/unknown = %q, want help", reply)
+	}
+
+	if _, err := controller.Pause(actor, "missing"); !errors.Is(err, ErrUnknownSource) {
+		t.Errorf("Pause() of an unknown source error = %v", err)
+	}
+}
@@ -0,0 +1,399 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/tickers"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/gate"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+// ScheduledJob is a configured action bound to a listed ticker. It is kept in the state file until it runs.
+type ScheduledJob struct {
+	ID              string    `json:"id"`
+	Action          string    `json:"action"`
+	Type            string    `json:"type"`
+	Ticker          string    `json:"ticker"`
+	Title           string    `json:"title"`
+	TradingStartsAt time.Time `json:"trading_starts_at"`
+	RunAt           time.Time `json:"run_at"`
+}
+
+// ActionExecutor runs a job of one action type
+type ActionExecutor func(ctx context.Context, job ScheduledJob) error
+
+// ActionScheduler runs the configured actions relative to the trading start time announced in a notice.
+// Pending jobs are persisted in the background, so they survive restarts without delaying the orders.
+type ActionScheduler struct {
+	config    config.ActionScheduler
+	executors map[string]ActionExecutor
+	logger    *slog.Logger
+	notifier  httptools.Notifier
+
+	guard   sync.Mutex
+	ctx     context.Context
+	jobs    map[string]ScheduledJob
+	timers  map[string]*time.Timer
+	running sync.WaitGroup
+
+	// version counts the changes of the jobs, saved is the last version written to the state file
+	version   uint64
+	saveGuard sync.Mutex
+	saved     uint64
+	saving    sync.WaitGroup
+}
+
+// NewActionExecutors returns the executors of all action types supported by the configuration.
+// Positions are not opened while trading is switched off.
+func NewActionExecutors(deps di.Container, tradingSwitch *TradingSwitch) map[string]ActionExecutor {
+	return map[string]ActionExecutor{
+		config.ScheduledActionOpenPosition: func(_ context.Context, job ScheduledJob) error {
+			if !tradingSwitch.Enabled() {
+				return ErrTradingDisabled
+			}
+
+			size := tradingSwitch.Size()
+
+			_, _, err := gate.OpenFuturesOrder(
+				deps.Metrics,
+				gate.Credentials{Key: deps.Config.Gate.APIKey, Secret: deps.Config.Gate.APISecret},
+				job.Ticker,
+				size.USDTAmount,
+				size.Leverage,
+			)
+			if err != nil {
+				return err
+			}
+
+			deps.SendAlert("✅ Scheduled position opened for <b>%s</b>", job.Ticker)
+			return nil
+		},
+
+		config.ScheduledActionReminder: func(_ context.Context, job ScheduledJob) error {
+			deps.SendAlert(
+				"⏰ <b>%s</b> trading starts in %s (%s)\n%s",
+				job.Ticker,
+				time.Until(job.TradingStartsAt).Round(time.Second),
+				job.TradingStartsAt.Format("2006-01-02 15:04:05 MST"),
+				html.EscapeString(job.Title),
+			)
+			return nil
+		},
+	}
+}
+
+// NewActionScheduler creates a scheduler and loads the pending jobs from the state file.
+func NewActionScheduler(
+	cfg config.ActionScheduler,
+	executors map[string]ActionExecutor,
+	logger *slog.Logger,
+	notifier httptools.Notifier,
+) (*ActionScheduler, error) {
+	for _, action := range cfg.Actions {
+		if _, ok := executors[action.Type]; !ok {
+			return nil, fmt.Errorf("no executor for action %q of type %q", action.Name, action.Type)
+		}
+	}
+
+	s := &ActionScheduler{
+		config:    cfg,
+		executors: executors,
+		logger:    logger,
+		notifier:  notifier,
+		jobs:      make(map[string]ScheduledJob),
+		timers:    make(map[string]*time.Timer),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Run arms the timers of the pending jobs and blocks until the context is done.
+// Jobs scheduled before Run are armed when it starts.
+func (s *ActionScheduler) Run(ctx context.Context) {
+	s.guard.Lock()
+	s.ctx = ctx
+	for _, job := range s.jobs {
+		s.arm(job)
+	}
+	s.guard.Unlock()
+
+	s.logger.Info("Action scheduler started", "pending_jobs", len(s.Pending()))
+
+	<-ctx.Done()
+
+	s.guard.Lock()
+	for id, timer := range s.timers {
+		timer.Stop()
+		delete(s.timers, id)
+	}
+	s.ctx = nil
+	s.guard.Unlock()
+
+	s.running.Wait()
+	s.saving.Wait()
+}
+
+// Schedule creates a job for every configured action and symbol of the event.
+// A position is opened only for the first symbol tradable on the venue, like the immediate order.
+// Events without a trading start time and jobs which are already scheduled or too late are skipped.
+func (s *ActionScheduler) Schedule(event entity.NewsEvent, symbols []tickers.Symbol) []ScheduledJob {
+	startsAt, ok := event.TradingStartTime()
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	scheduled := make([]ScheduledJob, 0, len(s.config.Actions)*len(symbols))
+	tradable := slices.IndexFunc(symbols, tickers.Symbol.Tradable)
+
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	for _, action := range s.config.Actions {
+		for i, symbol := range symbols {
+			if action.Type == config.ScheduledActionOpenPosition && i != tradable {
+				continue
+			}
+
+			job := ScheduledJob{
+				ID: fmt.Sprintf(
+					"%s/%s/%d",
+					action.Name,
+					symbol.Ticker,
+					startsAt.Unix(),
+				),
+				Action:          action.Name,
+				Type:            action.Type,
+				Ticker:          symbol.Ticker,
+				Title:           string(event.Title),
+				TradingStartsAt: startsAt,
+				RunAt:           startsAt.Add(action.Offset),
+			}
+
+			if _, ok := s.jobs[job.ID]; ok {
+				continue
+			}
+
+			if s.expired(job, now) {
+				continue
+			}
+
+			s.jobs[job.ID] = job
+			s.arm(job)
+			scheduled = append(scheduled, job)
+		}
+	}
+
+	if len(scheduled) > 0 {
+		s.persist()
+	}
+
+	return scheduled
+}
+
+// SchedulesPositions reports whether an action opens positions, the immediate order waits for the schedule then
+func (s *ActionScheduler) SchedulesPositions() bool {
+	return slices.ContainsFunc(s.config.Actions, func(action config.ScheduledAction) bool {
+		return action.Type == config.ScheduledActionOpenPosition
+	})
+}
+
+// Cancel removes a pending job. It returns false if there is no such job.
+func (s *ActionScheduler) Cancel(id string) bool {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return false
+	}
+
+	s.remove(id)
+
+	return true
+}
+
+// Pending returns the pending jobs ordered by run time.
+func (s *ActionScheduler) Pending() []ScheduledJob {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	return s.sortedJobs()
+}
+
+// arm starts the timer of the job. The guard must be held.
+func (s *ActionScheduler) arm(job ScheduledJob) {
+	if s.ctx == nil {
+		return
+	}
+
+	if timer, ok := s.timers[job.ID]; ok {
+		timer.Stop()
+	}
+
+	s.timers[job.ID] = time.AfterFunc(time.Until(job.RunAt), func() {
+		s.execute(job.ID)
+	})
+}
+
+func (s *ActionScheduler) execute(id string) {
+	s.guard.Lock()
+
+	job, ok := s.jobs[id]
+	ctx := s.ctx
+	if !ok || ctx == nil {
+		s.guard.Unlock()
+		return
+	}
+
+	s.remove(id)
+	s.running.Add(1)
+	s.guard.Unlock()
+
+	defer s.running.Done()
+
+	if s.expired(job, time.Now()) {
+		s.logger.Warn("scheduled action missed", "job", job.ID, "run_at", job.RunAt)
+		s.notifier.SendMessage(
+			"⚠️ Scheduled action <b>%s</b> for <b>%s</b> missed, it was due at %s",
+			html.EscapeString(job.Action),
+			job.Ticker,
+			job.RunAt.Format(time.DateTime),
+		)
+		return
+	}
+
+	s.logger.Info("running scheduled action", "job", job.ID)
+
+	if err := s.executors[job.Type](ctx, job); err != nil {
+		s.logger.Error("scheduled action failed", "job", job.ID, "error", err)
+		s.notifier.SendMessage(
+			"❌ Scheduled action <b>%s</b> for <b>%s</b> failed: %s",
+			html.EscapeString(job.Action),
+			job.Ticker,
+			html.EscapeString(err.Error()),
+		)
+	}
+}
+
+// remove deletes the job and persists the state. The guard must be held.
+func (s *ActionScheduler) remove(id string) {
+	if timer, ok := s.timers[id]; ok {
+		timer.Stop()
+		delete(s.timers, id)
+	}
+
+	delete(s.jobs, id)
+
+	s.persist()
+}
+
+func (s *ActionScheduler) expired(job ScheduledJob, now time.Time) bool {
+	return now.After(job.RunAt.Add(s.config.GracePeriod))
+}
+
+func (s *ActionScheduler) sortedJobs() []ScheduledJob {
+	jobs := make([]ScheduledJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+
+	slices.SortFunc(jobs, func(a, b ScheduledJob) int {
+		if c := a.RunAt.Compare(b.RunAt); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return jobs
+}
+
+func (s *ActionScheduler) load() error {
+	data, err := os.ReadFile(s.config.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("read scheduled actions: %w", err)
+	}
+
+	var jobs []ScheduledJob
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return fmt.Errorf("decode scheduled actions: %w", err)
+	}
+
+	for _, job := range jobs {
+		if _, ok := s.executors[job.Type]; !ok {
+			s.logger.Warn("dropping scheduled action of unknown type", "job", job.ID, "type", job.Type)
+			continue
+		}
+
+		s.jobs[job.ID] = job
+	}
+
+	return nil
+}
+
+// persist saves the pending jobs in the background, a snapshot older than the saved one is skipped.
+// The guard must be held.
+func (s *ActionScheduler) persist() {
+	s.version++
+	version, jobs := s.version, s.sortedJobs()
+
+	s.saving.Add(1)
+	go func() {
+		defer s.saving.Done()
+
+		s.saveGuard.Lock()
+		defer s.saveGuard.Unlock()
+
+		if version <= s.saved {
+			return
+		}
+
+		if err := s.save(jobs); err != nil {
+			s.logger.Error("failed to save scheduled actions", "error", err)
+			return
+		}
+
+		s.saved = version
+	}()
+}
+
+// save writes the jobs to the state file atomically
+func (s *ActionScheduler) save(jobs []ScheduledJob) error {
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.config.StateFile), 0o755); err != nil {
+		return err
+	}
+
+	tmp := s.config.StateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.config.StateFile)
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/tickers"
+)
+
+type testNotifier struct{}
+
+func (testNotifier) SendMessage(string, ...interface{}) {}
+
+// recordingNotifier delivers the formatted messages
+type recordingNotifier chan string
+
+func (n recordingNotifier) SendMessage(format string, args ...interface{}) {
+	n <- fmt.Sprintf(format, args...)
+}
+
+func newTestActionScheduler(
+	t *testing.T,
+	stateFile string,
+	executed chan<- ScheduledJob,
+) *ActionScheduler {
+	t.Helper()
+
+	executor := func(_ context.Context, job ScheduledJob) error {
+		executed <- job
+		return nil
+	}
+
+	scheduler, err := NewActionScheduler(
+		config.ActionScheduler{
+			StateFile:   stateFile,
+			GracePeriod: time.Minute,
+			Actions: []config.ScheduledAction{
+				{Name: "reminder", Type: config.ScheduledActionReminder, Offset: -time.Hour},
+				{Name: "open", Type: config.ScheduledActionOpenPosition, Offset: -30 * time.Second},
+			},
+		},
+		map[string]ActionExecutor{
+			config.ScheduledActionReminder:     executor,
+			config.ScheduledActionOpenPosition: executor,
+		},
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		testNotifier{},
+	)
+	if err != nil {
+		t.Fatalf("NewActionScheduler() error = %v", err)
+	}
+
+	return scheduler
+}
+
+func listingEvent(startsAt time.Time) entity.NewsEvent {
+	return entity.NewsEvent{
+		Title:   "라이브피어(LPT) 신규 거래지원 안내",
+		Details: &entity.NoticeDetails{TradingStartsAt: startsAt},
+	}
+}
+
+func TestActionScheduler_ScheduleAndPersist(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state", "jobs.json")
+	scheduler := newTestActionScheduler(t, stateFile, make(chan ScheduledJob, 1))
+
+	symbols := []tickers.Symbol{
+		{Ticker: "LPT", Sources: []string{tickers.SourceUpbit, tickers.SourceVenue}},
+		{Ticker: "POKT", Sources: []string{tickers.SourceUpbit}},
+	}
+
+	startsAt := time.Now().Add(2 * time.Hour)
+	scheduled := scheduler.Schedule(listingEvent(startsAt), symbols)
+
+	// Reminders for both, the position only for the tradable symbol
+	if len(scheduled) != 3 {
+		t.Fatalf("Schedule() scheduled %d jobs, want 3: %v", len(scheduled), scheduled)
+	}
+
+	if again := scheduler.Schedule(listingEvent(startsAt), symbols); len(again) != 0 {
+		t.Errorf("Schedule() of the same event scheduled %d jobs, want 0", len(again))
+	}
+
+	if jobs := scheduler.Schedule(listingEvent(time.Time{}), symbols); jobs != nil {
+		t.Errorf("Schedule() without trading start = %v, want nil", jobs)
+	}
+
+	scheduler.saving.Wait()
+
+	restored := newTestActionScheduler(t, stateFile, make(chan ScheduledJob, 1))
+	pending := restored.Pending()
+	if len(pending) != 3 {
+		t.Fatalf("Pending() after restart = %d jobs, want 3", len(pending))
+	}
+
+	if pending[0].Action != "reminder" || !pending[0].RunAt.Equal(startsAt.Add(-time.Hour)) {
+		t.Errorf("Pending()[0] = %+v, want the earliest reminder", pending[0])
+	}
+
+	if !restored.Cancel(pending[0].ID) || restored.Cancel(pending[0].ID) {
+		t.Errorf("Cancel() should remove the job exactly once")
+	}
+}
+
+func TestActionScheduler_OnePositionPerEvent(t *testing.T) {
+	scheduler := newTestActionScheduler(t, filepath.Join(t.TempDir(), "jobs.json"), make(chan ScheduledJob, 1))
+
+	symbols := []tickers.Symbol{
+		{Ticker: "POKT", Sources: []string{tickers.SourceUpbit}},
+		{Ticker: "LPT", Sources: []string{tickers.SourceUpbit, tickers.SourceVenue}},
+		{Ticker: "ARB", Sources: []string{tickers.SourceUpbit, tickers.SourceVenue}},
+	}
+
+	positions := []string{}
+	for _, job := range scheduler.Schedule(listingEvent(time.Now().Add(2*time.Hour)), symbols) {
+		if job.Type == config.ScheduledActionOpenPosition {
+			positions = append(positions, job.Ticker)
+		}
+	}
+
+	if len(positions) != 1 || positions[0] != "LPT" {
+		t.Errorf("scheduled positions for %v, want only the first tradable LPT", positions)
+	}
+}
+
+func TestActionScheduler_Run(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "jobs.json")
+	executed := make(chan ScheduledJob, 1)
+	scheduler := newTestActionScheduler(t, stateFile, executed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	// The position is due right away, the reminder was due an hour ago and is too late
+	symbols := []tickers.Symbol{{Ticker: "LPT", Sources: []string{tickers.SourceVenue}}}
+	scheduled := scheduler.Schedule(listingEvent(time.Now().Add(30*time.Second)), symbols)
+	if len(scheduled) != 1 {
+		t.Fatalf("Schedule() scheduled %d jobs, want 1", len(scheduled))
+	}
+
+	select {
+	case job := <-executed:
+		if job.Ticker != "LPT" || job.Type != config.ScheduledActionOpenPosition {
+			t.Errorf("executed job = %+v, want LPT open position", job)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduled job was not executed")
+	}
+
+	cancel()
+	<-done
+
+	if pending := scheduler.Pending(); len(pending) != 0 {
+		t.Errorf("Pending() after execution = %v, want none", pending)
+	}
+}
+
+func TestActionScheduler_EscapesFailure(t *testing.T) {
+	scheduler := newTestActionScheduler(t, filepath.Join(t.TempDir(), "jobs.json"), make(chan ScheduledJob, 1))
+
+	messages := make(recordingNotifier, 1)
+	scheduler.notifier = messages
+	scheduler.executors[config.ScheduledActionOpenPosition] = func(context.Context, ScheduledJob) error {
+		return errors.New(`order rejected: <error code="BALANCE_NOT_ENOUGH"> & retried`)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go scheduler.Run(ctx)
+
+	symbols := []tickers.Symbol{{Ticker: "LPT", Sources: []string{tickers.SourceVenue}}}
+	scheduler.Schedule(listingEvent(time.Now().Add(30*time.Second)), symbols)
+
+	select {
+	case message := <-messages:
+		if !strings.Contains(message, "&lt;error code=&#34;BALANCE_NOT_ENOUGH&#34;&gt; &amp; retried") {
+			t.Errorf("failure message = %q, want the error escaped", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("failure was not notified")
+	}
+}
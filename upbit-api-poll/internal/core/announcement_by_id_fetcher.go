@@ -9,19 +9,30 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/capture"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/messages"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/notice"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/container"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const announcementFetcherChanSize = 8
 
 type AnnouncementByIDFetcher struct {
 	poller *httptools.ProxyRotatingPoller
+	// endpoint is polled with the IDs of the probe
+	endpoint    httptools.URLTemplate
+	cacheBuster httptools.CacheBuster
 
 	newsGuard     sync.Mutex
 	lastNewsTitle string
-	nextNewsID    int
+	probe         *idProbe
+	ledger        *noticeLedger
+
+	recentResponses *container.RingTS[httptools.Response]
 
 	alreadyStreaming atomic.Bool
 
@@ -29,12 +40,26 @@ type AnnouncementByIDFetcher struct {
 }
 
 func NewAnnouncementByIDFetcher(deps di.Container) (*AnnouncementByIDFetcher, error) {
-	fetcher := &AnnouncementByIDFetcher{deps: deps}
+	cacheBuster, err := httptools.NewCacheBuster(deps.Config.UpbitAPI.CacheBusting.AnnouncementByID)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher := &AnnouncementByIDFetcher{
+		endpoint:        deps.Config.UpbitAPI.AnnouncementByIDURL(),
+		cacheBuster:     cacheBuster,
+		probe:           newIDProbe(0, deps.Config.UpbitAPI.LookAhead),
+		ledger:          newNoticeLedger(),
+		recentResponses: container.NewRingTS[httptools.Response](recentResponsesCapacity),
+		deps:            deps,
+	}
 
 	if err := fetcher.initLatestNewsTitleAndNextNewsID(context.Background()); err != nil {
 		return nil, err
 	}
 
+	fetcher.ledger.rebase(fetcher.probe.cursor)
+
 	if err := fetcher.initPoller(); err != nil {
 		return nil, err
 	}
@@ -42,16 +67,67 @@ func NewAnnouncementByIDFetcher(deps di.Container) (*AnnouncementByIDFetcher, er
 	return fetcher, nil
 }
 
-func (f *AnnouncementByIDFetcher) StreamNewAnnouncementTitles(
+// Poller returns the poller of the fetcher for runtime control
+func (f *AnnouncementByIDFetcher) Poller() *httptools.ProxyRotatingPoller {
+	return f.poller
+}
+
+// Cursor returns the ID of the next announcement the fetcher waits for
+func (f *AnnouncementByIDFetcher) Cursor() int {
+	f.newsGuard.Lock()
+	defer f.newsGuard.Unlock()
+
+	return f.probe.cursor
+}
+
+// SetCursor makes the fetcher wait for the announcement with the ID.
+// The last seen title is forgotten, so the announcement found at the ID is emitted even if it was seen before.
+func (f *AnnouncementByIDFetcher) SetCursor(id int) {
+	f.newsGuard.Lock()
+	defer f.newsGuard.Unlock()
+
+	f.probe.reset(id)
+	f.ledger.rebase(id)
+	f.lastNewsTitle = ""
+	f.poller.SetURLs(f.probe.urls(f.endpoint, time.Now())...)
+}
+
+// Reconcile moves the cursor after the listed notices if it's behind them
+// and returns the listed notices which were never emitted
+func (f *AnnouncementByIDFetcher) Reconcile(notices []entity.Notice) Reconciliation {
+	f.newsGuard.Lock()
+	defer f.newsGuard.Unlock()
+
+	reconciliation, title := f.ledger.reconcile(f.probe, notices, time.Now())
+	if reconciliation.Repaired() {
+		f.lastNewsTitle = title
+		f.poller.SetURLs(f.probe.urls(f.endpoint, time.Now())...)
+	}
+
+	return reconciliation
+}
+
+// refreshProbes stops polling the skipped IDs kept for too long
+func (f *AnnouncementByIDFetcher) refreshProbes() {
+	f.newsGuard.Lock()
+	defer f.newsGuard.Unlock()
+
+	f.poller.SetURLs(f.probe.urls(f.endpoint, time.Now())...)
+}
+
+// RecentResponses returns up to n latest polled responses, newest first
+func (f *AnnouncementByIDFetcher) RecentResponses(n int) []httptools.Response {
+	return f.recentResponses.Last(n)
+}
+
+func (f *AnnouncementByIDFetcher) StreamNewAnnouncements(
 	ctx context.Context,
-) (<-chan entity.NewsTitle, error) {
+) (<-chan entity.NewsEvent, error) {
 	if !f.alreadyStreaming.CompareAndSwap(false, true) {
 		return nil, fmt.Errorf("already streaming")
 	}
 
-	newsChan := make(chan entity.NewsTitle, announcementFetcherChanSize)
-
-	f.poller.SetURL(fmt.Sprintf(f.deps.Config.UpbitAPI.AnnouncementByIDEndpoint, f.nextNewsID))
+	newsChan := make(chan entity.NewsEvent, announcementFetcherChanSize)
 
 	responsesChan, err := f.poller.StartPolling(ctx)
 	if err != nil {
@@ -71,64 +147,99 @@ func (f *AnnouncementByIDFetcher) StreamNewAnnouncementTitles(
 
 func (f *AnnouncementByIDFetcher) populateWithNewNews(
 	ctx context.Context,
-	newsChan chan<- entity.NewsTitle,
+	newsChan chan<- entity.NewsEvent,
 	responses <-chan httptools.Response,
 ) error {
+	refresh := time.NewTicker(probeRefreshInterval)
+	defer refresh.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 
+		case <-refresh.C:
+			f.refreshProbes()
+
 		case response := <-responses:
-			if response.StatusCode != http.StatusOK {
-				if response.StatusCode == http.StatusTooManyRequests {
-					f.deps.SendMessage("Something is wrong with poller: too many requests")
-					f.deps.Logger.Error("too many requests", "status", response.StatusCode)
-					return fmt.Errorf("too many requests")
+			found := make([]foundAnnouncement, 0)
+
+			for _, response := range drainResponses(response, responses) {
+				f.recentResponses.Push(response)
+
+				if response.StatusCode != http.StatusOK {
+					if response.StatusCode == http.StatusTooManyRequests {
+						f.deps.SendMessage("Something is wrong with poller: too many requests")
+						f.deps.Logger.Error("too many requests", "status", response.StatusCode)
+						return fmt.Errorf("too many requests")
+					}
+
+					continue
 				}
 
-				continue
-			}
+				f.deps.Captures.Sample(entity.NewsSourceAnnouncementByID, response)
 
-			announcement, ok := func() (entity.SingleAnnouncement, bool) {
-				timer := f.deps.Metrics.StartTimer(
-					MetricUpbitNewsParseDuration,
-					"fetcher",
-					"announcement_by_id",
-				)
-				defer timer.ObserveDuration()
+				span := startParseSpan(response, entity.NewsSourceAnnouncementByID)
+
+				announcement, err := func() (entity.SingleAnnouncement, error) {
+					timer := f.deps.Metrics.StartTimer(
+						MetricUpbitNewsParseDuration,
+						"fetcher",
+						"announcement_by_id",
+					)
+					defer timer.ObserveDuration()
+
+					announcement := entity.SingleAnnouncement{}
+					err := announcement.UnmarshalJSON(response.Body)
 
-				announcement := entity.SingleAnnouncement{}
-				if err := announcement.UnmarshalJSON(response.Body); err != nil {
+					return announcement, err
+				}()
+
+				endSpan(span, err)
+
+				if err != nil {
 					f.deps.Logger.Error(
 						"failed to unmarshal announcement",
 						"error",
 						err,
-						"body",
-						string(response.Body),
+						"capture",
+						f.deps.Captures.Save(capture.ReasonParseFailure, entity.NewsSourceAnnouncementByID, response, err),
 					)
-					return entity.SingleAnnouncement{}, false
+					continue
 				}
 
-				return announcement, true
-			}()
-
-			if !ok {
-				continue
+				if announcement.Success {
+					found = append(found, foundAnnouncement{
+						announcement: announcement,
+						response:     response,
+						span:         span.SpanContext(),
+					})
+				}
 			}
 
-			if announcement.Success {
-				f.updateAndNotify(newsChan, announcement, response)
+			slices.SortFunc(found, func(a, b foundAnnouncement) int {
+				return a.announcement.Data.ID - b.announcement.Data.ID
+			})
+
+			for _, announcement := range found {
+				f.updateAndNotify(newsChan, announcement)
 			}
 		}
 	}
 }
 
-func (f *AnnouncementByIDFetcher) updateAndNotify(
-	newsChan chan<- entity.NewsTitle,
-	announcement entity.SingleAnnouncement,
-	response httptools.Response,
-) {
+// foundAnnouncement is an announcement found at the probed ID
+type foundAnnouncement struct {
+	announcement entity.SingleAnnouncement
+	response     httptools.Response
+	// span is the span of parsing the announcement
+	span trace.SpanContext
+}
+
+// updateAndNotify emits the announcement if it's new, the found announcements must be passed in ID order
+func (f *AnnouncementByIDFetcher) updateAndNotify(newsChan chan<- entity.NewsEvent, found foundAnnouncement) {
+	announcement, response := found.announcement, found.response
+
 	if !announcement.Success {
 		f.deps.Logger.Error(
 			"updateAndNotify accepts only successful announcements",
@@ -141,6 +252,12 @@ func (f *AnnouncementByIDFetcher) updateAndNotify(
 	f.newsGuard.Lock()
 	defer f.newsGuard.Unlock()
 
+	if !f.probe.found(announcement.Data.ID, response.ReceivedAt) {
+		return
+	}
+
+	f.poller.SetURLs(f.probe.urls(f.endpoint, time.Now())...)
+
 	if f.lastNewsTitle == announcement.Data.Title {
 		f.deps.Logger.Info(
 			"skipping announcement",
@@ -152,11 +269,28 @@ func (f *AnnouncementByIDFetcher) updateAndNotify(
 		return
 	}
 
-	f.nextNewsID = announcement.Data.ID + 1
 	f.lastNewsTitle = announcement.Data.Title
 
-	newsChan <- announcement.Data.Title
-	f.poller.SetURL(fmt.Sprintf(f.deps.Config.UpbitAPI.AnnouncementByIDEndpoint, f.nextNewsID))
+	event := entity.NewsEvent{
+		Title:       announcement.Data.Title,
+		Source:      entity.NewsSourceAnnouncementByID,
+		NoticeID:    announcement.Data.ID,
+		ListedAt:    announcement.Data.ListedAt,
+		ReceivedAt:  response.ReceivedAt,
+		SpanContext: found.span,
+	}
+	event.SpanContext = startNewsTrace(event)
+
+	if announcement.Data.Body != "" {
+		_, span := startEventSpan(context.Background(), event, SpanParseDetails)
+		details := notice.ParseHTML(announcement.Data.Body, announcement.Data.ListedAt)
+		event.Details = &details
+		span.End()
+	}
+
+	newsChan <- event
+	f.ledger.emit(event.NoticeID)
+	f.deps.Captures.Save(capture.ReasonNewNews, entity.NewsSourceAnnouncementByID, response, nil)
 
 	f.deps.Metrics.IncrementCounter(
 		MetricUpbitNewNewsDetectedTotal,
@@ -164,56 +298,33 @@ func (f *AnnouncementByIDFetcher) updateAndNotify(
 		"announcement_by_id",
 	)
 
-	f.deps.SendMessage(
-		fmt.Sprintf(
-			"!!!New announcement !!!\n"+
-				"-----&gt; NEWS INFO &lt;----\n"+
-				"Title: %s\n"+
-				"Category: %s\n"+
-				"\n"+
-				"Listed at: %s\n"+
-				"First listed at: %s\n"+
-				"---&gt; RESPONSE INFO &lt;---\n"+
-				"Client: %s\n"+
-				"Proxy address: %s\n"+
-				"\n"+
-				"Requested at: %s\n"+
-				"Received at: %s\n"+
-				"\n"+
-				"Status: %d\n"+
-				"Headers: %s\n"+
-				"\n"+
-				"----&gt; DELAYS INFO &lt;----\n"+
-				"Between requested_at and received_at: %s\n"+
-				"Between requested_at and listed_at: %s\n"+
-				"Between received_at and listed_at: %s\n",
-			announcement.Data.Title,
-			announcement.Data.Category,
-			announcement.Data.ListedAt.Format("2006-01-02 15:04:05.000"),
-			announcement.Data.FirstListedAt.Format("2006-01-02 15:04:05.000"),
-			response.ClientName,
-			response.ProxyAddr,
-			response.RequestedAt.Format("2006-01-02 15:04:05.000"),
-			response.ReceivedAt.Format("2006-01-02 15:04:05.000"),
-			response.StatusCode,
-			response.Headers,
-			response.RequestedAt.Sub(response.ReceivedAt),
-			response.RequestedAt.Sub(announcement.Data.ListedAt),
-			response.ReceivedAt.Sub(announcement.Data.ListedAt),
-		),
-	)
+	traceNotification(event, messages.TemplateAnnouncement, func() {
+		f.deps.SendTemplatedAlert(messages.TemplateAnnouncement, messages.NewsData{
+			Event:         event,
+			Category:      announcement.Data.Category,
+			FirstListedAt: announcement.Data.FirstListedAt,
+			Response:      response,
+		})
+	})
 }
 
 func (f *AnnouncementByIDFetcher) initLatestNewsTitleAndNextNewsID(ctx context.Context) error {
 	hostIPClient, err := httptools.NewClientHTTP2(httptools.ClientConfig{
-		Logger:  f.deps.Logger,
-		Metrics: &metricsAdapter{prometheus: f.deps.Metrics},
+		Logger:     f.deps.Logger,
+		Metrics:    &metricsAdapter{prometheus: f.deps.Metrics},
+		Headers:    f.deps.Config.ProxyRotatingPoller.Headers,
+		HeaderKind: httptools.HeaderKindAPI,
 	})
 	if err != nil {
 		return err
 	}
 
-	response, err := hostIPClient.Request(ctx, f.deps.Config.UpbitAPI.AnnouncementsEndpoint)
+	endpoint, err := latestNoticesURL(f.deps.Config.UpbitAPI, 20)
+	if err != nil {
+		return err
+	}
+
+	response, err := hostIPClient.Request(ctx, endpoint)
 	if err != nil {
 		return err
 	}
@@ -239,7 +350,7 @@ func (f *AnnouncementByIDFetcher) initLatestNewsTitleAndNextNewsID(ctx context.C
 
 	lastNoticeIndex := len(notices) - 1
 	f.lastNewsTitle = notices[lastNoticeIndex].Title
-	f.nextNewsID = notices[lastNoticeIndex].ID + 1
+	f.probe.cursor = notices[lastNoticeIndex].ID + 1
 
 	timer := time.NewTicker(
 		time.Duration(float64(time.Second) / f.deps.Config.UpbitAPI.AnnouncementByIDSingleIPMaxRPS),
@@ -254,7 +365,7 @@ func (f *AnnouncementByIDFetcher) initLatestNewsTitleAndNextNewsID(ctx context.C
 		case <-timer.C:
 			response, err := hostIPClient.Request(
 				ctx,
-				fmt.Sprintf(f.deps.Config.UpbitAPI.AnnouncementByIDEndpoint, f.nextNewsID),
+				f.cacheBuster.Bust(f.endpoint.With(httptools.VarID, f.probe.cursor).String()),
 			)
 			if err != nil {
 				return err
@@ -270,7 +381,7 @@ func (f *AnnouncementByIDFetcher) initLatestNewsTitleAndNextNewsID(ctx context.C
 					f.deps.Logger.Info(
 						"no more announcements",
 						"next_news_id",
-						f.nextNewsID,
+						f.probe.cursor,
 						"last_news_title",
 						f.lastNewsTitle,
 					)
@@ -286,7 +397,7 @@ func (f *AnnouncementByIDFetcher) initLatestNewsTitleAndNextNewsID(ctx context.C
 				return fmt.Errorf("failed to get announcement: %s", announcement.ErrorMessage)
 			}
 
-			f.nextNewsID = announcement.Data.ID + 1
+			f.probe.cursor = announcement.Data.ID + 1
 			f.lastNewsTitle = announcement.Data.Title
 		}
 	}
@@ -294,7 +405,9 @@ func (f *AnnouncementByIDFetcher) initLatestNewsTitleAndNextNewsID(ctx context.C
 
 func (f *AnnouncementByIDFetcher) initPoller() error {
 	poller, err := httptools.NewProxyRotatingPollerBuilder().
-		WithURL(fmt.Sprintf(f.deps.Config.UpbitAPI.AnnouncementByIDEndpoint, f.nextNewsID)).
+		WithName("announcement_by_id").
+		WithURL(f.endpoint.With(httptools.VarID, f.probe.cursor).String()).
+		WithCacheBuster(f.cacheBuster).
 		WithTargetRPS(f.deps.Config.ProxyRotatingPoller.TargetRPS).
 		WithSingleProxyMaxRPS(f.deps.Config.UpbitAPI.AnnouncementByIDSingleIPMaxRPS).
 		WithProxies(f.deps.Config.ProxyRotatingPoller.Proxies...).
@@ -308,6 +421,8 @@ func (f *AnnouncementByIDFetcher) initPoller() error {
 				ClientRetriesConfig: f.deps.Config.ProxyRotatingPoller.Retries,
 				Metrics:             &metricsAdapter{prometheus: f.deps.Metrics},
 				Logger:              f.deps.Logger,
+				Headers:             f.deps.Config.ProxyRotatingPoller.Headers,
+				HeaderKind:          httptools.HeaderKindAPI,
 			})
 		}).
 		Build()
@@ -315,6 +430,8 @@ func (f *AnnouncementByIDFetcher) initPoller() error {
 		return err
 	}
 
+	poller.SetURLs(f.probe.urls(f.endpoint, time.Now())...)
+
 	f.poller = poller
 
 	return nil
@@ -7,9 +7,12 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/capture"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/messages"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type AnnouncementsFetcher struct {
@@ -37,16 +40,21 @@ func NewAnnouncementsFetcher(deps di.Container) (*AnnouncementsFetcher, error) {
 	return fetcher, nil
 }
 
-func (f *AnnouncementsFetcher) StreamNewNewsTitles(
+// Poller returns the poller of the fetcher for runtime control
+func (f *AnnouncementsFetcher) Poller() *httptools.ProxyRotatingPoller {
+	return f.poller
+}
+
+func (f *AnnouncementsFetcher) StreamNewAnnouncements(
 	ctx context.Context,
-) (<-chan entity.NewsTitle, error) {
+) (<-chan entity.NewsEvent, error) {
 	if !f.alreadyStreaming.CompareAndSwap(false, true) {
 		return nil, fmt.Errorf("already streaming")
 	}
 
 	f.deps.Logger.Info("Starting to stream new announcements")
 
-	announcementsChan := make(chan entity.NewsTitle, announcementFetcherChanSize)
+	announcementsChan := make(chan entity.NewsEvent, announcementFetcherChanSize)
 
 	responsesChan, err := f.poller.StartPolling(ctx)
 	if err != nil {
@@ -66,7 +74,7 @@ func (f *AnnouncementsFetcher) StreamNewNewsTitles(
 
 func (f *AnnouncementsFetcher) populateWithNewAnnouncements(
 	ctx context.Context,
-	announcementsChan chan<- entity.NewsTitle,
+	announcementsChan chan<- entity.NewsEvent,
 	responses <-chan httptools.Response,
 ) error {
 	for {
@@ -85,21 +93,32 @@ func (f *AnnouncementsFetcher) populateWithNewAnnouncements(
 				continue
 			}
 
+			f.deps.Captures.Sample(entity.NewsSourceAnnouncements, response)
+
+			span := startParseSpan(response, entity.NewsSourceAnnouncements)
 			announcement, err := f.parseAnnouncement(response)
+			endSpan(span, err)
 			if err != nil {
-				f.deps.Logger.Error("failed to parse announcement", "error", err)
+				f.deps.Logger.Error(
+					"failed to parse announcement",
+					"error",
+					err,
+					"capture",
+					f.deps.Captures.Save(capture.ReasonParseFailure, entity.NewsSourceAnnouncements, response, err),
+				)
 				continue
 			}
 
-			f.updateAndNotify(announcementsChan, announcement, response)
+			f.updateAndNotify(announcementsChan, announcement, response, span.SpanContext())
 		}
 	}
 }
 
 func (f *AnnouncementsFetcher) updateAndNotify(
-	announcementsChan chan<- entity.NewsTitle,
+	announcementsChan chan<- entity.NewsEvent,
 	announcement entity.Notice,
 	response httptools.Response,
+	span trace.SpanContext,
 ) {
 	f.noticeGuard.Lock()
 	defer f.noticeGuard.Unlock()
@@ -109,57 +128,31 @@ func (f *AnnouncementsFetcher) updateAndNotify(
 	}
 
 	f.lastNotice = announcement
-	announcementsChan <- announcement.Title
+
+	event := entity.NewsEvent{
+		Title:       announcement.Title,
+		Source:      entity.NewsSourceAnnouncements,
+		NoticeID:    announcement.ID,
+		ListedAt:    announcement.ListedAt,
+		ReceivedAt:  response.ReceivedAt,
+		SpanContext: span,
+	}
+	event.SpanContext = startNewsTrace(event)
+	announcementsChan <- event
+	f.deps.Captures.Save(capture.ReasonNewNews, entity.NewsSourceAnnouncements, response, nil)
 	f.deps.Metrics.IncrementCounter(MetricUpbitNewNewsDetectedTotal, "fetcher", "announcements")
 
 	f.deps.Logger.Info("New announcement", "notice", announcement)
 
-	f.deps.SendMessage(
-		fmt.Sprintf(
-			"New announcement\n"+
-				"\n"+
-				"* NEWS INFO\n"+
-				"ID: %d\n"+
-				"Title: %s\n"+
-				"Category: %s\n"+
-				"\n"+
-				"Listed at: %s\n"+
-				"First listed at: %s\n"+
-				"\n"+
-				"Link: %s\n"+
-				"\n"+
-				"\n"+
-				"* RESPONSE INFO\n"+
-				"Client: %s\n"+
-				"Proxy address: %s\n"+
-				"\n"+
-				"Requested at: %s\n"+
-				"Received at: %s\n"+
-				"\n"+
-				"Status: %d\n"+
-				"Headers: %s\n"+
-				"\n"+
-				"\n"+
-				"* DELAYS INFO\n"+
-				"Between received_at and listed_at: %s\n"+
-				"\n"+
-				"Between requested_at and received_at: %s\n",
-			announcement.ID,
-			announcement.Title,
-			announcement.Category,
-			announcement.ListedAt,
-			announcement.FirstListedAt,
-			fmt.Sprintf(f.deps.Config.UpbitAPI.NoticeByIDEndpoint, announcement.ID),
-			response.ClientName,
-			response.ProxyAddr,
-			response.RequestedAt.Format("2006-01-02 15:04:05.000"),
-			response.ReceivedAt.Format("2006-01-02 15:04:05.000"),
-			response.StatusCode,
-			response.Headers,
-			response.ReceivedAt.Sub(announcement.ListedAt),
-			response.ReceivedAt.Sub(response.RequestedAt),
-		),
-	)
+	traceNotification(event, messages.TemplateAnnouncement, func() {
+		f.deps.SendTemplatedAlert(messages.TemplateAnnouncement, messages.NewsData{
+			Event:         event,
+			Category:      announcement.Category,
+			FirstListedAt: announcement.FirstListedAt,
+			Link:          noticeLink(f.deps.Config.UpbitAPI, announcement.ID),
+			Response:      response,
+		})
+	})
 }
 
 func (f *AnnouncementsFetcher) parseAnnouncement(response httptools.Response) (entity.Notice, error) {
@@ -188,17 +181,21 @@ func (f *AnnouncementsFetcher) parseAnnouncement(response httptools.Response) (e
 
 func (f *AnnouncementsFetcher) initLatestNotice(ctx context.Context) error {
 	hostIPClient, err := httptools.NewClientHTTP2(httptools.ClientConfig{
-		Logger:  f.deps.Logger,
-		Metrics: &metricsAdapter{prometheus: f.deps.Metrics},
+		Logger:     f.deps.Logger,
+		Metrics:    &metricsAdapter{prometheus: f.deps.Metrics},
+		Headers:    f.deps.Config.ProxyRotatingPoller.Headers,
+		HeaderKind: httptools.HeaderKindAPI,
 	})
 	if err != nil {
 		return err
 	}
 
-	response, err := hostIPClient.Request(
-		ctx,
-		fmt.Sprintf(f.deps.Config.UpbitAPI.AnnouncementsEndpoint, 1),
-	)
+	endpoint, err := latestNoticesURL(f.deps.Config.UpbitAPI, 1)
+	if err != nil {
+		return err
+	}
+
+	response, err := hostIPClient.Request(ctx, endpoint)
 	if err != nil {
 		return err
 	}
@@ -216,8 +213,15 @@ func (f *AnnouncementsFetcher) initLatestNotice(ctx context.Context) error {
 }
 
 func (f *AnnouncementsFetcher) initPoller() error {
+	cacheBuster, err := httptools.NewCacheBuster(f.deps.Config.UpbitAPI.CacheBusting.Announcements)
+	if err != nil {
+		return err
+	}
+
 	poller, err := httptools.NewProxyRotatingPollerBuilder().
-		WithURL(fmt.Sprintf(f.deps.Config.UpbitAPI.AnnouncementsEndpoint, 1)).
+		WithName("announcements").
+		WithURL(f.deps.Config.UpbitAPI.AnnouncementsURL().With(httptools.VarPerPage, 1).String()).
+		WithCacheBuster(cacheBuster).
 		WithTargetRPS(f.deps.Config.ProxyRotatingPoller.TargetRPS).
 		WithSingleProxyMaxRPS(f.deps.Config.UpbitAPI.AnnouncementsSingleIPMaxRPS).
 		WithProxies(f.deps.Config.ProxyRotatingPoller.Proxies...).
@@ -231,6 +235,8 @@ func (f *AnnouncementsFetcher) initPoller() error {
 				ClientRetriesConfig: f.deps.Config.ProxyRotatingPoller.Retries,
 				Metrics:             &metricsAdapter{prometheus: f.deps.Metrics},
 				Logger:              f.deps.Logger,
+				Headers:             f.deps.Config.ProxyRotatingPoller.Headers,
+				HeaderKind:          httptools.HeaderKindAPI,
 			})
 		}).
 		Build()
@@ -0,0 +1,25 @@
+package core
+
+import (
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+// latestNoticesURL returns the URL of the count latest notices of the announcements list,
+// busted with the strategy of the announcements endpoint
+func latestNoticesURL(cfg config.UpbitAPI, count int) (string, error) {
+	cacheBuster, err := httptools.NewCacheBuster(cfg.CacheBusting.Announcements)
+	if err != nil {
+		return "", err
+	}
+
+	return cacheBuster.Bust(cfg.AnnouncementsURL().With(httptools.VarPerPage, count).String()), nil
+}
+
+// noticeLink returns the link to the notice for the messages
+func noticeLink(cfg config.UpbitAPI, id int) string {
+	return cfg.NoticeByIDURL().
+		With(httptools.VarID, id).
+		With(httptools.VarBuster, "").
+		String()
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// probeRefreshInterval is how often the polled IDs are refreshed to drop the expired skipped IDs
+const probeRefreshInterval = time.Minute
+
+// skippedIDRetention is how long an ID skipped by a later found ID is still probed.
+// Upbit can publish several notices at once or unhide a notice after the next one.
+const skippedIDRetention = 10 * time.Minute
+
+// idProbe tracks the IDs polled by the by-ID fetchers: the cursor, the next IDs after it
+// and the IDs skipped when a later one was found. It's not safe for concurrent use.
+type idProbe struct {
+	cursor    int
+	lookAhead int
+	// skipped are the IDs before the cursor which were not found, with the time they were skipped
+	skipped map[int]time.Time
+}
+
+func newIDProbe(cursor, lookAhead int) *idProbe {
+	return &idProbe{
+		cursor:    cursor,
+		lookAhead: lookAhead,
+		skipped:   make(map[int]time.Time),
+	}
+}
+
+// reset moves the cursor to the ID and forgets the skipped IDs
+func (p *idProbe) reset(cursor int) {
+	p.cursor = cursor
+	clear(p.skipped)
+}
+
+// advance moves the cursor forward to the ID, the skipped IDs are kept
+func (p *idProbe) advance(cursor int) {
+	p.cursor = max(p.cursor, cursor)
+}
+
+// found records a notice found at the ID and reports whether it's new: at or after the cursor,
+// or a skipped one. The cursor is moved after the ID, the IDs in between are skipped.
+func (p *idProbe) found(id int, at time.Time) bool {
+	if id >= p.cursor {
+		for skipped := p.cursor; skipped < id; skipped++ {
+			p.skipped[skipped] = at
+		}
+
+		p.cursor = id + 1
+
+		return true
+	}
+
+	if _, ok := p.skipped[id]; ok {
+		delete(p.skipped, id)
+		return true
+	}
+
+	return false
+}
+
+// ids returns the IDs to poll in turn. Every other request polls the cursor,
+// the IDs after it and the skipped ones are polled in between.
+func (p *idProbe) ids(at time.Time) []int {
+	maps.DeleteFunc(p.skipped, func(_ int, skippedAt time.Time) bool {
+		return at.Sub(skippedAt) > skippedIDRetention
+	})
+
+	others := make([]int, 0, p.lookAhead+len(p.skipped))
+	for offset := 1; offset <= p.lookAhead; offset++ {
+		others = append(others, p.cursor+offset)
+	}
+
+	others = append(others, slices.Sorted(maps.Keys(p.skipped))...)
+
+	if len(others) == 0 {
+		return []int{p.cursor}
+	}
+
+	ids := make([]int, 0, 2*len(others))
+	for _, id := range others {
+		ids = append(ids, p.cursor, id)
+	}
+
+	return ids
+}
+
+// urls expands the endpoint with the IDs to poll
+func (p *idProbe) urls(endpoint httptools.URLTemplate, at time.Time) []string {
+	ids := p.ids(at)
+
+	urls := make([]string, 0, len(ids))
+	for _, id := range ids {
+		urls = append(urls, endpoint.With(httptools.VarID, id).String())
+	}
+
+	return urls
+}
+
+// probedID returns the ID of the notice from the polled URL of the endpoint
+func probedID(endpoint httptools.URLTemplate, url string) (int, error) {
+	value, err := endpoint.Extract(url, httptools.VarID)
+	if err != nil {
+		return 0, fmt.Errorf("parse notice ID: %w", err)
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("parse notice ID from %s: %w", url, err)
+	}
+
+	return id, nil
+}
+
+// foundNotice is a notice found at the probed ID
+type foundNotice struct {
+	id       int
+	title    string
+	response httptools.Response
+	// span is the span of parsing the notice
+	span trace.SpanContext
+}
+
+// drainResponses returns the response with the responses already received after it,
+// so the notices found in them can be emitted in ID order
+func drainResponses(first httptools.Response, responses <-chan httptools.Response) []httptools.Response {
+	batch := []httptools.Response{first}
+
+	for {
+		select {
+		case response := <-responses:
+			batch = append(batch, response)
+		default:
+			return batch
+		}
+	}
+}
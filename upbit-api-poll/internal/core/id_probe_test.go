@@ -0,0 +1,78 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+func TestIDProbe(t *testing.T) {
+	now := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	probe := newIDProbe(100, 2)
+
+	if ids := probe.ids(now); !slices.Equal(ids, []int{100, 101, 100, 102}) {
+		t.Fatalf("ids() = %v, expected the cursor in every other request", ids)
+	}
+
+	// 100 is hidden, 101 and 102 are published at once
+	for _, id := range []int{101, 102} {
+		if !probe.found(id, now) {
+			t.Errorf("found(%d) = false, expected a new ID", id)
+		}
+	}
+
+	if probe.cursor != 103 {
+		t.Errorf("cursor = %d, expected the ID after the highest found one", probe.cursor)
+	}
+
+	if probe.found(101, now) {
+		t.Error("found(101) = true, expected an ID found before to be old")
+	}
+
+	if ids := probe.ids(now); !slices.Equal(ids, []int{103, 104, 103, 105, 103, 100}) {
+		t.Errorf("ids() = %v, expected the skipped ID to be probed", ids)
+	}
+
+	if !probe.found(100, now) || probe.found(100, now) {
+		t.Error("a skipped ID should be new only once")
+	}
+
+	probe.found(106, now)
+
+	if ids := probe.ids(now.Add(skippedIDRetention + time.Second)); !slices.Equal(ids, []int{107, 108, 107, 109}) {
+		t.Errorf("ids() = %v, expected the expired skipped IDs to be dropped", ids)
+	}
+
+	probe.reset(50)
+
+	if ids := newIDProbe(50, 0).ids(now); !slices.Equal(ids, []int{50}) || probe.cursor != 50 {
+		t.Errorf("ids() = %v, expected only the cursor without the look-ahead", ids)
+	}
+}
+
+func TestProbedID(t *testing.T) {
+	endpoint := httptools.NewURLTemplate("https://upbit.com/service_center/notice?id={id}", httptools.VarID)
+	cacheBuster, _ := httptools.NewCacheBuster(httptools.CacheBustingRandom)
+
+	probe := newIDProbe(5000, 1)
+
+	for _, url := range probe.urls(endpoint, time.Now()) {
+		url = cacheBuster.Bust(url)
+
+		id, err := probedID(endpoint, url)
+		if err != nil {
+			t.Fatalf("probedID(%s) error = %v", url, err)
+		}
+
+		if id != 5000 && id != 5001 {
+			t.Errorf("probedID(%s) = %d", url, id)
+		}
+	}
+
+	if _, err := probedID(endpoint, "https://upbit.com/service_center/notice"); err == nil {
+		t.Error("probedID() should fail without the ID")
+	}
+}
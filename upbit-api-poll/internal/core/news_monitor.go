@@ -2,28 +2,59 @@ package core
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/messages"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/service"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/tickers"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/container"
 	gate "github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/gate"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-type NewsMonitor struct {
-	newsChan <-chan entity.NewsTitle
-	metrics  *service.PrometheusService
+// templatedAlerter sends alerts rendered from message templates
+type templatedAlerter interface {
+	SendTemplatedAlert(template string, data any)
 }
 
-var koreanListingPatterns = []string{
-	"신규 거래지원 안내",
-	"디지털 자산 추가",
-	"상장 안내",
+// recentEventsCapacity is the number of received events kept for operators
+const recentEventsCapacity = 50
+
+// detailsWaitTimeout bounds the wait for the details still parsed by the source, the event is
+// scheduled without them after it
+const detailsWaitTimeout = time.Second
+
+const MetricDetectionLatency = "upbit_detection_latency_seconds"
+
+// DetectionLatencyBuckets are the histogram buckets of the detection stages in seconds
+var DetectionLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// orderOpener opens a position of the size for the ticker on the venue
+type orderOpener func(ticker string, size TradeSize) (gate.OrderTimings, error)
+
+type NewsMonitor struct {
+	newsChan      <-chan entity.NewsEvent
+	registry      *tickers.Registry
+	dictionary    *tickers.Dictionary
+	scheduler     *ActionScheduler
+	tradingSwitch *TradingSwitch
+	patterns      atomic.Pointer[[]string]
+	recentEvents  *container.RingTS[entity.NewsEvent]
+	alerter       templatedAlerter
+	metrics       *service.PrometheusService
+	logger        *slog.Logger
+	openOrder     orderOpener
 }
 
-func containsKoreanListingPattern(news string) bool {
-	for _, pattern := range koreanListingPatterns {
+func containsListingPattern(news string, patterns []string) bool {
+	for _, pattern := range patterns {
 		if strings.Contains(news, pattern) {
 			return true
 		}
@@ -32,41 +63,276 @@ func containsKoreanListingPattern(news string) bool {
 }
 
 func NewNewsMonitor(
-	newsChan <-chan entity.NewsTitle,
-	metrics *service.PrometheusService,
+	newsChan <-chan entity.NewsEvent,
+	registry *tickers.Registry,
+	dictionary *tickers.Dictionary,
+	scheduler *ActionScheduler,
+	tradingSwitch *TradingSwitch,
+	deps di.Container,
 ) *NewsMonitor {
-	return &NewsMonitor{
-		newsChan: newsChan,
-		metrics:  metrics,
+	m := &NewsMonitor{
+		newsChan:      newsChan,
+		registry:      registry,
+		dictionary:    dictionary,
+		scheduler:     scheduler,
+		tradingSwitch: tradingSwitch,
+		recentEvents:  container.NewRingTS[entity.NewsEvent](recentEventsCapacity),
+		alerter:       deps,
+		metrics:       deps.Metrics,
+		logger:        deps.Logger,
+		openOrder: func(ticker string, size TradeSize) (gate.OrderTimings, error) {
+			_, timings, err := gate.OpenFuturesOrder(
+				deps.Metrics,
+				gate.Credentials{Key: deps.Config.Gate.APIKey, Secret: deps.Config.Gate.APISecret},
+				ticker,
+				size.USDTAmount,
+				size.Leverage,
+			)
+			return timings, err
+		},
 	}
+	m.SetListingPatterns(deps.Config.Classifier.ListingPatterns)
+
+	return m
+}
+
+// SetListingPatterns replaces the patterns of listing news titles, applied from the next event
+func (m *NewsMonitor) SetListingPatterns(patterns []string) {
+	patterns = slices.Clone(patterns)
+	m.patterns.Store(&patterns)
+}
+
+// RecentEvents returns up to n latest received events, newest first
+func (m *NewsMonitor) RecentEvents(n int) []entity.NewsEvent {
+	return m.recentEvents.Last(n)
 }
 
 func (m *NewsMonitor) StartMonitoring(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Println("Background monitoring stopped")
+			m.logger.Info("background monitoring stopped")
 			return
-		case news := <-m.newsChan:
-			fmt.Printf("Received news: %s\n", news)
-
-			if strings.Contains(news, "Market Support for") ||
-				containsKoreanListingPattern(news) {
-				fmt.Printf("!!! FOUND LISTING NEWS: %s !!!\n", news)
-
-				extractedTickers := tickers.ExtractKoreanTickers(news)
-				if len(extractedTickers) > 0 {
-					ticker := extractedTickers[0]
-					go func(ticker string) {
-						_, err := gate.OpenFuturesOrder(m.metrics, ticker, 10.0, "20")
-						if err != nil {
-							fmt.Printf("Failed to open order for %s: %v\n", ticker, err)
-						} else {
-							fmt.Printf("Order opened for %s\n", ticker)
-						}
-					}(ticker)
-				}
-			}
+		case event := <-m.newsChan:
+			m.handle(ctx, event)
+		}
+	}
+}
+
+// handle classifies the event and, for a listing, schedules the actions, alerts and opens the position.
+// Without a scheduled open action the position is opened before the details are awaited,
+// with it only if the scheduler did not take the position over.
+func (m *NewsMonitor) handle(ctx context.Context, event entity.NewsEvent) {
+	news := event.Title
+	m.logger.Info("received news", "source", event.Source, "title", news)
+	m.recentEvents.Push(event)
+
+	_, span := startEventSpan(ctx, event, SpanClassify)
+
+	listing := containsListingPattern(news, *m.patterns.Load())
+	span.SetAttributes(attribute.Bool("listing", listing))
+
+	if !listing {
+		event.ClassifiedAt = time.Now()
+		span.End()
+		m.observeLatencies(event, entity.StageReceipt, entity.StageClassification)
+
+		return
+	}
+
+	m.logger.Info("found listing news", "source", event.Source, "title", news)
+
+	matches := tickers.ExtractKoreanTickersWithDictionary(news, m.dictionary)
+	known, unknown := m.registry.Validate(tickers.MatchedTickers(matches, false))
+	// Tickers matched by approximate names are only alerted, nothing is scheduled or traded on them
+	certain := certainSymbols(known, matches)
+	event.ClassifiedAt = time.Now()
+	span.SetAttributes(
+		attribute.StringSlice("tickers", symbolTickers(known)),
+		attribute.StringSlice("certain_tickers", symbolTickers(certain)),
+		attribute.StringSlice("unknown_tickers", unknown),
+	)
+	span.End()
+	event.SpanContext = span.SpanContext()
+	m.observeLatencies(event, entity.StageReceipt, entity.StageClassification)
+
+	tradable := slices.IndexFunc(certain, tickers.Symbol.Tradable)
+
+	// Without a scheduled open action the order doesn't depend on the details, it's sent before them
+	schedulesPositions := m.scheduler.SchedulesPositions()
+	if tradable >= 0 && !schedulesPositions {
+		m.openPosition(ctx, event, certain[tradable].Ticker)
+	}
+
+	// The trading start time is in the details, which may still be parsed by the source
+	detailsCtx, cancel := context.WithTimeout(ctx, detailsWaitTimeout)
+	event = event.AwaitDetails(detailsCtx)
+	cancel()
+
+	scheduled := m.scheduler.Schedule(event, certain)
+	traceNotification(event, messages.TemplateListing, func() {
+		m.notifyListing(event, known, certain, unknown, scheduled)
+	})
+
+	if tradable < 0 || !schedulesPositions || positionScheduled(scheduled, certain[tradable].Ticker) {
+		return
+	}
+
+	m.openPosition(ctx, event, certain[tradable].Ticker)
+}
+
+// openPosition opens the position of the ticker in the background unless trading is switched off
+func (m *NewsMonitor) openPosition(ctx context.Context, event entity.NewsEvent, ticker string) {
+	if !m.tradingSwitch.Enabled() {
+		m.logger.Info("trading is disabled, no order opened", "ticker", ticker)
+		return
+	}
+
+	size := m.tradingSwitch.Size()
+	go func() {
+		_, span := startEventSpan(
+			ctx,
+			event,
+			SpanOrder,
+			attribute.String("venue", gate.Venue),
+			attribute.String("ticker", ticker),
+		)
+
+		timings, err := m.openOrder(ticker, size)
+		endSpan(span, err)
+
+		event.Venue = gate.Venue
+		event.OrderSentAt = timings.SentAt
+		event.OrderAckedAt = timings.AckedAt
+		event.SpanContext = span.SpanContext()
+		m.observeLatencies(event, entity.StageOrderSend, entity.StageOrderAck, entity.StageTotal)
+		traceNotification(event, messages.TemplateOrder, func() {
+			m.notifyOrder(event, ticker, err)
+		})
+
+		if err != nil {
+			m.logger.Error("failed to open order", "ticker", ticker, "error", err)
+		} else {
+			m.logger.Info("order opened", "ticker", ticker)
+		}
+	}()
+}
+
+// positionScheduled reports whether the scheduler will open the position of the ticker
+func positionScheduled(scheduled []ScheduledJob, ticker string) bool {
+	return slices.ContainsFunc(scheduled, func(job ScheduledJob) bool {
+		return job.Type == config.ScheduledActionOpenPosition && job.Ticker == ticker
+	})
+}
+
+// certainSymbols returns the symbols matched certainly enough to trade on
+func certainSymbols(symbols []tickers.Symbol, matches []tickers.Match) []tickers.Symbol {
+	certain := tickers.MatchedTickers(matches, true)
+
+	return slices.DeleteFunc(slices.Clone(symbols), func(symbol tickers.Symbol) bool {
+		return !slices.ContainsFunc(certain, func(ticker string) bool {
+			return tickers.Canonicalize(ticker) == symbol.Ticker
+		})
+	})
+}
+
+func symbolTickers(symbols []tickers.Symbol) []string {
+	names := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		names = append(names, symbol.Ticker)
+	}
+
+	return names
+}
+
+// observeLatencies exports the latencies of the stages of the event
+func (m *NewsMonitor) observeLatencies(event entity.NewsEvent, stages ...string) {
+	venue := event.Venue
+	if venue == "" {
+		venue = "none"
+	}
+
+	// total falls back to the receipt without the publication time, the label tells the two apart
+	listedAt := "known"
+	if event.ListedAt.IsZero() {
+		listedAt = "unknown"
+	}
+
+	for _, latency := range event.Latencies() {
+		if !slices.Contains(stages, latency.Stage) {
+			continue
 		}
+
+		m.metrics.ObserveHistogram(
+			MetricDetectionLatency,
+			latency.Duration.Seconds(),
+			"stage",
+			latency.Stage,
+			"source",
+			event.Source,
+			"venue",
+			venue,
+			"listed_at",
+			listedAt,
+		)
+	}
+}
+
+func (m *NewsMonitor) notifyOrder(event entity.NewsEvent, ticker string, err error) {
+	data := messages.OrderData{
+		Event:     event,
+		Ticker:    ticker,
+		Latencies: event.Latencies(),
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+
+	m.alerter.SendTemplatedAlert(messages.TemplateOrder, data)
+}
+
+func (m *NewsMonitor) notifyListing(
+	event entity.NewsEvent,
+	known []tickers.Symbol,
+	certain []tickers.Symbol,
+	unknown []string,
+	scheduled []ScheduledJob,
+) {
+	for range unknown {
+		m.metrics.IncrementCounter(MetricUnknownTickersTotal)
 	}
+
+	data := messages.ListingData{
+		Event:     event,
+		Latencies: event.Latencies(),
+		Tickers:   make([]messages.TickerStatus, 0, len(known)+len(unknown)),
+		Scheduled: make([]messages.ScheduledAction, 0, len(scheduled)),
+	}
+	data.TradingStartsAt, _ = event.TradingStartTime()
+
+	for _, symbol := range known {
+		data.Tickers = append(data.Tickers, messages.TickerStatus{
+			Ticker:     symbol.Ticker,
+			KoreanName: symbol.KoreanName,
+			Known:      true,
+			Tradable:   symbol.Tradable(),
+			Uncertain: !slices.ContainsFunc(certain, func(c tickers.Symbol) bool {
+				return c.Ticker == symbol.Ticker
+			}),
+		})
+	}
+
+	for _, ticker := range unknown {
+		data.Tickers = append(data.Tickers, messages.TickerStatus{Ticker: ticker})
+	}
+
+	for _, job := range scheduled {
+		data.Scheduled = append(data.Scheduled, messages.ScheduledAction{
+			Action: job.Action,
+			Ticker: job.Ticker,
+			RunAt:  job.RunAt,
+		})
+	}
+
+	m.alerter.SendTemplatedAlert(messages.TemplateListing, data)
 }
@@ -1,8 +1,22 @@
 package core
 
-import "testing"
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
 
-func TestContainsKoreanListingPattern(t *testing.T) {
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/service"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/tickers"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/gate"
+)
+
+func TestContainsListingPattern(t *testing.T) {
 	testCases := []struct {
 		news    string
 		expects bool
@@ -15,6 +29,7 @@ func TestContainsKoreanListingPattern(t *testing.T) {
 		{"커널다오(KERNEL) 신규 거래지원 안내 (BTC, USDT 마켓) (업비트 ATH 이벤트 안내)", true},
 		{"펏지펭귄(PENGU) 신규 거래지원 안내 (KRW, BTC, USDT 마켓)", true},
 		{"셀레스티아(TIA)(KRW, BTC, USDT 마켓), 아이오넷(IO)(BTC, USDT 마켓) 신규 거래지원 안내 (주문 타입 제한 관련 안내)", true},
+		{"Market Support for Livepeer(LPT)(KRW, USDT Market)", true},
 		{"스톰엑스(STMX) 거래지원 종료 안내 (7/3 15:00)", false},
 		{"넴(XEM) 거래지원 종료 안내 (7/3 15:00)", false},
 		{"신세틱스(SNX) 거래 유의 종목 지정 기간 연장 안내", false},
@@ -23,8 +38,189 @@ func TestContainsKoreanListingPattern(t *testing.T) {
 	}
 
 	for i, tc := range testCases {
-		if got := containsKoreanListingPattern(tc.news); got != tc.expects {
+		if got := containsListingPattern(tc.news, config.DefaultListingPatterns); got != tc.expects {
 			t.Errorf("case %d failed: got %v, want %v, news: %s", i+1, got, tc.expects, tc.news)
 		}
 	}
 }
+
+// testSymbolSource lists the symbols as known on the venue
+type testSymbolSource []tickers.Symbol
+
+func (s testSymbolSource) Name() string {
+	return tickers.SourceVenue
+}
+
+func (s testSymbolSource) FetchSymbols(context.Context) ([]tickers.Symbol, error) {
+	return s, nil
+}
+
+type testAlerter struct{}
+
+func (testAlerter) SendTemplatedAlert(string, any) {}
+
+// newTestNewsMonitor returns a monitor with LPT tradable on the venue, its orders are sent to the channel
+func newTestNewsMonitor(t *testing.T, scheduler *ActionScheduler, orders chan<- string) *NewsMonitor {
+	t.Helper()
+
+	registry := tickers.NewRegistry(testSymbolSource{
+		{Ticker: "LPT"},
+	})
+	if err := registry.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	deps := di.Container{
+		Config:  config.Config{Classifier: config.Classifier{ListingPatterns: config.DefaultListingPatterns}},
+		Metrics: service.NewPrometheusService(),
+		Logger:  slog.New(slog.DiscardHandler),
+	}
+
+	monitor := NewNewsMonitor(nil, registry, nil, scheduler, NewTradingSwitch(config.Trading{Enabled: true}), deps)
+	monitor.alerter = testAlerter{}
+	monitor.openOrder = func(ticker string, _ TradeSize) (gate.OrderTimings, error) {
+		orders <- ticker
+		return gate.OrderTimings{SentAt: time.Now(), AckedAt: time.Now()}, nil
+	}
+
+	return monitor
+}
+
+func TestNewsMonitor_OneOrderPerEvent(t *testing.T) {
+	testCases := []struct {
+		name  string
+		event func() entity.NewsEvent
+	}{
+		{
+			name: "immediate order without trading start time",
+			event: func() entity.NewsEvent {
+				return entity.NewsEvent{Title: "라이브피어(LPT) 신규 거래지원 안내"}
+			},
+		},
+		{
+			name: "scheduled order with trading start time",
+			event: func() entity.NewsEvent {
+				// The open action of the test scheduler runs 30s before the start
+				return listingEvent(time.Now().Add(30*time.Second + 200*time.Millisecond))
+			},
+		},
+		{
+			name: "scheduled order with trading start time parsed after emission",
+			event: func() entity.NewsEvent {
+				event := listingEvent(time.Now().Add(30*time.Second + 200*time.Millisecond))
+
+				pending := make(chan *entity.NoticeDetails, 1)
+				pending <- event.Details
+				event.Details, event.PendingDetails = nil, pending
+
+				return event
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			executed := make(chan ScheduledJob, 4)
+			scheduler := newTestActionScheduler(t, filepath.Join(t.TempDir(), "jobs.json"), executed)
+			go scheduler.Run(ctx)
+
+			orders := make(chan string, 4)
+			monitor := newTestNewsMonitor(t, scheduler, orders)
+
+			var count atomic.Int32
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+
+				for {
+					select {
+					case <-orders:
+						count.Add(1)
+					case job := <-executed:
+						if job.Type == config.ScheduledActionOpenPosition {
+							count.Add(1)
+						}
+					case <-time.After(time.Second):
+						return
+					}
+				}
+			}()
+
+			monitor.handle(ctx, tc.event())
+			<-done
+
+			if got := count.Load(); got != 1 {
+				t.Errorf("opened %d positions for the event, want 1", got)
+			}
+		})
+	}
+}
+
+func TestNewsMonitor_OrdersBeforeDetails(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Only the reminder is scheduled, the order doesn't wait for the details which never arrive
+	scheduler := newTestActionScheduler(t, filepath.Join(t.TempDir(), "jobs.json"), make(chan ScheduledJob, 4))
+	scheduler.config.Actions = scheduler.config.Actions[:1]
+
+	orders := make(chan string, 4)
+	monitor := newTestNewsMonitor(t, scheduler, orders)
+
+	event := entity.NewsEvent{Title: "라이브피어(LPT) 신규 거래지원 안내", PendingDetails: make(chan *entity.NoticeDetails)}
+
+	handled := make(chan struct{})
+	go func() {
+		monitor.handle(ctx, event)
+		close(handled)
+	}()
+
+	select {
+	case ticker := <-orders:
+		if ticker != "LPT" {
+			t.Errorf("opened a position for %s, want LPT", ticker)
+		}
+	case <-handled:
+		t.Fatal("handled the event without an order")
+	case <-time.After(detailsWaitTimeout / 2):
+		t.Fatal("order waited for the details")
+	}
+
+	select {
+	case <-handled:
+	case <-time.After(2 * detailsWaitTimeout):
+		t.Fatal("wait for the details is not bounded")
+	}
+}
+
+func TestNewsMonitor_NoOrderOnApproximateName(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	executed := make(chan ScheduledJob, 4)
+	scheduler := newTestActionScheduler(t, filepath.Join(t.TempDir(), "jobs.json"), executed)
+	go scheduler.Run(ctx)
+
+	orders := make(chan string, 4)
+	monitor := newTestNewsMonitor(t, scheduler, orders)
+	monitor.dictionary = tickers.NewDictionary(nil)
+	monitor.dictionary.Seed([]tickers.Symbol{{Ticker: "LPT", KoreanName: "라이브피어"}})
+
+	// A misspelled name is matched fuzzily
+	event := listingEvent(time.Now().Add(2 * time.Hour))
+	event.Title = "라이브피오 신규 거래지원 안내"
+	monitor.handle(ctx, event)
+
+	select {
+	case ticker := <-orders:
+		t.Errorf("opened a position for %s matched by an approximate name", ticker)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if pending := scheduler.Pending(); len(pending) != 0 {
+		t.Errorf("scheduled %d actions for an approximate name, want none", len(pending))
+	}
+}
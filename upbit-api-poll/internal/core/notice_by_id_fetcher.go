@@ -4,31 +4,43 @@ import (
 	"context"
 	"fmt"
 	"slices"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/capture"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/messages"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/notice"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/container"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
 )
 
 const (
 	noticeFetcherChanSize = 8
 
+	// recentResponsesCapacity is the number of polled responses kept by the by-ID fetchers for operators
+	recentResponsesCapacity = 20
+
 	defaultNoticeTitle = "비트코인, 이더리움, 엑스알피(리플), NFT 등 다양한 디지털 자산, 국내 거래량 1위 거래소 업비트에서 지금 확인해보세요. No.1 Digital Asset Exchange in Korea, Upbit. Trade various digital assets conveniently and securely including Bitcoin, Ethereum, XRP(Ripple), NFT etc."
 
 	MetricUpbitNewNewsDetectedTotal = "upbit_new_news_detected_total"
-	MetricUpbitNewsParseDuration    = "upbit_news_parse_duration"
+	MetricUpbitNewsParseDuration    = "upbit_news_parse_duration_seconds"
 )
 
 type NoticeByIDFetcher struct {
 	poller *httptools.ProxyRotatingPoller
+	// endpoint is polled with the IDs of the probe
+	endpoint    httptools.URLTemplate
+	cacheBuster httptools.CacheBuster
 
 	newsGuard     sync.Mutex
 	lastNewsTitle string
-	nextNewsID    int
+	probe         *idProbe
+	ledger        *noticeLedger
+
+	recentResponses *container.RingTS[httptools.Response]
 
 	alreadyStreaming atomic.Bool
 
@@ -36,13 +48,27 @@ type NoticeByIDFetcher struct {
 }
 
 func NewNoticeByIDFetcher(deps di.Container) (*NoticeByIDFetcher, error) {
-	fetcher := &NoticeByIDFetcher{deps: deps}
+	cacheBuster, err := httptools.NewCacheBuster(deps.Config.UpbitAPI.CacheBusting.NoticeByID)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher := &NoticeByIDFetcher{
+		endpoint:        deps.Config.UpbitAPI.NoticeByIDURL(),
+		cacheBuster:     cacheBuster,
+		probe:           newIDProbe(0, deps.Config.UpbitAPI.LookAhead),
+		ledger:          newNoticeLedger(),
+		recentResponses: container.NewRingTS[httptools.Response](recentResponsesCapacity),
+		deps:            deps,
+	}
 
 	if err := fetcher.initLatestNewsTitleAndNextNewsID(context.Background()); err != nil {
 		fetcher.deps.Logger.Error("failed to init latest news title and next news id", "error", err)
 		return nil, err
 	}
 
+	fetcher.ledger.rebase(fetcher.probe.cursor)
+
 	if err := fetcher.initPoller(); err != nil {
 		fetcher.deps.Logger.Error("failed to init poller", "error", err)
 		return nil, err
@@ -51,16 +77,69 @@ func NewNoticeByIDFetcher(deps di.Container) (*NoticeByIDFetcher, error) {
 	return fetcher, nil
 }
 
-func (f *NoticeByIDFetcher) StreamNewNoticeTitles(
+// Poller returns the poller of the fetcher for runtime control
+func (f *NoticeByIDFetcher) Poller() *httptools.ProxyRotatingPoller {
+	return f.poller
+}
+
+// Cursor returns the ID of the next notice the fetcher waits for
+func (f *NoticeByIDFetcher) Cursor() int {
+	f.newsGuard.Lock()
+	defer f.newsGuard.Unlock()
+
+	return f.probe.cursor
+}
+
+// SetCursor makes the fetcher wait for the notice with the ID.
+// The last seen title is forgotten, so the notice found at the ID is emitted even if it was seen before.
+func (f *NoticeByIDFetcher) SetCursor(id int) {
+	f.newsGuard.Lock()
+	defer f.newsGuard.Unlock()
+
+	f.probe.reset(id)
+	f.ledger.rebase(id)
+	f.lastNewsTitle = ""
+	f.poller.SetURLs(f.probe.urls(f.endpoint, time.Now())...)
+}
+
+// Reconcile moves the cursor after the listed notices if it's behind them
+// and returns the listed notices which were never emitted
+func (f *NoticeByIDFetcher) Reconcile(notices []entity.Notice) Reconciliation {
+	f.newsGuard.Lock()
+	defer f.newsGuard.Unlock()
+
+	reconciliation, title := f.ledger.reconcile(f.probe, notices, time.Now())
+	if reconciliation.Repaired() {
+		f.lastNewsTitle = title
+		f.poller.SetURLs(f.probe.urls(f.endpoint, time.Now())...)
+	}
+
+	return reconciliation
+}
+
+// refreshProbes stops polling the skipped IDs kept for too long
+func (f *NoticeByIDFetcher) refreshProbes() {
+	f.newsGuard.Lock()
+	defer f.newsGuard.Unlock()
+
+	f.poller.SetURLs(f.probe.urls(f.endpoint, time.Now())...)
+}
+
+// RecentResponses returns up to n latest polled responses, newest first
+func (f *NoticeByIDFetcher) RecentResponses(n int) []httptools.Response {
+	return f.recentResponses.Last(n)
+}
+
+func (f *NoticeByIDFetcher) StreamNewNotices(
 	ctx context.Context,
-) (<-chan entity.NewsTitle, error) {
+) (<-chan entity.NewsEvent, error) {
 	if !f.alreadyStreaming.CompareAndSwap(false, true) {
 		return nil, fmt.Errorf("already streaming")
 	}
 
-	f.deps.Logger.Info("Starting to stream new notice titles", "next_news_id", f.nextNewsID)
+	f.deps.Logger.Info("Starting to stream new notice titles", "next_news_id", f.Cursor())
 
-	newsChan := make(chan entity.NewsTitle, noticeFetcherChanSize)
+	newsChan := make(chan entity.NewsEvent, noticeFetcherChanSize)
 
 	responsesChan, err := f.poller.StartPolling(ctx)
 	if err != nil {
@@ -80,66 +159,98 @@ func (f *NoticeByIDFetcher) StreamNewNoticeTitles(
 
 func (f *NoticeByIDFetcher) populateWithNewNews(
 	ctx context.Context,
-	newsChan chan<- entity.NewsTitle,
+	newsChan chan<- entity.NewsEvent,
 	responses <-chan httptools.Response,
 ) error {
 	banned := map[string]time.Time{}
+
+	refresh := time.NewTicker(probeRefreshInterval)
+	defer refresh.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 
+		case <-refresh.C:
+			f.refreshProbes()
+
 		case response := <-responses:
-			if response.IsTooManyRequests() {
-				if _, ok := banned[response.ProxyAddr]; ok {
-					if time.Since(banned[response.ProxyAddr]) < 1*time.Hour {
+			found := make([]foundNotice, 0)
+
+			for _, response := range drainResponses(response, responses) {
+				f.recentResponses.Push(response)
+
+				if response.IsTooManyRequests() {
+					if _, ok := banned[response.ProxyAddr]; ok {
+						if time.Since(banned[response.ProxyAddr]) < 1*time.Hour {
+							continue
+						}
+
+						delete(banned, response.ProxyAddr)
 						continue
 					}
 
-					delete(banned, response.ProxyAddr)
+					banned[response.ProxyAddr] = time.Now()
+
+					f.deps.SendTemplatedMessage(messages.TemplateTooManyRequests, messages.TooManyRequestsData{
+						Response:      response,
+						BannedProxies: len(banned),
+					})
+
+					f.deps.Logger.Error(
+						"too many requests",
+						"status",
+						response.StatusCode,
+						"proxy",
+						httptools.RedactProxyAddress(response.ProxyAddr),
+						"headers",
+						response.Headers,
+					)
+
 					continue
 				}
 
-				banned[response.ProxyAddr] = time.Now()
+				if !response.IsOK() {
+					continue
+				}
 
-				f.deps.SendMessage(
-					"Something is wrong with poller: too many requests\nProxy: %s\nStatus: %d\nHeaders: %s\nDead proxies count: %d",
-					response.ProxyAddr,
-					response.StatusCode,
-					response.Headers,
-					len(banned),
-				)
+				f.deps.Captures.Sample(entity.NewsSourceNoticeByID, response)
+
+				span := startParseSpan(response, entity.NewsSourceNoticeByID)
+				title, err := f.parseNoticePage(response)
+				endSpan(span, err)
+				if err != nil {
+					f.deps.Logger.Error(
+						"failed to parse notice page",
+						"error",
+						err,
+						"capture",
+						f.deps.Captures.Save(capture.ReasonParseFailure, entity.NewsSourceNoticeByID, response, err),
+					)
+					continue
+				}
 
-				f.deps.Logger.Error(
-					"too many requests",
-					"status",
-					response.StatusCode,
-					"proxy",
-					response.ProxyAddr,
-					"headers",
-					response.Headers,
-				)
+				if title == defaultNoticeTitle {
+					continue
+				}
 
-				continue
-			}
+				id, err := probedID(f.endpoint, response.URL)
+				if err != nil {
+					f.deps.Logger.Error("failed to get notice ID", "error", err)
+					continue
+				}
 
-			if !response.IsOK() {
-				continue
+				found = append(found, foundNotice{id: id, title: title, response: response, span: span.SpanContext()})
 			}
 
-			title, err := f.parseNoticePage(response)
-			if err != nil {
-				f.deps.Logger.Error(
-					"failed to parse notice page",
-					"error",
-					err,
-					"body",
-					string(response.Body),
-				)
-				continue
-			}
+			slices.SortFunc(found, func(a, b foundNotice) int {
+				return a.id - b.id
+			})
 
-			f.updateAndNotify(newsChan, title, response)
+			for _, notice := range found {
+				f.updateAndNotify(newsChan, notice)
+			}
 		}
 	}
 }
@@ -148,104 +259,135 @@ func (f *NoticeByIDFetcher) parseNoticePage(response httptools.Response) (entity
 	timer := f.deps.Metrics.StartTimer(MetricUpbitNewsParseDuration, "fetcher", "notice_by_id")
 	defer timer.ObserveDuration()
 
-	// Convert the response body to a string
-	bodyStr := string(response.Body)
+	return notice.ParseTitle(response.Body)
+}
 
-	// Find the start of the meta tag with name="description"
-	metaTagStart := `<meta name="description" content="`
-	startIndex := strings.Index(bodyStr, metaTagStart)
-	if startIndex == -1 {
-		return "", fmt.Errorf("description meta tag not found")
-	}
+// parseNoticeDetails parses the full notice page. It is only done for new notices,
+// since the title is enough to tell whether the notice is new.
+func (f *NoticeByIDFetcher) parseNoticeDetails(event entity.NewsEvent, response httptools.Response) *entity.NoticeDetails {
+	timer := f.deps.Metrics.StartTimer(
+		MetricUpbitNewsParseDuration,
+		"fetcher",
+		"notice_by_id_details",
+	)
+	defer timer.ObserveDuration()
 
-	// Find the end of the content attribute
-	startIndex += len(metaTagStart)
-	endIndex := strings.Index(bodyStr[startIndex:], `" />`)
-	if endIndex == -1 {
-		return "", fmt.Errorf("end of description content not found")
-	}
+	_, span := startEventSpan(context.Background(), event, SpanParseDetails)
 
-	// Extract the description content
-	description := bodyStr[startIndex : startIndex+endIndex]
+	details, err := notice.ParsePage(response.Body, response.ReceivedAt)
+	endSpan(span, err)
+	if err != nil {
+		f.deps.Logger.Error(
+			"failed to parse notice details",
+			"error",
+			err,
+			"capture",
+			f.deps.Captures.Save(capture.ReasonParseFailure, entity.NewsSourceNoticeByID, response, err),
+		)
+		return nil
+	}
 
-	return entity.NewsTitle(description), nil
+	return &details
 }
 
-func (f *NoticeByIDFetcher) updateAndNotify(
-	newsChan chan<- entity.NewsTitle,
-	title entity.NewsTitle,
-	response httptools.Response,
-) {
-	if title == defaultNoticeTitle {
+// updateAndNotify emits the notice if it's new, the found notices must be passed in ID order.
+// The event is emitted before the page is parsed, the details follow through its PendingDetails.
+func (f *NoticeByIDFetcher) updateAndNotify(newsChan chan<- entity.NewsEvent, found foundNotice) {
+	f.newsGuard.Lock()
+	defer f.newsGuard.Unlock()
+
+	if !f.probe.found(found.id, found.response.ReceivedAt) {
 		return
 	}
 
-	f.newsGuard.Lock()
-	defer f.newsGuard.Unlock()
+	f.poller.SetURLs(f.probe.urls(f.endpoint, time.Now())...)
 
-	if f.lastNewsTitle == title {
+	if f.lastNewsTitle == found.title {
 		f.deps.Logger.Info(
 			"skipping notice",
+			"id",
+			found.id,
 			"title",
-			title,
+			found.title,
 		)
 		return
 	}
 
-	f.nextNewsID += 1
-	f.lastNewsTitle = title
+	// The publication time is cheap to find, unlike the details it's taken before emitting
+	listedAt, err := notice.ParseListedAt(found.response.Body)
+	if err != nil {
+		f.deps.Logger.Warn("failed to parse notice publication time", "id", found.id, "error", err)
+	}
+
+	details := make(chan *entity.NoticeDetails, 1)
 
-	newsChan <- title
-	f.poller.SetURL(fmt.Sprintf(f.deps.Config.UpbitAPI.NoticeByIDEndpoint, f.nextNewsID))
+	event := entity.NewsEvent{
+		Title:          found.title,
+		Source:         entity.NewsSourceNoticeByID,
+		NoticeID:       found.id,
+		ListedAt:       listedAt,
+		ReceivedAt:     found.response.ReceivedAt,
+		PendingDetails: details,
+		SpanContext:    found.span,
+	}
+	event.SpanContext = startNewsTrace(event)
+
+	f.lastNewsTitle = found.title
+
+	newsChan <- event
+	f.ledger.emit(event.NoticeID)
+
+	go f.completeDetails(event, found.response, details)
+
+	f.deps.Captures.Save(capture.ReasonNewNews, entity.NewsSourceNoticeByID, found.response, nil)
 
 	f.deps.Metrics.IncrementCounter(MetricUpbitNewNewsDetectedTotal, "fetcher", "notice_by_id")
+}
 
-	f.deps.SendMessage(
-		fmt.Sprintf(
-			"New notice\n"+
-				"* NEWS INFO\n"+
-				"Title: %s\n"+
-				"\n"+
-				"* RESPONSE INFO\n"+
-				"Client: %s\n"+
-				"Proxy address: %s\n"+
-				"\n"+
-				"Requested at: %s\n"+
-				"Received at: %s\n"+
-				"\n"+
-				"Status: %d\n"+
-				"Headers: %s\n"+
-				"\n"+
-				"* DELAYS INFO\n"+
-				"Between requested_at and received_at: %s\n",
-			title,
-			response.ClientName,
-			response.ProxyAddr,
-			response.RequestedAt.Format("2006-01-02 15:04:05.000"),
-			response.ReceivedAt.Format("2006-01-02 15:04:05.000"),
-			response.StatusCode,
-			response.Headers,
-			response.ReceivedAt.Sub(response.RequestedAt),
-		),
-	)
+// completeDetails parses the details of the emitted event, delivers them to its consumer
+// and sends the notice alert with them
+func (f *NoticeByIDFetcher) completeDetails(
+	event entity.NewsEvent,
+	response httptools.Response,
+	details chan<- *entity.NoticeDetails,
+) {
+	event.Details = f.parseNoticeDetails(event, response)
+	event.PendingDetails = nil
+
+	details <- event.Details
+	close(details)
+
+	traceNotification(event, messages.TemplateNotice, func() {
+		f.deps.SendTemplatedAlert(messages.TemplateNotice, messages.NewsData{
+			Event:    event,
+			Response: response,
+		})
+	})
 }
 
 func (f *NoticeByIDFetcher) initLatestNewsTitleAndNextNewsID(ctx context.Context) error {
 	hostIPClient, err := httptools.NewClientHTTP2(httptools.ClientConfig{
-		Logger:  f.deps.Logger,
-		Metrics: &metricsAdapter{prometheus: f.deps.Metrics},
+		Logger:     f.deps.Logger,
+		Metrics:    &metricsAdapter{prometheus: f.deps.Metrics},
+		Headers:    f.deps.Config.ProxyRotatingPoller.Headers,
+		HeaderKind: httptools.HeaderKindAPI,
 	})
 	if err != nil {
 		return err
 	}
 
-	response, err := hostIPClient.Request(ctx, fmt.Sprintf(f.deps.Config.UpbitAPI.AnnouncementsEndpoint, 20))
+	endpoint, err := latestNoticesURL(f.deps.Config.UpbitAPI, 20)
+	if err != nil {
+		return err
+	}
+
+	response, err := hostIPClient.Request(ctx, endpoint)
 	if err != nil {
 		f.deps.Logger.Error("failed to get notices", "error", err)
 		return err
 	}
 
-	f.deps.Logger.Info("getting notices", "endpoint", fmt.Sprintf(f.deps.Config.UpbitAPI.AnnouncementsEndpoint, 20), "body", string(response.Body), "status", response.StatusCode, "proxy", response.ProxyAddr)
+	f.deps.Logger.Info("getting notices", "endpoint", endpoint, "status", response.StatusCode, "proxy", httptools.RedactProxyAddress(response.ProxyAddr))
 
 	notices := entity.Announcements{}
 	if err := notices.UnmarshalJSON(response.Body); err != nil {
@@ -271,7 +413,7 @@ func (f *NoticeByIDFetcher) initLatestNewsTitleAndNextNewsID(ctx context.Context
 
 	lastNoticeIndex := len(announcements) - 1
 	f.lastNewsTitle = announcements[lastNoticeIndex].Title
-	f.nextNewsID = announcements[lastNoticeIndex].ID + 1
+	f.probe.cursor = announcements[lastNoticeIndex].ID + 1
 
 	pollingInterval := time.Duration(
 		float64(time.Second) / f.deps.Config.UpbitAPI.NoticeByIDSingleIPMaxRPS,
@@ -295,7 +437,7 @@ func (f *NoticeByIDFetcher) initLatestNewsTitleAndNextNewsID(ctx context.Context
 
 			response, err := hostIPClient.Request(
 				ctx,
-				fmt.Sprintf(f.deps.Config.UpbitAPI.NoticeByIDEndpoint, f.nextNewsID),
+				f.cacheBuster.Bust(f.endpoint.With(httptools.VarID, f.probe.cursor).String()),
 			)
 			if err != nil {
 				return err
@@ -306,20 +448,20 @@ func (f *NoticeByIDFetcher) initLatestNewsTitleAndNextNewsID(ctx context.Context
 				return err
 			}
 
-			f.deps.Logger.Info("Fetching notice", "id", f.nextNewsID, "title", title)
+			f.deps.Logger.Info("Fetching notice", "id", f.probe.cursor, "title", title)
 
 			if title == defaultNoticeTitle {
 				f.deps.Logger.Info(
 					"no more notices",
 					"next_news_id",
-					f.nextNewsID,
+					f.probe.cursor,
 					"last_news_title",
 					f.lastNewsTitle,
 				)
 				return nil
 			}
 
-			f.nextNewsID += 1
+			f.probe.cursor += 1
 			f.lastNewsTitle = title
 		}
 	}
@@ -327,7 +469,9 @@ func (f *NoticeByIDFetcher) initLatestNewsTitleAndNextNewsID(ctx context.Context
 
 func (f *NoticeByIDFetcher) initPoller() error {
 	poller, err := httptools.NewProxyRotatingPollerBuilder().
-		WithURL(fmt.Sprintf(f.deps.Config.UpbitAPI.NoticeByIDEndpoint, f.nextNewsID)).
+		WithName("notice_by_id").
+		WithURL(f.endpoint.With(httptools.VarID, f.probe.cursor).String()).
+		WithCacheBuster(f.cacheBuster).
 		WithTargetRPS(f.deps.Config.ProxyRotatingPoller.TargetRPS).
 		WithSingleProxyMaxRPS(f.deps.Config.UpbitAPI.NoticeByIDSingleIPMaxRPS).
 		WithProxies(f.deps.Config.ProxyRotatingPoller.Proxies...).
@@ -341,6 +485,8 @@ func (f *NoticeByIDFetcher) initPoller() error {
 				ClientRetriesConfig: f.deps.Config.ProxyRotatingPoller.Retries,
 				Metrics:             &metricsAdapter{prometheus: f.deps.Metrics},
 				Logger:              f.deps.Logger,
+				Headers:             f.deps.Config.ProxyRotatingPoller.Headers,
+				HeaderKind:          httptools.HeaderKindDocument,
 			})
 		}).
 		Build()
@@ -348,6 +494,8 @@ func (f *NoticeByIDFetcher) initPoller() error {
 		return err
 	}
 
+	poller.SetURLs(f.probe.urls(f.endpoint, time.Now())...)
+
 	f.poller = poller
 
 	return nil
@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"slices"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/capture"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+const (
+	// reconcileNoticesCount is the number of latest notices compared with the by-ID fetchers
+	reconcileNoticesCount = 20
+
+	// reconcileGracePeriod leaves the notices listed just now to the fetchers, which can be slower than the list
+	reconcileGracePeriod = time.Minute
+
+	MetricUpbitReconcileMissedTotal  = "upbit_reconcile_missed_notices_total"
+	MetricUpbitReconcileRepairsTotal = "upbit_reconcile_cursor_repairs_total"
+	MetricUpbitReconcileErrorsTotal  = "upbit_reconcile_errors_total"
+)
+
+// ReconciledFetcher is a by-ID fetcher which can be checked against the announcements list
+type ReconciledFetcher interface {
+	Reconcile(notices []entity.Notice) Reconciliation
+}
+
+// Reconciliation is the result of comparing a fetcher with the announcements list
+type Reconciliation struct {
+	// Missed are the listed notices the fetcher never emitted
+	Missed []entity.Notice
+	// PreviousCursor differs from Cursor when the cursor was behind the list and was moved after it
+	PreviousCursor int
+	Cursor         int
+}
+
+// Repaired reports whether the cursor was moved
+func (r Reconciliation) Repaired() bool {
+	return r.PreviousCursor != r.Cursor
+}
+
+// Reconciler periodically fetches the announcements list and corrects the by-ID fetchers
+// whose cursor drifted: a missed or deleted notice, or a title equal to the previous one.
+type Reconciler struct {
+	client   httptools.Client
+	fetchers []reconciledSource
+
+	deps di.Container
+}
+
+type reconciledSource struct {
+	name    string
+	fetcher ReconciledFetcher
+}
+
+func NewReconciler(deps di.Container) (*Reconciler, error) {
+	client, err := httptools.NewClientHTTP2(httptools.ClientConfig{
+		Logger:     deps.Logger,
+		Metrics:    &metricsAdapter{prometheus: deps.Metrics},
+		Headers:    deps.Config.ProxyRotatingPoller.Headers,
+		HeaderKind: httptools.HeaderKindAPI,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reconciler{client: client, deps: deps}, nil
+}
+
+// Add makes the reconciler check the fetcher, the name is used in alerts and metrics
+func (r *Reconciler) Add(name string, fetcher ReconciledFetcher) {
+	r.fetchers = append(r.fetchers, reconciledSource{name: name, fetcher: fetcher})
+}
+
+// Run reconciles the fetchers every interval until the context is done
+func (r *Reconciler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := r.Reconcile(ctx); err != nil {
+				r.deps.Metrics.IncrementCounter(MetricUpbitReconcileErrorsTotal)
+				r.deps.Logger.Error("failed to reconcile cursors", "error", err)
+			}
+		}
+	}
+}
+
+// Reconcile fetches the announcements list once and reconciles every fetcher with it
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	endpoint, err := latestNoticesURL(r.deps.Config.UpbitAPI, reconcileNoticesCount)
+	if err != nil {
+		return err
+	}
+
+	response, err := r.client.Request(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+
+	if !response.IsOK() {
+		return fmt.Errorf("unexpected status: %d", response.StatusCode)
+	}
+
+	announcements := entity.Announcements{}
+	if err := announcements.UnmarshalJSON(response.Body); err != nil {
+		r.deps.Captures.Save(capture.ReasonParseFailure, "reconciler", response, err)
+		return err
+	}
+
+	if !announcements.Success {
+		return fmt.Errorf("failed to get announcements: %s", announcements.ErrorMessage)
+	}
+
+	if len(announcements.Data.Notices) == 0 {
+		return fmt.Errorf("no announcements found")
+	}
+
+	for _, source := range r.fetchers {
+		r.report(source.name, source.fetcher.Reconcile(announcements.Data.Notices))
+	}
+
+	return nil
+}
+
+func (r *Reconciler) report(name string, reconciliation Reconciliation) {
+	if reconciliation.Repaired() {
+		r.deps.Metrics.IncrementCounter(MetricUpbitReconcileRepairsTotal, "fetcher", name)
+		r.deps.Logger.Warn(
+			"cursor repaired",
+			"fetcher",
+			name,
+			"previous",
+			reconciliation.PreviousCursor,
+			"cursor",
+			reconciliation.Cursor,
+		)
+		r.deps.SendMessage(
+			"🔧 <b>Cursor repaired</b>\nSource: %s\nCursor: %d → %d",
+			name,
+			reconciliation.PreviousCursor,
+			reconciliation.Cursor,
+		)
+	}
+
+	for _, notice := range reconciliation.Missed {
+		r.deps.Metrics.IncrementCounter(MetricUpbitReconcileMissedTotal, "fetcher", name)
+		r.deps.Logger.Error("notice never emitted", "fetcher", name, "id", notice.ID, "title", notice.Title)
+		r.deps.SendAlert(
+			"⚠️ <b>Notice never emitted</b>\nSource: %s\nID: %d\nTitle: %s\nListed at: %s",
+			name,
+			notice.ID,
+			html.EscapeString(notice.Title),
+			notice.ListedAt.Format(time.DateTime),
+		)
+	}
+}
+
+// noticeLedger remembers the notices emitted by a by-ID fetcher to find the ones it missed.
+// It's not safe for concurrent use.
+type noticeLedger struct {
+	// since is the first ID the fetcher is responsible for, the earlier notices were known on start
+	since int
+	// emitted are the IDs emitted or already reported as missed. Upbit publishes a few notices a day,
+	// so they are kept for the lifetime of the service: an updated notice can be listed again.
+	emitted map[int]bool
+}
+
+func newNoticeLedger() *noticeLedger {
+	return &noticeLedger{emitted: make(map[int]bool)}
+}
+
+// rebase makes the ledger responsible for the notices from the ID on
+func (l *noticeLedger) rebase(since int) {
+	l.since = since
+}
+
+// emit records the emitted notice
+func (l *noticeLedger) emit(id int) {
+	l.emitted[id] = true
+}
+
+// reconcile compares the probe with the notices listed before the grace period: the notices never emitted
+// are returned once, and a cursor at or before a listed notice is moved after the latest one.
+// The second result is the title of the latest listed notice when the cursor was moved.
+func (l *noticeLedger) reconcile(probe *idProbe, notices []entity.Notice, now time.Time) (Reconciliation, string) {
+	notices = slices.DeleteFunc(slices.Clone(notices), func(notice entity.Notice) bool {
+		return now.Sub(notice.ListedAt) < reconcileGracePeriod
+	})
+	slices.SortFunc(notices, func(a, b entity.Notice) int {
+		return a.ID - b.ID
+	})
+
+	reconciliation := Reconciliation{PreviousCursor: probe.cursor, Cursor: probe.cursor}
+	if len(notices) == 0 {
+		return reconciliation, ""
+	}
+
+	for _, notice := range notices {
+		if notice.ID < l.since || l.emitted[notice.ID] {
+			continue
+		}
+
+		reconciliation.Missed = append(reconciliation.Missed, notice)
+		l.emitted[notice.ID] = true
+	}
+
+	latest := notices[len(notices)-1]
+	if probe.cursor > latest.ID {
+		return reconciliation, ""
+	}
+
+	probe.advance(latest.ID + 1)
+	reconciliation.Cursor = probe.cursor
+
+	return reconciliation, latest.Title
+}
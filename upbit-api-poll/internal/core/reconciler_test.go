@@ -0,0 +1,56 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+)
+
+func TestNoticeLedger_Reconcile(t *testing.T) {
+	now := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	probe := newIDProbe(101, 2)
+	ledger := newNoticeLedger()
+	ledger.rebase(101)
+
+	probe.found(101, now)
+	ledger.emit(101)
+
+	notices := []entity.Notice{
+		{ID: 103, Title: "deleted before it was polled", ListedAt: now.Add(-5 * time.Minute)},
+		{ID: 100, Title: "known on start", ListedAt: now.Add(-time.Hour)},
+		{ID: 101, Title: "emitted", ListedAt: now.Add(-10 * time.Minute)},
+		{ID: 104, Title: "listed just now", ListedAt: now.Add(-10 * time.Second)},
+	}
+
+	reconciliation, title := ledger.reconcile(probe, notices, now)
+
+	if len(reconciliation.Missed) != 1 || reconciliation.Missed[0].ID != 103 {
+		t.Errorf("missed = %+v, expected only the notice never emitted", reconciliation.Missed)
+	}
+
+	if !reconciliation.Repaired() || reconciliation.PreviousCursor != 102 || reconciliation.Cursor != 104 {
+		t.Errorf("reconciliation = %+v, expected the cursor moved after the listed notices", reconciliation)
+	}
+
+	if title != "deleted before it was polled" {
+		t.Errorf("title = %q, expected the title of the latest listed notice", title)
+	}
+
+	reconciliation, _ = ledger.reconcile(probe, notices, now.Add(time.Minute))
+
+	if len(reconciliation.Missed) != 1 || reconciliation.Missed[0].ID != 104 {
+		t.Errorf("missed = %+v, expected the missed notices to be reported once", reconciliation.Missed)
+	}
+
+	if reconciliation.Cursor != 105 {
+		t.Errorf("cursor = %d, expected 105", reconciliation.Cursor)
+	}
+
+	reconciliation, _ = ledger.reconcile(probe, notices, now.Add(time.Minute))
+
+	if len(reconciliation.Missed) != 0 || reconciliation.Repaired() {
+		t.Errorf("reconciliation = %+v, expected nothing to repair", reconciliation)
+	}
+}
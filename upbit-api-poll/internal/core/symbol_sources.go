@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/tickers"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/gate"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+const MetricUnknownTickersTotal = "upbit_unknown_tickers_total"
+
+// NewAssetDictionary creates a dictionary of asset names with the configured aliases.
+// It is seeded with Upbit's korean and english names by the symbol registry.
+func NewAssetDictionary(deps di.Container) *tickers.Dictionary {
+	return tickers.NewDictionary(deps.Config.SymbolRegistry.AssetAliasesMap())
+}
+
+// NewSymbolRegistry creates a registry backed by Upbit's market list and the venue's contract list,
+// which seeds the dictionary on every refresh, and performs the initial refresh.
+// A failed initial refresh is reported but is not fatal.
+func NewSymbolRegistry(
+	ctx context.Context,
+	deps di.Container,
+	dictionary *tickers.Dictionary,
+) (*tickers.Registry, error) {
+	client, err := httptools.NewClientHTTP2(httptools.ClientConfig{
+		Logger:     deps.Logger,
+		Metrics:    &metricsAdapter{prometheus: deps.Metrics},
+		Headers:    deps.Config.ProxyRotatingPoller.Headers,
+		HeaderKind: httptools.HeaderKindAPI,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	registry := tickers.NewRegistry(
+		&upbitMarketsSource{client: client, endpoint: deps.Config.SymbolRegistry.UpbitMarketsEndpoint},
+		venueContractsSource{},
+	)
+
+	registry.OnRefresh(dictionary.Seed)
+
+	if err := registry.Refresh(ctx); err != nil {
+		deps.Logger.Error("failed to refresh symbol registry", "error", err)
+		deps.SendMessage("Failed to refresh symbol registry: %s", html.EscapeString(err.Error()))
+	}
+
+	deps.Logger.Info(
+		"Symbol registry initialized",
+		"symbols",
+		registry.Len(),
+		"asset_names",
+		dictionary.Len(),
+	)
+
+	return registry, nil
+}
+
+type upbitMarketsSource struct {
+	client   httptools.Client
+	endpoint string
+}
+
+func (s *upbitMarketsSource) Name() string {
+	return tickers.SourceUpbit
+}
+
+func (s *upbitMarketsSource) FetchSymbols(ctx context.Context) ([]tickers.Symbol, error) {
+	response, err := s.client.Request(ctx, s.endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if !response.IsOK() {
+		return nil, fmt.Errorf("unexpected status: %d", response.StatusCode)
+	}
+
+	markets := entity.Markets{}
+	if err := markets.UnmarshalJSON(response.Body); err != nil {
+		return nil, err
+	}
+
+	symbols := make([]tickers.Symbol, 0, len(markets))
+	for _, market := range markets {
+		symbols = append(symbols, tickers.Symbol{
+			Ticker:      market.Market,
+			KoreanName:  market.KoreanName,
+			EnglishName: market.EnglishName,
+		})
+	}
+
+	return symbols, nil
+}
+
+type venueContractsSource struct{}
+
+func (venueContractsSource) Name() string {
+	return tickers.SourceVenue
+}
+
+func (venueContractsSource) FetchSymbols(ctx context.Context) ([]tickers.Symbol, error) {
+	contracts, err := gate.ListFuturesContracts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]tickers.Symbol, 0, len(contracts))
+	for _, contract := range contracts {
+		symbols = append(symbols, tickers.Symbol{Ticker: contract})
+	}
+
+	return symbols, nil
+}
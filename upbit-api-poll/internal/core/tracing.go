@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Spans of the detection. parse continues the trace of the poll tick which fetched the response,
+// a news event starts its own trace linked to it.
+const (
+	SpanParse        = "parse"
+	SpanNews         = "news"
+	SpanParseDetails = "parse_details"
+	SpanClassify     = "classify"
+	SpanNotify       = "notify"
+	SpanOrder        = "order"
+)
+
+// tracer delegates to the global provider, which may be set after the package is loaded
+var tracer = otel.Tracer("github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/core")
+
+// startParseSpan starts the span of parsing the response under the span of its request
+func startParseSpan(response httptools.Response, fetcher string) trace.Span {
+	_, span := tracer.Start(
+		trace.ContextWithSpanContext(context.Background(), response.SpanContext),
+		SpanParse,
+		trace.WithAttributes(attribute.String("fetcher", fetcher)),
+	)
+
+	return span
+}
+
+// NewSampler samples the traces of the poll ticks by the ratio and keeps every news trace,
+// the news are rare and their traces are the ones worth keeping
+func NewSampler(ratio float64) sdktrace.Sampler {
+	return newsSampler{ticks: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))}
+}
+
+type newsSampler struct {
+	ticks sdktrace.Sampler
+}
+
+func (s newsSampler) ShouldSample(parameters sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if parameters.Name == SpanNews {
+		return sdktrace.AlwaysSample().ShouldSample(parameters)
+	}
+
+	return s.ticks.ShouldSample(parameters)
+}
+
+func (s newsSampler) Description() string {
+	return "NewsSampler{" + s.ticks.Description() + "}"
+}
+
+// startNewsTrace starts the trace of the event linked to the span of the response which found it
+// and returns its span context, the spans of the stages of the event continue it
+func startNewsTrace(event entity.NewsEvent) trace.SpanContext {
+	options := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithTimestamp(event.ReceivedAt),
+		trace.WithAttributes(
+			attribute.String("source", event.Source),
+			attribute.Int("notice_id", event.NoticeID),
+		),
+	}
+
+	if event.SpanContext.IsValid() {
+		options = append(options, trace.WithLinks(trace.Link{SpanContext: event.SpanContext}))
+	}
+
+	_, span := tracer.Start(context.Background(), SpanNews, options...)
+	span.End()
+
+	return span.SpanContext()
+}
+
+// startEventSpan starts the span of a stage of the event under the span of its last stage
+func startEventSpan(ctx context.Context, event entity.NewsEvent, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	attributes = append(
+		attributes,
+		attribute.String("source", event.Source),
+		attribute.Int("notice_id", event.NoticeID),
+	)
+
+	return tracer.Start(
+		trace.ContextWithSpanContext(ctx, event.SpanContext),
+		name,
+		trace.WithAttributes(attributes...),
+	)
+}
+
+// endSpan records the error on the span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// traceNotification sends the notification of the event in its span
+func traceNotification(event entity.NewsEvent, template string, send func()) {
+	_, span := startEventSpan(context.Background(), event, SpanNotify, attribute.String("template", template))
+	defer span.End()
+
+	send()
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestStartNewsTrace_SampledWithoutPollTick(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder), sdktrace.WithSampler(NewSampler(0)))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// The poll tick is not sampled with ratio 0, so neither is the parse span under it
+	_, poll := tracer.Start(context.Background(), "poll")
+	_, parse := tracer.Start(trace.ContextWithSpanContext(context.Background(), poll.SpanContext()), SpanParse)
+	parse.End()
+	poll.End()
+
+	event := entity.NewsEvent{Source: entity.NewsSourceNoticeByID, NoticeID: 5123, ReceivedAt: time.Now(), SpanContext: parse.SpanContext()}
+	event.SpanContext = startNewsTrace(event)
+
+	_, classify := startEventSpan(context.Background(), event, SpanClassify)
+	classify.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want the news and classify spans", len(spans))
+	}
+
+	news, child := spans[0], spans[1]
+	if news.Name() != SpanNews || news.Parent().IsValid() {
+		t.Errorf("first span is %s with parent %v, want the news root", news.Name(), news.Parent())
+	}
+
+	if links := news.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != parse.SpanContext().SpanID() {
+		t.Errorf("news span links %v, want the parse span", links)
+	}
+
+	if child.Name() != SpanClassify || child.Parent().SpanID() != news.SpanContext().SpanID() {
+		t.Errorf("second span is %s under %v, want classify under the news span", child.Name(), child.Parent())
+	}
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+)
+
+var ErrTradingDisabled = errors.New("trading is disabled")
+
+// TradingSwitch turns opening of positions on listing news on and off and holds
+// the size of the positions. Both can be changed at runtime.
+type TradingSwitch struct {
+	enabled atomic.Bool
+	size    atomic.Pointer[TradeSize]
+}
+
+// TradeSize is the size of the positions opened on listing news
+type TradeSize struct {
+	USDTAmount float64
+	Leverage   string
+}
+
+func NewTradingSwitch(cfg config.Trading) *TradingSwitch {
+	s := &TradingSwitch{}
+	s.enabled.Store(cfg.Enabled)
+	s.SetSize(TradeSize{USDTAmount: cfg.USDTAmount, Leverage: cfg.Leverage})
+
+	return s
+}
+
+func (s *TradingSwitch) Enabled() bool {
+	return s.enabled.Load()
+}
+
+// SetEnabled changes the state and returns the previous one
+func (s *TradingSwitch) SetEnabled(enabled bool) bool {
+	return s.enabled.Swap(enabled)
+}
+
+func (s *TradingSwitch) Size() TradeSize {
+	return *s.size.Load()
+}
+
+func (s *TradingSwitch) SetSize(size TradeSize) {
+	s.size.Store(&size)
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"errors"
+	"html"
 	"math"
 	"net/http"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/messages"
 	"github.com/gorilla/websocket"
 )
 
@@ -41,17 +43,17 @@ func NewWebsocketSucker(deps di.Container) *WebsocketSucker {
 	return sucker
 }
 
-func (ws *WebsocketSucker) StreamNews(ctx context.Context) (<-chan entity.NewsTitle, error) {
+func (ws *WebsocketSucker) StreamNews(ctx context.Context) (<-chan entity.NewsEvent, error) {
 	if !ws.streaming.CompareAndSwap(false, true) {
 		return nil, ErrAlreadyStreaming
 	}
 
 	ws.deps.SendMessage(
 		"🔍 <b>Websocket Sucker Started</b>\nURL: %s",
-		ws.deps.Config.WebsocketSucker.URL,
+		html.EscapeString(ws.deps.Config.WebsocketSucker.URL),
 	)
 
-	newsChan := make(chan entity.NewsTitle, WebsocketSucketNewsChanSize)
+	newsChan := make(chan entity.NewsEvent, WebsocketSucketNewsChanSize)
 
 	go func() {
 		defer ws.streaming.Store(false)
@@ -74,7 +76,7 @@ func (ws *WebsocketSucker) StreamNews(ctx context.Context) (<-chan entity.NewsTi
 	return newsChan, nil
 }
 
-func (ws *WebsocketSucker) read(ctx context.Context, newsChan chan entity.NewsTitle) {
+func (ws *WebsocketSucker) read(ctx context.Context, newsChan chan entity.NewsEvent) {
 	message := make(chan []byte)
 
 	for {
@@ -109,21 +111,22 @@ func (ws *WebsocketSucker) read(ctx context.Context, newsChan chan entity.NewsTi
 			}
 
 			if suckerPayload.Exchange == "upbit" {
-				newsChan <- suckerPayload.OriginalTitle
-
-				ws.deps.SendMessage(
-					"🚨 <b>New Listing Detected via WebSocket</b>\n\n"+
-						"📰 <b>Title:</b> %s\n"+
-						"🏢 <b>Exchange:</b> %s\n"+
-						"🔗 <b>URL:</b> %s\n"+
-						"⏰ <b>Time:</b> %s\n"+
-						"🎯 <b>Tickers:</b> %s",
-					suckerPayload.OriginalTitle,
-					suckerPayload.Exchange,
-					suckerPayload.URL,
-					time.Unix(suckerPayload.Time, 0).Format("2006-01-02 15:04:05"),
-					formatDetections(suckerPayload.Detections),
-				)
+				event := entity.NewsEvent{
+					Title:      suckerPayload.OriginalTitle,
+					Source:     entity.NewsSourceWebsocketSucker,
+					ListedAt:   time.Unix(suckerPayload.Time, 0),
+					ReceivedAt: time.Now(),
+				}
+				event.SpanContext = startNewsTrace(event)
+				newsChan <- event
+
+				ws.deps.SendTemplatedAlert(messages.TemplateWebsocketListing, messages.WebsocketListingData{
+					Title:    suckerPayload.OriginalTitle,
+					Exchange: suckerPayload.Exchange,
+					URL:      suckerPayload.URL,
+					Time:     time.Unix(suckerPayload.Time, 0),
+					Tickers:  formatDetections(suckerPayload.Detections),
+				})
 			}
 
 			ws.deps.Logger.Info("Received sucker payload", "payload", suckerPayload)
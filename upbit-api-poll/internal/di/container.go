@@ -1,68 +1,64 @@
 package di
 
 import (
-	"context"
-	"fmt"
+	"html"
 	"log/slog"
-	"strings"
-	"time"
 
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/capture"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/messages"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/notifier"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/service"
 	"github.com/mymmrac/telego"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 type Container struct {
+	// Telegram is nil when Telegram is disabled
 	Telegram *telego.Bot
+	Notifier *notifier.Registry
+	Messages *messages.Renderer
 	Config   config.Config
 	Logger   *slog.Logger
 	Metrics  *service.PrometheusService
+	// Traces is nil when tracing is disabled
+	Traces   *sdktrace.TracerProvider
+	Captures *capture.Store
 }
 
+// SendMessage queues an operational message to all enabled notification backends
 func (c Container) SendMessage(format string, args ...any) {
-	now := time.Now().Format("2006-01-02T15:04:05.000Z07:00")
+	c.Notifier.SendMessage(format, args...)
+}
 
-	go func() {
-		format = fmt.Sprintf(
-			"<b>[upbit.api.poller]</b>\n<u>%s</u>\n\n%s",
-			now,
-			format,
-		)
+// SendAlert queues a message delivered ahead of operational messages, used for listing news
+func (c Container) SendAlert(format string, args ...any) {
+	c.Notifier.SendAlert(format, args...)
+}
 
-		body := fmt.Sprintf(format, args...)
-		parts := strings.Split(body, "\n")
-		builder := strings.Builder{}
+// SendTemplatedAlert renders the message template with the data and queues it as an alert.
+// If the template fails, an alert with the error is sent instead so the event is not lost.
+func (c Container) SendTemplatedAlert(template string, data any) {
+	message, err := c.Messages.Render(template, data)
+	if err != nil {
+		c.Logger.Error("failed to render message", "template", template, "error", err)
+		c.Notifier.SendAlert("Failed to render %s message: %s", template, html.EscapeString(err.Error()))
 
-		for _, part := range parts {
-			if len(part) >= 4096 {
-				c.Logger.Error("part is too long", "part", part)
-				continue
-			}
+		return
+	}
 
-			if builder.Len()+len(part) >= 4096 {
-				if _, err := c.Telegram.SendMessage(context.Background(), &telego.SendMessageParams{
-					ChatID:    telego.ChatID{ID: c.Config.Telegram.GroupID},
-					Text:      builder.String(),
-					ParseMode: telego.ModeHTML,
-				}); err != nil {
-					c.Logger.Error("failed to send telegram message", "error", err)
-				}
+	c.Notifier.SendAlert("%s", message)
+}
 
-				builder.Reset()
-			}
+// SendTemplatedMessage renders the message template with the data and queues it as an operational message
+func (c Container) SendTemplatedMessage(template string, data any) {
+	message, err := c.Messages.Render(template, data)
+	if err != nil {
+		c.Logger.Error("failed to render message", "template", template, "error", err)
+		c.Notifier.SendMessage("Failed to render %s message: %s", template, html.EscapeString(err.Error()))
 
-			builder.WriteString(part)
-			builder.WriteString("\n")
-		}
+		return
+	}
 
-		if builder.Len() > 0 {
-			if _, err := c.Telegram.SendMessage(context.Background(), &telego.SendMessageParams{
-				ChatID:    telego.ChatID{ID: c.Config.Telegram.GroupID},
-				Text:      builder.String(),
-				ParseMode: telego.ModeHTML,
-			}); err != nil {
-				c.Logger.Error("failed to send telegram message", "error", err)
-			}
-		}
-	}()
+	c.Notifier.SendMessage("%s", message)
 }
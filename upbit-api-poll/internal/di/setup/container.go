@@ -2,33 +2,38 @@ package setup
 
 import (
 	"log"
-	"strings"
 
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/capture"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/messages"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/service"
-	"github.com/mymmrac/telego"
 )
 
 func MustContainer(cfg config.Config) di.Container {
 	logger := mustLogger(cfg)
+	telegram := mustTelegram(cfg)
+	prometheus := service.NewPrometheusService()
+	prometheus.MustDeclare(metrics...)
 
-	token := strings.Join([]string{
-		cfg.Telegram.BotID,
-		cfg.Telegram.AuthorizationToken,
-	}, ":")
-
-	telegram, err := telego.NewBot(token)
+	renderer, err := messages.New(cfg.Notifier.Messages)
 	if err != nil {
-		log.Panicf("Failed to create telegram bot: %v", err)
+		log.Panicf("Failed to load message templates: %v", err)
 	}
 
-	metrics := service.NewPrometheusService()
+	captures, err := capture.NewStore(cfg.Capture, logger, prometheus)
+	if err != nil {
+		log.Panicf("Failed to open capture store: %v", err)
+	}
 
 	return di.Container{
 		Config:   cfg,
 		Logger:   logger,
 		Telegram: telegram,
-		Metrics:  metrics,
+		Notifier: newNotifier(cfg, telegram, logger),
+		Messages: renderer,
+		Metrics:  prometheus,
+		Traces:   mustTracing(cfg),
+		Captures: captures,
 	}
 }
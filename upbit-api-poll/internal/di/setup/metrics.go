@@ -0,0 +1,177 @@
+package setup
+
+import (
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/capture"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/core"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/service"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/gate"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+// metrics are all the metrics of the service. The proxy label is the redacted httptools.ProxyID.
+var metrics = []service.Metric{
+	// Poller
+	{
+		Name: httptools.MetricUpbitNewsRequestsTotal,
+		Help: "Requests made by the pollers",
+		Kind: service.KindCounter,
+	},
+	{
+		Name: httptools.MetricUpbitNewsErrorsTotal,
+		Help: "Failed requests of the pollers",
+		Kind: service.KindCounter,
+	},
+	{
+		Name: httptools.MetricUpbitNewsFetchedTotal,
+		Help: "Responses received by the pollers",
+		Kind: service.KindCounter,
+	},
+	{
+		Name:    httptools.MetricUpbitNewsRequestDuration,
+		Help:    "Duration of a poller request in seconds, including the retries",
+		Kind:    service.KindHistogram,
+		Buckets: service.DurationBuckets,
+	},
+	{
+		Name:    httptools.MetricUpbitNewsPollDuration,
+		Help:    "Duration of a poll in seconds",
+		Kind:    service.KindHistogram,
+		Buckets: service.DurationBuckets,
+	},
+	{
+		Name:   httptools.MetricUpbitNewsRPSProfile,
+		Help:   "1 for the RPS profile of the current work window",
+		Kind:   service.KindGauge,
+		Labels: []string{"endpoint", "profile"},
+	},
+	{
+		Name:   httptools.MetricUpbitNewsTargetRPS,
+		Help:   "Effective target RPS of the poller",
+		Kind:   service.KindGauge,
+		Labels: []string{"endpoint"},
+	},
+	{
+		Name:   httptools.MetricUpbitNewsCacheStatusTotal,
+		Help:   "Responses by the cache-busting strategy and the CDN cache status",
+		Kind:   service.KindCounter,
+		Labels: []string{"strategy", "status"},
+	},
+	{
+		Name:    httptools.MetricUpbitNewsStaleness,
+		Help:    "Time since the origin generated the content of a response in seconds",
+		Kind:    service.KindHistogram,
+		Labels:  []string{"proxy", "endpoint"},
+		Buckets: httptools.StalenessBuckets,
+	},
+	{
+		Name:   httptools.MetricUpbitNewsClockOffset,
+		Help:   "Estimated server clock minus the local clock in seconds",
+		Kind:   service.KindGauge,
+		Labels: []string{"endpoint"},
+	},
+
+	// Clients
+	{
+		Name:    httptools.MetricUpbitRequestDuration,
+		Help:    "Duration of a request through the proxy in seconds",
+		Kind:    service.KindHistogram,
+		Labels:  []string{"proxy"},
+		Buckets: service.DurationBuckets,
+	},
+	{
+		Name:   httptools.MetricUpbitRequestsTotal,
+		Help:   "Requests through the proxy",
+		Kind:   service.KindCounter,
+		Labels: []string{"proxy"},
+	},
+	{
+		Name:   httptools.MetricUpbitErrorsTotal,
+		Help:   "Failed requests through the proxy by the failure type",
+		Kind:   service.KindCounter,
+		Labels: []string{"proxy", "type"},
+	},
+	{
+		Name:   httptools.MetricUpbitResponsesTotal,
+		Help:   "Responses through the proxy by the status",
+		Kind:   service.KindCounter,
+		Labels: []string{"proxy", "status_code", "status_class"},
+	},
+	{
+		Name:   httptools.MetricUpbitSuccessfulRequests,
+		Help:   "Responses with 200 OK through the proxy",
+		Kind:   service.KindCounter,
+		Labels: []string{"proxy"},
+	},
+	{
+		Name:   httptools.MetricUpbitRateLimitedTotal,
+		Help:   "Responses with 429 Too Many Requests through the proxy",
+		Kind:   service.KindCounter,
+		Labels: []string{"proxy"},
+	},
+
+	// Fetchers
+	{
+		Name:   core.MetricUpbitNewNewsDetectedTotal,
+		Help:   "New news emitted by the fetcher",
+		Kind:   service.KindCounter,
+		Labels: []string{"fetcher"},
+	},
+	{
+		Name:    core.MetricUpbitNewsParseDuration,
+		Help:    "Duration of parsing a response in seconds",
+		Kind:    service.KindHistogram,
+		Labels:  []string{"fetcher"},
+		Buckets: service.DurationBuckets,
+	},
+	{
+		Name:   core.MetricUpbitReconcileMissedTotal,
+		Help:   "Notices of the announcements list never emitted by the fetcher",
+		Kind:   service.KindCounter,
+		Labels: []string{"fetcher"},
+	},
+	{
+		Name:   core.MetricUpbitReconcileRepairsTotal,
+		Help:   "Cursors of the fetcher moved by the reconciliation",
+		Kind:   service.KindCounter,
+		Labels: []string{"fetcher"},
+	},
+	{
+		Name: core.MetricUpbitReconcileErrorsTotal,
+		Help: "Failed reconciliations",
+		Kind: service.KindCounter,
+	},
+
+	// Captures
+	{
+		Name:   capture.MetricCapturesTotal,
+		Help:   "Raw responses written to the capture store",
+		Kind:   service.KindCounter,
+		Labels: []string{"reason"},
+	},
+	{
+		Name:   capture.MetricCapturesDroppedTotal,
+		Help:   "Raw responses not captured because the queue was full or the write failed",
+		Kind:   service.KindCounter,
+		Labels: []string{"reason"},
+	},
+
+	// Detection
+	{
+		Name: core.MetricUnknownTickersTotal,
+		Help: "Tickers of listing news missing in the symbol registry",
+		Kind: service.KindCounter,
+	},
+	{
+		Name:    core.MetricDetectionLatency,
+		Help:    "Duration of a detection stage in seconds",
+		Kind:    service.KindHistogram,
+		Labels:  []string{"stage", "source", "venue", "listed_at"},
+		Buckets: core.DetectionLatencyBuckets,
+	},
+	{
+		Name:    gate.MetricGateOpenOrderDuration,
+		Help:    "Duration of opening an order on Gate in seconds",
+		Kind:    service.KindHistogram,
+		Buckets: service.DurationBuckets,
+	},
+}
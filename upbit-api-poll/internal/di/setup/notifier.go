@@ -0,0 +1,65 @@
+package setup
+
+import (
+	"log"
+	"log/slog"
+	"strings"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/notifier"
+	"github.com/mymmrac/telego"
+)
+
+func mustTelegram(cfg config.Config) *telego.Bot {
+	if !cfg.Telegram.Enabled {
+		return nil
+	}
+
+	token := strings.Join([]string{
+		cfg.Telegram.BotID,
+		cfg.Telegram.AuthorizationToken,
+	}, ":")
+
+	telegram, err := telego.NewBot(token)
+	if err != nil {
+		log.Panicf("Failed to create telegram bot: %v", err)
+	}
+
+	return telegram
+}
+
+func newNotifier(cfg config.Config, telegram *telego.Bot, logger *slog.Logger) *notifier.Registry {
+	backends := make([]notifier.Backend, 0, 4)
+
+	if telegram != nil {
+		backends = append(backends, notifier.NewTelegram(telegram, cfg.Telegram.GroupID))
+	}
+
+	if cfg.Notifier.Discord.Enabled {
+		backends = append(backends, notifier.NewDiscord(cfg.Notifier.Discord.WebhookURL))
+	}
+
+	if cfg.Notifier.Slack.Enabled {
+		backends = append(backends, notifier.NewSlack(cfg.Notifier.Slack.WebhookURL))
+	}
+
+	if cfg.Notifier.Webhook.Enabled {
+		backends = append(
+			backends,
+			notifier.NewWebhook(cfg.Notifier.Webhook.URL, cfg.Notifier.Webhook.Headers),
+		)
+	}
+
+	registry := notifier.NewRegistry(
+		cfg.Notifier.Source,
+		cfg.Notifier.Timeout,
+		cfg.Notifier.Queue,
+		logger,
+		backends...,
+	)
+	if len(backends) == 0 {
+		logger.Warn("No notification backends enabled, notifications are only logged")
+	}
+
+	return registry
+}
@@ -0,0 +1,57 @@
+package setup
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/core"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// mustTracing sets the global tracer provider exporting the spans, it returns nil when tracing is disabled
+func mustTracing(cfg config.Config) *sdktrace.TracerProvider {
+	if !cfg.Tracing.Enabled {
+		return nil
+	}
+
+	exporter, err := newSpanExporter(cfg.Tracing)
+	if err != nil {
+		log.Panicf("Failed to create span exporter: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(core.NewSampler(cfg.Tracing.SampleRatio)),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(cfg.Tracing.ServiceName),
+		)),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider
+}
+
+func newSpanExporter(cfg config.Tracing) (sdktrace.SpanExporter, error) {
+	if cfg.Exporter == config.TracingExporterStdout {
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	}
+
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+
+	// The client connects lazily, an unavailable collector doesn't stop the service
+	return otlptracegrpc.New(context.Background(), options...)
+}
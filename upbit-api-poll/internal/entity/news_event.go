@@ -0,0 +1,130 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	NewsSourceNoticeByID       = "notice_by_id"
+	NewsSourceAnnouncements    = "announcements"
+	NewsSourceAnnouncementByID = "announcement_by_id"
+	NewsSourceWebsocketSucker  = "websocket_sucker"
+)
+
+// NewsEvent is a single piece of news detected by one of the sources.
+type NewsEvent struct {
+	Title    NewsTitle `json:"title"`
+	Source   string    `json:"source"`
+	NoticeID int       `json:"notice_id,omitempty"`
+
+	// ListedAt is the publication time reported by Upbit, zero if the source doesn't provide it
+	ListedAt   time.Time `json:"listed_at"`
+	ReceivedAt time.Time `json:"received_at"`
+
+	// The times of the later stages of the detection, zero for the stages the event didn't reach
+	ClassifiedAt time.Time `json:"classified_at,omitzero"`
+	OrderSentAt  time.Time `json:"order_sent_at,omitzero"`
+	OrderAckedAt time.Time `json:"order_acked_at,omitzero"`
+	// Venue is the exchange the order was sent to
+	Venue string `json:"venue,omitempty"`
+
+	// Details are parsed from the notice body, nil if the source doesn't provide the body
+	Details *NoticeDetails `json:"details,omitempty"`
+	// PendingDetails delivers the details parsed after the event was emitted, nil if there are none to wait for.
+	// It yields nil if the body failed to parse.
+	PendingDetails <-chan *NoticeDetails `json:"-"`
+
+	// SpanContext is the span of the last stage the event passed, the parent of the next one
+	SpanContext trace.SpanContext `json:"-"`
+}
+
+// Stages of the detection of listing news, from the publication to the order acknowledgement
+const (
+	StageReceipt        = "receipt"
+	StageClassification = "classification"
+	StageOrderSend      = "order_send"
+	StageOrderAck       = "order_ack"
+	// StageTotal is the time from the publication, or the receipt if it's unknown, to the order acknowledgement
+	StageTotal = "total"
+)
+
+// StageLatency is the time an event spent in a stage of the detection
+type StageLatency struct {
+	Stage    string        `json:"stage"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Latencies returns the latencies of the stages the event passed, in the order of the stages
+func (e NewsEvent) Latencies() []StageLatency {
+	stages := []struct {
+		stage      string
+		start, end time.Time
+	}{
+		{StageReceipt, e.ListedAt, e.ReceivedAt},
+		{StageClassification, e.ReceivedAt, e.ClassifiedAt},
+		{StageOrderSend, e.ClassifiedAt, e.OrderSentAt},
+		{StageOrderAck, e.OrderSentAt, e.OrderAckedAt},
+	}
+
+	latencies := make([]StageLatency, 0, len(stages)+1)
+	for _, stage := range stages {
+		if stage.start.IsZero() || stage.end.IsZero() {
+			continue
+		}
+
+		latencies = append(latencies, StageLatency{Stage: stage.stage, Duration: stage.end.Sub(stage.start)})
+	}
+
+	if !e.OrderAckedAt.IsZero() {
+		start := e.ListedAt
+		if start.IsZero() {
+			start = e.ReceivedAt
+		}
+
+		latencies = append(latencies, StageLatency{Stage: StageTotal, Duration: e.OrderAckedAt.Sub(start)})
+	}
+
+	return latencies
+}
+
+// NoticeDetails is the structured data parsed from the body of a listing notice.
+type NoticeDetails struct {
+	TradingStartsAt   time.Time `json:"trading_starts_at"`
+	DepositOpensAt    time.Time `json:"deposit_opens_at"`
+	WithdrawalOpensAt time.Time `json:"withdrawal_opens_at"`
+	Networks          []string  `json:"networks,omitempty"`
+	ContractAddresses []string  `json:"contract_addresses,omitempty"`
+	Markets           []string  `json:"markets,omitempty"`
+	Body              string    `json:"body,omitempty"`
+}
+
+// AwaitDetails waits for the pending details and returns the event with them attached.
+// The event is returned as is if no details are pending or the context is done.
+func (e NewsEvent) AwaitDetails(ctx context.Context) NewsEvent {
+	if e.PendingDetails == nil {
+		return e
+	}
+
+	select {
+	case details, ok := <-e.PendingDetails:
+		if ok && details != nil {
+			e.Details = details
+		}
+		e.PendingDetails = nil
+	case <-ctx.Done():
+	}
+
+	return e
+}
+
+// TradingStartTime returns the announced trading start time if the event has one.
+func (e NewsEvent) TradingStartTime() (time.Time, bool) {
+	if e.Details == nil || e.Details.TradingStartsAt.IsZero() {
+		return time.Time{}, false
+	}
+
+	return e.Details.TradingStartsAt, true
+}
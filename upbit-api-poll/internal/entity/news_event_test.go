@@ -0,0 +1,83 @@
+package entity_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewsEvent_Latencies(t *testing.T) {
+	listedAt := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	event := entity.NewsEvent{
+		ListedAt:     listedAt,
+		ReceivedAt:   listedAt.Add(800 * time.Millisecond),
+		ClassifiedAt: listedAt.Add(802 * time.Millisecond),
+	}
+
+	assert.Equal(t, []entity.StageLatency{
+		{Stage: entity.StageReceipt, Duration: 800 * time.Millisecond},
+		{Stage: entity.StageClassification, Duration: 2 * time.Millisecond},
+	}, event.Latencies())
+
+	event.OrderSentAt = listedAt.Add(810 * time.Millisecond)
+	event.OrderAckedAt = listedAt.Add(900 * time.Millisecond)
+
+	assert.Equal(t, []entity.StageLatency{
+		{Stage: entity.StageReceipt, Duration: 800 * time.Millisecond},
+		{Stage: entity.StageClassification, Duration: 2 * time.Millisecond},
+		{Stage: entity.StageOrderSend, Duration: 8 * time.Millisecond},
+		{Stage: entity.StageOrderAck, Duration: 90 * time.Millisecond},
+		{Stage: entity.StageTotal, Duration: 900 * time.Millisecond},
+	}, event.Latencies())
+}
+
+func TestNewsEvent_Latencies_WithoutListedAt(t *testing.T) {
+	receivedAt := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	event := entity.NewsEvent{
+		ReceivedAt:   receivedAt,
+		ClassifiedAt: receivedAt.Add(time.Millisecond),
+		OrderSentAt:  receivedAt.Add(5 * time.Millisecond),
+		OrderAckedAt: receivedAt.Add(50 * time.Millisecond),
+	}
+
+	latencies := event.Latencies()
+
+	assert.Len(t, latencies, 4, "the receipt stage is unknown without the publication time")
+	assert.Equal(t, entity.StageLatency{Stage: entity.StageTotal, Duration: 50 * time.Millisecond}, latencies[3])
+}
+
+func TestNewsEvent_AwaitDetails(t *testing.T) {
+	startsAt := time.Date(2025, 10, 1, 18, 0, 0, 0, time.UTC)
+
+	pending := make(chan *entity.NoticeDetails, 1)
+	event := entity.NewsEvent{PendingDetails: pending}
+
+	_, ok := event.TradingStartTime()
+	assert.False(t, ok, "the details are not parsed yet")
+
+	pending <- &entity.NoticeDetails{TradingStartsAt: startsAt}
+	close(pending)
+
+	event = event.AwaitDetails(context.Background())
+
+	tradingStartsAt, ok := event.TradingStartTime()
+	assert.True(t, ok)
+	assert.Equal(t, startsAt, tradingStartsAt)
+	assert.Nil(t, event.PendingDetails)
+
+	failed := make(chan *entity.NoticeDetails, 1)
+	failed <- nil
+
+	assert.Nil(t, entity.NewsEvent{PendingDetails: failed}.AwaitDetails(context.Background()).Details)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	never := make(chan *entity.NoticeDetails)
+	assert.Nil(t, entity.NewsEvent{PendingDetails: never}.AwaitDetails(ctx).Details, "waiting stops with the context")
+}
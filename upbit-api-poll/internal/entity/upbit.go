@@ -32,6 +32,17 @@ type Notice struct {
 	ID            int       `json:"id"`
 	Category      string    `json:"category"`
 	Title         string    `json:"title"`
+	Body          string    `json:"body"`
 	ListedAt      time.Time `json:"listed_at"`
 	FirstListedAt time.Time `json:"first_listed_at"`
 }
+
+//easyjson:json
+type Markets []Market
+
+// Market is a single entry of Upbit's public market list (/v1/market/all).
+type Market struct {
+	Market      string `json:"market"`
+	KoreanName  string `json:"korean_name"`
+	EnglishName string `json:"english_name"`
+}
@@ -129,6 +129,8 @@ func easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiP
 			out.Category = string(in.String())
 		case "title":
 			out.Title = string(in.String())
+		case "body":
+			out.Body = string(in.String())
 		case "listed_at":
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.ListedAt).UnmarshalJSON(data))
@@ -166,6 +168,11 @@ func easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiP
 		out.RawString(prefix)
 		out.String(string(in.Title))
 	}
+	{
+		const prefix string = ",\"body\":"
+		out.RawString(prefix)
+		out.String(string(in.Body))
+	}
 	{
 		const prefix string = ",\"listed_at\":"
 		out.RawString(prefix)
@@ -202,7 +209,153 @@ func (v *Notice) UnmarshalJSON(data []byte) error {
 func (v *Notice) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity1(l, v)
 }
-func easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(in *jlexer.Lexer, out *Announcements) {
+func easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(in *jlexer.Lexer, out *Markets) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Markets, 0, 1)
+			} else {
+				*out = Markets{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 Market
+			(v1).UnmarshalEasyJSON(in)
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(out *jwriter.Writer, in Markets) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Markets) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Markets) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Markets) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Markets) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(l, v)
+}
+func easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity3(in *jlexer.Lexer, out *Market) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "market":
+			out.Market = string(in.String())
+		case "korean_name":
+			out.KoreanName = string(in.String())
+		case "english_name":
+			out.EnglishName = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity3(out *jwriter.Writer, in Market) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"market\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Market))
+	}
+	{
+		const prefix string = ",\"korean_name\":"
+		out.RawString(prefix)
+		out.String(string(in.KoreanName))
+	}
+	{
+		const prefix string = ",\"english_name\":"
+		out.RawString(prefix)
+		out.String(string(in.EnglishName))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Market) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Market) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Market) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Market) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity3(l, v)
+}
+func easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity4(in *jlexer.Lexer, out *Announcements) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -239,7 +392,7 @@ func easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiP
 		in.Consumed()
 	}
 }
-func easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(out *jwriter.Writer, in Announcements) {
+func easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity4(out *jwriter.Writer, in Announcements) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -269,25 +422,25 @@ func easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiP
 // MarshalJSON supports json.Marshaler interface
 func (v Announcements) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(&w, v)
+	easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Announcements) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(w, v)
+	easyjsonD1ca1c96EncodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Announcements) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(&r, v)
+	easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Announcements) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity2(l, v)
+	easyjsonD1ca1c96DecodeGithubComShadowWeb3DevelopmentStudioListingsUpbitApiPollInternalEntity4(l, v)
 }
 func easyjsonD1ca1c96Decode(in *jlexer.Lexer, out *struct {
 	TotalPages int      `json:"total_pages"`
@@ -332,9 +485,9 @@ func easyjsonD1ca1c96Decode(in *jlexer.Lexer, out *struct {
 					out.Notices = (out.Notices)[:0]
 				}
 				for !in.IsDelim(']') {
-					var v1 Notice
-					(v1).UnmarshalEasyJSON(in)
-					out.Notices = append(out.Notices, v1)
+					var v4 Notice
+					(v4).UnmarshalEasyJSON(in)
+					out.Notices = append(out.Notices, v4)
 					in.WantComma()
 				}
 				in.Delim(']')
@@ -374,11 +527,11 @@ func easyjsonD1ca1c96Encode(out *jwriter.Writer, in struct {
 			out.RawString("null")
 		} else {
 			out.RawByte('[')
-			for v2, v3 := range in.Notices {
-				if v2 > 0 {
+			for v5, v6 := range in.Notices {
+				if v5 > 0 {
 					out.RawByte(',')
 				}
-				(v3).MarshalEasyJSON(out)
+				(v6).MarshalEasyJSON(out)
 			}
 			out.RawByte(']')
 		}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -10,9 +11,25 @@ var (
 	ErrNoWorkSession  = errors.New("no work session found")
 )
 
+const (
+	timeOfDayLayout = "15:04"
+	dateLayout      = "2006-01-02"
+
+	// DefaultRPSProfile is the profile of the windows without a target RPS,
+	// the configured target RPS is polled with
+	DefaultRPSProfile = "default"
+
+	// sessionLookAheadDays bounds the search of the next work session, dated schedules
+	// can close the weekly windows for a while
+	sessionLookAheadDays = 366
+)
+
+// WorkSchedule is the weekly schedule of the work windows in the time zone.
+// Dated schedules replace the weekly one on their dates, e.g. on public holidays.
 type WorkSchedule struct {
-	TimeZone string                    `mapstructure:"time_zone" validate:"required"`
-	Schedule map[Weekday]DailySchedule `mapstructure:"schedule"  validate:"required,dive"`
+	TimeZone string                    `mapstructure:"time_zone" validate:"required,timezone"`
+	Schedule map[Weekday]DailySchedule `mapstructure:"schedule"  validate:"required,dive,keys,oneof=sunday monday tuesday wednesday thursday friday saturday,endkeys,required"`
+	Dates    []DateSchedule            `mapstructure:"dates"     validate:"dive"`
 }
 
 type Weekday string
@@ -79,10 +96,43 @@ func NewWeekdayFromInt(day int) (Weekday, error) {
 	return "", ErrInvalidWeekday
 }
 
+// DailySchedule holds the work windows of a day. The start and end times with the profile
+// are the first window, more windows can be listed. Every window is extended by the preparation
+// time on both sides.
 type DailySchedule struct {
-	StartTime       string        `mapstructure:"start_time"`
-	EndTime         string        `mapstructure:"end_time"`
-	PreparationTime time.Duration `mapstructure:"preparation_time"`
+	StartTime       string        `mapstructure:"start_time"       validate:"required_with=EndTime,omitempty,datetime=15:04"`
+	EndTime         string        `mapstructure:"end_time"         validate:"required_with=StartTime,omitempty,datetime=15:04"`
+	Profile         string        `mapstructure:"profile"`
+	TargetRPS       float64       `mapstructure:"target_rps"       validate:"gte=0"`
+	PreparationTime time.Duration `mapstructure:"preparation_time" validate:"gte=0"`
+	Windows         []TimeWindow  `mapstructure:"windows"          validate:"dive"`
+}
+
+// TimeWindow is a work window of a day. A window which ends at or before its start
+// ends on the next day: 22:00-02:00 crosses midnight, 00:00-00:00 lasts the whole day.
+// The target RPS is polled with within the window, zero keeps the configured one.
+// The profile names the window in metrics, the times are used if it's not set.
+type TimeWindow struct {
+	StartTime string  `mapstructure:"start_time" validate:"required,datetime=15:04"`
+	EndTime   string  `mapstructure:"end_time"   validate:"required,datetime=15:04"`
+	Profile   string  `mapstructure:"profile"`
+	TargetRPS float64 `mapstructure:"target_rps" validate:"gte=0"`
+}
+
+// DateSchedule replaces the weekly schedule on the date. A date without windows is a day off.
+type DateSchedule struct {
+	Date            string        `mapstructure:"date"             validate:"required,datetime=2006-01-02"`
+	Name            string        `mapstructure:"name"`
+	PreparationTime time.Duration `mapstructure:"preparation_time" validate:"gte=0"`
+	Windows         []TimeWindow  `mapstructure:"windows"          validate:"dive"`
+}
+
+// session is a work window on a date extended by the preparation time
+type session struct {
+	start     time.Time
+	end       time.Time
+	profile   string
+	targetRPS float64
 }
 
 // NewWorkSchedule creates a new WorkSchedule with a specified time zone
@@ -93,34 +143,30 @@ func NewWorkSchedule(timeZone string) *WorkSchedule {
 	}
 }
 
-// SetDailySchedule sets the schedule for a specific day
+// SetDailySchedule sets a single window for a specific day, the window ends on the next day
+// if the end time is not after the start time
 func (ws *WorkSchedule) SetDailySchedule(
 	day Weekday,
 	startTimeStr, endTimeStr string,
 	preparationTime time.Duration,
 ) {
-	startTime, err := time.Parse("15:04", startTimeStr)
+	startTime, err := time.Parse(timeOfDayLayout, startTimeStr)
 	if err != nil {
 		panic(err)
 	}
 
-	endTime, err := time.Parse("15:04", endTimeStr)
+	endTime, err := time.Parse(timeOfDayLayout, endTimeStr)
 	if err != nil {
 		panic(err)
 	}
 
-	if startTime.After(endTime) {
-		panic("start time is after end time")
-	}
-
-	loc, err := time.LoadLocation(ws.TimeZone)
-	if err != nil {
+	if _, err := time.LoadLocation(ws.TimeZone); err != nil {
 		panic(err)
 	}
 
 	ws.Schedule[day] = DailySchedule{
-		StartTime:       startTime.In(loc).Format("15:04"),
-		EndTime:         endTime.In(loc).Format("15:04"),
+		StartTime:       startTime.Format(timeOfDayLayout),
+		EndTime:         endTime.Format(timeOfDayLayout),
 		PreparationTime: preparationTime,
 	}
 }
@@ -145,110 +191,198 @@ func (ws *WorkSchedule) GetTimeZone() *time.Location {
 	return loc
 }
 
-func (we *WorkSchedule) WorkNow() bool {
-	now := time.Now().In(we.GetTimeZone())
-	day, err := NewWeekdayFromInt(int(now.Weekday()))
-	if err != nil {
-		panic(err)
+func (ws *WorkSchedule) WorkNow() bool {
+	return ws.WorkAt(time.Now())
+}
+
+// WorkAt reports whether the moment is within a work session. Sessions of the previous day
+// can last past midnight and sessions of the next day can start before it with the preparation.
+func (ws *WorkSchedule) WorkAt(moment time.Time) bool {
+	moment = moment.In(ws.GetTimeZone())
+
+	for offset := -1; offset <= 1; offset++ {
+		for _, session := range ws.sessions(moment.AddDate(0, 0, offset)) {
+			if session.contains(moment) {
+				return true
+			}
+		}
 	}
 
-	startTime, endTime, ok := we.getTimesWithPreparation(day)
-	if !ok {
-		return false
+	return false
+}
+
+// ProfileNow returns the name and the target RPS of the profile of the current work window
+func (ws *WorkSchedule) ProfileNow() (string, float64) {
+	return ws.ProfileAt(time.Now())
+}
+
+// ProfileAt returns the name and the target RPS of the profile of the work window at the moment.
+// Of the overlapping windows the one with the highest target RPS is used. Outside the windows
+// it's the default profile with zero target RPS.
+func (ws *WorkSchedule) ProfileAt(moment time.Time) (string, float64) {
+	moment = moment.In(ws.GetTimeZone())
+
+	var active *session
+
+	for offset := -1; offset <= 1; offset++ {
+		for _, session := range ws.sessions(moment.AddDate(0, 0, offset)) {
+			if session.contains(moment) && (active == nil || session.targetRPS > active.targetRPS) {
+				active = &session
+			}
+		}
 	}
 
-	return now.After(startTime) && now.Before(endTime)
+	if active == nil {
+		return DefaultRPSProfile, 0
+	}
+
+	return active.profile, active.targetRPS
 }
 
+// NextWorkSession returns the time left till the next work session, zero within a session
 func (ws *WorkSchedule) NextWorkSession() (time.Duration, error) {
-	now := time.Now().In(ws.GetTimeZone())
-	dayIndex := int(now.Weekday())
-	day := Weekdays[dayIndex]
+	return ws.NextWorkSessionAt(time.Now())
+}
+
+// NextWorkSessionAt returns the time left from the moment till the next work session,
+// zero if the moment is within a session
+func (ws *WorkSchedule) NextWorkSessionAt(moment time.Time) (time.Duration, error) {
+	if ws.WorkAt(moment) {
+		return 0, nil
+	}
 
-	for daysChecked := 0; daysChecked < len(Weekdays); daysChecked++ {
+	moment = moment.In(ws.GetTimeZone())
 
-		startTime, endTime, ok := ws.getTimesWithPreparation(day)
+	var next time.Time
 
-		// Situation 0: we are on a non-work day
-		if !ok {
-			day = day.Next()
-			continue
-		}
+	for offset := -1; offset <= sessionLookAheadDays; offset++ {
+		date := moment.AddDate(0, 0, offset)
 
-		// Situation 1: we are in a work session
-		if now.After(startTime) && now.Before(endTime) {
-			return 0, nil
+		// A session of a later date can start earlier only with a preparation time longer than
+		// a day, the dates are checked till the day after the found session
+		if !next.IsZero() && date.After(next.AddDate(0, 0, 1)) {
+			break
 		}
 
-		// Situation 2: we are on a work day but before the start time
-		if now.Before(startTime) {
-			return startTime.Sub(now), nil
+		for _, session := range ws.sessions(date) {
+			if session.start.After(moment) && (next.IsZero() || session.start.Before(next)) {
+				next = session.start
+			}
 		}
-		// Situation 3: we are on a work day but after the end time
-		if now.After(endTime) {
-			return endTime.Sub(now), nil
+	}
+
+	if next.IsZero() {
+		return 0, ErrNoWorkSession
+	}
+
+	return next.Sub(moment), nil
+}
+
+// sessions returns the work sessions starting on the date of the moment,
+// from the dated schedule of the date or from the weekly one
+func (ws *WorkSchedule) sessions(moment time.Time) []session {
+	loc := moment.Location()
+	year, month, day := moment.Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+
+	windows, preparationTime, ok := ws.windowsOn(date)
+	if !ok {
+		return nil
+	}
+
+	sessions := make([]session, 0, len(windows))
+
+	for _, window := range windows {
+		start, end, err := window.times(date)
+		if err != nil {
+			panic(err)
 		}
+
+		sessions = append(sessions, session{
+			start:     start.Add(-preparationTime),
+			end:       end.Add(preparationTime),
+			profile:   window.ProfileName(),
+			targetRPS: window.TargetRPS,
+		})
 	}
 
-	return 0, ErrNoWorkSession
+	return sessions
 }
 
-func (ws *WorkSchedule) getTimes(day Weekday) (time.Time, time.Time, bool) {
-	schedule, exists := ws.GetDailySchedule(day)
-	if !exists {
-		return time.Time{}, time.Time{}, false
+func (ws *WorkSchedule) windowsOn(date time.Time) ([]TimeWindow, time.Duration, bool) {
+	for _, dated := range ws.Dates {
+		if dated.Date == date.Format(dateLayout) {
+			return dated.Windows, dated.PreparationTime, true
+		}
 	}
 
-	loc, err := time.LoadLocation(ws.TimeZone)
+	day, err := NewWeekdayFromInt(int(date.Weekday()))
 	if err != nil {
 		panic(err)
 	}
 
-	now := time.Now().In(loc)
+	schedule, ok := ws.GetDailySchedule(day)
+	if !ok {
+		return nil, 0, false
+	}
+
+	return schedule.AllWindows(), schedule.PreparationTime, true
+}
+
+// AllWindows returns the first window set with the start and end times and the listed windows
+func (ds DailySchedule) AllWindows() []TimeWindow {
+	windows := make([]TimeWindow, 0, len(ds.Windows)+1)
+
+	if ds.StartTime != "" || ds.EndTime != "" {
+		windows = append(windows, TimeWindow{
+			StartTime: ds.StartTime,
+			EndTime:   ds.EndTime,
+			Profile:   ds.Profile,
+			TargetRPS: ds.TargetRPS,
+		})
+	}
+
+	return append(windows, ds.Windows...)
+}
+
+// ProfileName returns the profile of the window: the set one, the times of the window
+// with a target RPS or the default profile
+func (w TimeWindow) ProfileName() string {
+	switch {
+	case w.Profile != "":
+		return w.Profile
+	case w.TargetRPS > 0:
+		return w.StartTime + "-" + w.EndTime
+	default:
+		return DefaultRPSProfile
+	}
+}
 
-	startTime, err := time.ParseInLocation("15:04", schedule.StartTime, loc)
+// times returns the start and the end of the window starting on the date
+func (w TimeWindow) times(date time.Time) (time.Time, time.Time, error) {
+	startTime, err := time.Parse(timeOfDayLayout, w.StartTime)
 	if err != nil {
-		panic(err)
+		return time.Time{}, time.Time{}, fmt.Errorf("parse start time: %w", err)
 	}
 
-	endTime, err := time.ParseInLocation("15:04", schedule.EndTime, loc)
+	endTime, err := time.Parse(timeOfDayLayout, w.EndTime)
 	if err != nil {
-		panic(err)
+		return time.Time{}, time.Time{}, fmt.Errorf("parse end time: %w", err)
 	}
 
-	startTime = time.Date(
-		now.Year(),
-		now.Month(),
-		now.Day(),
-		startTime.Hour(),
-		startTime.Minute(),
-		0,
-		0,
-		loc,
-	)
-	endTime = time.Date(
-		now.Year(),
-		now.Month(),
-		now.Day(),
-		endTime.Hour(),
-		endTime.Minute(),
-		0,
-		0,
-		loc,
-	)
-
-	return startTime, endTime, true
-}
-
-func (ws *WorkSchedule) getTimesWithPreparation(day Weekday) (time.Time, time.Time, bool) {
-	startTime, endTime, ok := ws.getTimes(day)
-	if !ok {
-		return time.Time{}, time.Time{}, false
+	year, month, day := date.Date()
+	loc := date.Location()
+
+	start := time.Date(year, month, day, startTime.Hour(), startTime.Minute(), 0, 0, loc)
+	end := time.Date(year, month, day, endTime.Hour(), endTime.Minute(), 0, 0, loc)
+
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
 	}
 
-	preparationTime := ws.Schedule[day].PreparationTime
-	startTime = startTime.Add(-preparationTime)
-	endTime = endTime.Add(preparationTime)
+	return start, end, nil
+}
 
-	return startTime, endTime, true
+func (s session) contains(moment time.Time) bool {
+	return !moment.Before(s.start) && moment.Before(s.end)
 }
@@ -0,0 +1,223 @@
+package messages
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools"
+)
+
+// Message templates. Every template has variants named <template>.<mode>.<language>.tmpl,
+// the default language is used when there is no variant for the configured one.
+const (
+	TemplateNotice           = "notice"
+	TemplateAnnouncement     = "announcement"
+	TemplateWebsocketListing = "websocket_listing"
+	TemplateListing          = "listing"
+	TemplateOrder            = "order"
+	TemplateTooManyRequests  = "too_many_requests"
+)
+
+const (
+	ModeCompact = "compact"
+	ModeVerbose = "verbose"
+
+	DefaultLanguage = "en"
+
+	templateExtension = ".tmpl"
+)
+
+var ErrTemplateNotFound = errors.New("message template not found")
+
+//go:embed templates/*.tmpl
+var embeddedTemplates embed.FS
+
+var templateNames = []string{
+	TemplateNotice,
+	TemplateAnnouncement,
+	TemplateWebsocketListing,
+	TemplateListing,
+	TemplateOrder,
+	TemplateTooManyRequests,
+}
+
+// Config selects the variant of the templates and the directory with templates overriding the embedded ones
+type Config struct {
+	Language     string `mapstructure:"language"      validate:"required"                   env:"NOTIFIER_MESSAGES_LANGUAGE"`
+	Mode         string `mapstructure:"mode"          validate:"required,oneof=compact verbose" env:"NOTIFIER_MESSAGES_MODE"`
+	TemplatesDir string `mapstructure:"templates_dir"                                       env:"NOTIFIER_MESSAGES_TEMPLATES_DIR"`
+}
+
+// NewsData is rendered by the notice and announcement templates
+type NewsData struct {
+	Event         entity.NewsEvent
+	Category      string
+	FirstListedAt time.Time
+	Link          string
+	Response      httptools.Response
+}
+
+// WebsocketListingData is rendered by the websocket listing template
+type WebsocketListingData struct {
+	Title    string
+	Exchange string
+	URL      string
+	Time     time.Time
+	Tickers  string
+}
+
+// ListingData is rendered by the listing template
+type ListingData struct {
+	Event entity.NewsEvent
+	// TradingStartsAt is zero if the event has no announced trading start time
+	TradingStartsAt time.Time
+	Tickers         []TickerStatus
+	Scheduled       []ScheduledAction
+	// Latencies are the stages the event passed until the classification
+	Latencies []entity.StageLatency
+}
+
+// OrderData is rendered by the order template after the venue answered
+type OrderData struct {
+	Event  entity.NewsEvent
+	Ticker string
+	// Error is empty if the order was acknowledged
+	Error     string
+	Latencies []entity.StageLatency
+}
+
+// TooManyRequestsData is rendered by the too many requests template when a proxy is rate limited
+type TooManyRequestsData struct {
+	Response httptools.Response
+	// BannedProxies is the number of the proxies rate limited within the last hour
+	BannedProxies int
+}
+
+type TickerStatus struct {
+	Ticker     string
+	KoreanName string
+	Known      bool
+	Tradable   bool
+	// Uncertain tickers were matched by an approximate asset name, they are alerted only
+	Uncertain bool
+}
+
+type ScheduledAction struct {
+	Action string
+	Ticker string
+	RunAt  time.Time
+}
+
+// Renderer renders notification messages as Telegram HTML. Values are HTML escaped.
+type Renderer struct {
+	templates *template.Template
+	language  string
+	mode      string
+}
+
+// New parses the embedded templates and the overriding ones from the configured directory
+// and checks that every template exists in the configured mode.
+func New(config Config) (*Renderer, error) {
+	templates, err := template.New("messages").Funcs(funcs).ParseFS(embeddedTemplates, "templates/*"+templateExtension)
+	if err != nil {
+		return nil, fmt.Errorf("parse embedded templates: %w", err)
+	}
+
+	if config.TemplatesDir != "" {
+		paths, err := filepath.Glob(filepath.Join(config.TemplatesDir, "*"+templateExtension))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			text, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := templates.New(filepath.Base(path)).Parse(string(text)); err != nil {
+				return nil, fmt.Errorf("parse template %s: %w", path, err)
+			}
+		}
+	}
+
+	r := &Renderer{
+		templates: templates,
+		language:  config.Language,
+		mode:      config.Mode,
+	}
+
+	for _, name := range templateNames {
+		if _, err := r.lookup(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Render renders the template in the configured mode and language.
+func (r *Renderer) Render(name string, data any) (string, error) {
+	tmpl, err := r.lookup(name)
+	if err != nil {
+		return "", err
+	}
+
+	builder := strings.Builder{}
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("render %s: %w", tmpl.Name(), err)
+	}
+
+	return strings.TrimSpace(builder.String()), nil
+}
+
+func (r *Renderer) lookup(name string) (*template.Template, error) {
+	for _, language := range []string{r.language, DefaultLanguage} {
+		if tmpl := r.templates.Lookup(variant(name, r.mode, language)); tmpl != nil {
+			return tmpl, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, variant(name, r.mode, r.language))
+}
+
+func variant(name, mode, language string) string {
+	return strings.Join([]string{name, mode, language}, ".") + templateExtension
+}
+
+var funcs = template.FuncMap{
+	// time formats a time with minute precision, "unknown" for the zero time
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "unknown"
+		}
+
+		return t.Format("2006-01-02 15:04 MST")
+	},
+	// timestamp formats a time with millisecond precision
+	"timestamp": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04:05.000")
+	},
+	"sub": func(a, b time.Time) time.Duration {
+		return a.Sub(b)
+	},
+	// duration formats a duration with millisecond precision
+	"duration": func(d time.Duration) string {
+		return d.Round(time.Millisecond).String()
+	},
+	"list": func(values []string) string {
+		if len(values) == 0 {
+			return "unknown"
+		}
+
+		return strings.Join(values, ", ")
+	},
+	"redact": httptools.RedactProxyAddress,
+}
@@ -0,0 +1,236 @@
+package notice
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+)
+
+// maxStoredBodyLength limits the body kept in the details, enough for any listing notice
+const maxStoredBodyLength = 8 * 1024
+
+// KST is the time zone of all times in Upbit notices
+var KST = time.FixedZone("KST", 9*60*60)
+
+var (
+	// 2025-06-05 18:00, 2025.06.05 18:00, 2025년 6월 5일 (목) 오후 6시, 6월 5일 18:00
+	dateTimePattern = regexp.MustCompile(
+		`(?:(\d{4})\s*[-./년]\s*)?(\d{1,2})\s*[-./월]\s*(\d{1,2})\s*일?\s*(?:\([^)]*\)\s*)?` +
+			`(오전|오후|AM|PM|am|pm)?\s*(\d{1,2})\s*(?::\s*(\d{2})|시(?:\s*(\d{1,2})\s*분)?)`,
+	)
+
+	evmAddressPattern     = regexp.MustCompile(`0x[0-9a-fA-F]{40}`)
+	genericAddressPattern = regexp.MustCompile(`[0-9A-Za-z_-]{26,70}`)
+	marketPattern         = regexp.MustCompile(`\b(KRW|BTC|USDT)\b`)
+)
+
+var (
+	tradingStartLabels       = []string{"거래지원 개시", "거래지원개시", "거래 개시", "거래지원 시작", "trading start", "trading opens", "trading will open"}
+	depositAndWithdrawLabels = []string{"입출금", "deposit and withdrawal", "deposits and withdrawals"}
+	depositLabels            = []string{"입금", "deposit"}
+	withdrawLabels           = []string{"출금", "withdraw"}
+	networkLabels            = []string{"네트워크", "network"}
+	contractLabels           = []string{"컨트랙트", "contract"}
+	marketLabels             = []string{"마켓", "market"}
+)
+
+// ParsePage extracts the notice body from the notice page and parses it into structured data.
+// Times without a year get the year of the reference time.
+func ParsePage(page []byte, reference time.Time) (entity.NoticeDetails, error) {
+	body, err := ExtractBody(page)
+	if err != nil {
+		return entity.NoticeDetails{}, err
+	}
+
+	return ParseText(body, reference), nil
+}
+
+// ParseHTML parses an HTML notice body, as returned by the announcements API.
+func ParseHTML(body string, reference time.Time) entity.NoticeDetails {
+	return ParseText(HTMLToText(body), reference)
+}
+
+// ParseText parses a plain text notice body into structured data.
+func ParseText(text string, reference time.Time) entity.NoticeDetails {
+	details := entity.NoticeDetails{}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lower := strings.ToLower(line)
+
+		switch {
+		case containsAny(lower, tradingStartLabels):
+			setTimeOnce(&details.TradingStartsAt, lineTime(lines, i, reference))
+
+		case containsAny(lower, depositAndWithdrawLabels):
+			at := lineTime(lines, i, reference)
+			setTimeOnce(&details.DepositOpensAt, at)
+			setTimeOnce(&details.WithdrawalOpensAt, at)
+
+		case containsAny(lower, depositLabels):
+			setTimeOnce(&details.DepositOpensAt, lineTime(lines, i, reference))
+
+		case containsAny(lower, withdrawLabels):
+			setTimeOnce(&details.WithdrawalOpensAt, lineTime(lines, i, reference))
+		}
+
+		if containsAny(lower, networkLabels) {
+			details.Networks = appendUnique(details.Networks, labelValues(line)...)
+		}
+
+		if containsAny(lower, contractLabels) {
+			if value, ok := labelValue(line); ok {
+				details.ContractAddresses = appendUnique(
+					details.ContractAddresses,
+					genericAddressPattern.FindAllString(value, -1)...,
+				)
+			}
+		}
+
+		details.ContractAddresses = appendUnique(
+			details.ContractAddresses,
+			evmAddressPattern.FindAllString(line, -1)...,
+		)
+
+		if containsAny(lower, marketLabels) {
+			details.Markets = appendUnique(details.Markets, marketPattern.FindAllString(line, -1)...)
+		}
+	}
+
+	if len(text) > maxStoredBodyLength {
+		text = text[:maxStoredBodyLength]
+	}
+	details.Body = text
+
+	return details
+}
+
+// ParseTime parses the first date and time found in the text, see dateTimePattern.
+func ParseTime(text string, reference time.Time) (time.Time, bool) {
+	match := dateTimePattern.FindStringSubmatch(text)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	reference = reference.In(KST)
+
+	year := reference.Year()
+	explicitYear := match[1] != ""
+	if explicitYear {
+		year, _ = strconv.Atoi(match[1])
+	}
+
+	month, _ := strconv.Atoi(match[2])
+	day, _ := strconv.Atoi(match[3])
+	hour, _ := strconv.Atoi(match[5])
+
+	minute := 0
+	switch {
+	case match[6] != "":
+		minute, _ = strconv.Atoi(match[6])
+	case match[7] != "":
+		minute, _ = strconv.Atoi(match[7])
+	}
+
+	switch strings.ToUpper(match[4]) {
+	case "오후", "PM":
+		if hour < 12 {
+			hour += 12
+		}
+	case "오전", "AM":
+		if hour == 12 {
+			hour = 0
+		}
+	}
+
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 24 || minute > 59 {
+		return time.Time{}, false
+	}
+
+	parsed := time.Date(year, time.Month(month), day, hour, minute, 0, 0, KST)
+
+	// A notice published in late December may announce a date in January
+	if !explicitYear && parsed.Before(reference.AddDate(0, -6, 0)) {
+		parsed = parsed.AddDate(1, 0, 0)
+	}
+
+	return parsed, true
+}
+
+// lineTime parses the time on the labelled line or, if the label stands alone, on the next line.
+func lineTime(lines []string, i int, reference time.Time) time.Time {
+	if at, ok := ParseTime(lines[i], reference); ok {
+		return at
+	}
+
+	if i+1 < len(lines) {
+		if at, ok := ParseTime(lines[i+1], reference); ok {
+			return at
+		}
+	}
+
+	return time.Time{}
+}
+
+func setTimeOnce(target *time.Time, value time.Time) {
+	if target.IsZero() {
+		*target = value
+	}
+}
+
+// labelValue returns the part of a "label : value" line after the colon.
+func labelValue(line string) (string, bool) {
+	_, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", false
+	}
+
+	value = strings.TrimSpace(value)
+
+	return value, value != ""
+}
+
+// labelValues splits the value of a "label : a, b / c" line into items.
+func labelValues(line string) []string {
+	value, ok := labelValue(line)
+	if !ok {
+		return nil
+	}
+
+	items := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '/' || r == '·'
+	})
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+
+	return values
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func appendUnique(values []string, items ...string) []string {
+	for _, item := range items {
+		if !slices.Contains(values, item) {
+			values = append(values, item)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,120 @@
+package notice_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/notice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const noticePage = `<!DOCTYPE html>
+<html>
+<head>
+<meta name="description" content="소폰(SOPH) 신규 거래지원 안내 (KRW, BTC, USDT 마켓)" />
+<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"notice":{"id":5123,
+"listed_at":"2025-05-28T16:00:12+09:00","first_listed_at":"2025-05-28T16:00:00+09:00",
+"title":"소폰(SOPH) 신규 거래지원 안내 (KRW, BTC, USDT 마켓)",
+"body":"<p>1. 거래지원 개시 일정</p><ul><li>거래지원 개시 시점 : 2025년 5월 28일 (수) 오후 6시 예정</li><li>입출금 개시 시점 : 공지 후 2시간 이내</li></ul><p>2. 거래지원 자산 정보</p><ul><li>지원 마켓 : KRW, BTC, USDT 마켓</li><li>지원 네트워크 : Ethereum, zkSync Era</li><li>컨트랙트 주소 : 0x6B7774CB12ed7573a7586E7D0e62a2A563dDd3f0</li></ul>"}}}}</script>
+</head>
+<body><div id="root"></div></body>
+</html>`
+
+func TestParseTitle(t *testing.T) {
+	title, err := notice.ParseTitle([]byte(noticePage))
+	require.NoError(t, err)
+	assert.Equal(t, "소폰(SOPH) 신규 거래지원 안내 (KRW, BTC, USDT 마켓)", title)
+
+	_, err = notice.ParseTitle([]byte("<html></html>"))
+	assert.ErrorIs(t, err, notice.ErrDescriptionNotFound)
+}
+
+func TestParseListedAt(t *testing.T) {
+	listedAt, err := notice.ParseListedAt([]byte(noticePage))
+	require.NoError(t, err)
+	assert.True(t, time.Date(2025, 5, 28, 16, 0, 12, 0, notice.KST).Equal(listedAt))
+
+	ldJSON := `<script type="application/ld+json">{"@type":"Article","datePublished": "2025-06-05T07:00:00Z"}</script>`
+	listedAt, err = notice.ParseListedAt([]byte(ldJSON))
+	require.NoError(t, err)
+	assert.True(t, time.Date(2025, 6, 5, 7, 0, 0, 0, time.UTC).Equal(listedAt))
+
+	_, err = notice.ParseListedAt([]byte(`<html><body>2025-06-05 16:00</body></html>`))
+	assert.ErrorIs(t, err, notice.ErrListedAtNotFound)
+}
+
+func TestParsePage_EmbeddedData(t *testing.T) {
+	reference := time.Date(2025, 5, 28, 16, 0, 0, 0, notice.KST)
+
+	details, err := notice.ParsePage([]byte(noticePage), reference)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2025, 5, 28, 18, 0, 0, 0, notice.KST), details.TradingStartsAt)
+	assert.True(t, details.DepositOpensAt.IsZero())
+	assert.Equal(t, []string{"KRW", "BTC", "USDT"}, details.Markets)
+	assert.Equal(t, []string{"Ethereum", "zkSync Era"}, details.Networks)
+	assert.Equal(
+		t,
+		[]string{"0x6B7774CB12ed7573a7586E7D0e62a2A563dDd3f0"},
+		details.ContractAddresses,
+	)
+	assert.Contains(t, details.Body, "거래지원 개시 시점")
+}
+
+func TestParsePage_RenderedHTML(t *testing.T) {
+	page := `<html><head><meta name="description" content="플록(FLOCK) 신규 거래지원 안내" /></head>
+<body><div class="notice">
+<p>거래지원 개시 시점</p><p>06/05 18:30 KST</p>
+<p>입금 개시 : 2025-06-05 16:00</p>
+<p>출금 개시 : 2025-06-05 17:00</p>
+<p>지원 네트워크 : Solana</p>
+<p>컨트랙트 주소 : 5ToU4PkzsgHmJdosgjdL8Ks4axYHbZ8BFmkWztNN9WuN</p>
+<p>마켓 : BTC, USDT 마켓</p>
+<script>var x = "2099-01-01 00:00";</script>
+</div></body></html>`
+
+	reference := time.Date(2025, 6, 5, 15, 0, 0, 0, notice.KST)
+
+	details, err := notice.ParsePage([]byte(page), reference)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2025, 6, 5, 18, 30, 0, 0, notice.KST), details.TradingStartsAt)
+	assert.Equal(t, time.Date(2025, 6, 5, 16, 0, 0, 0, notice.KST), details.DepositOpensAt)
+	assert.Equal(t, time.Date(2025, 6, 5, 17, 0, 0, 0, notice.KST), details.WithdrawalOpensAt)
+	assert.Equal(t, []string{"Solana"}, details.Networks)
+	assert.Equal(t, []string{"5ToU4PkzsgHmJdosgjdL8Ks4axYHbZ8BFmkWztNN9WuN"}, details.ContractAddresses)
+	assert.Equal(t, []string{"BTC", "USDT"}, details.Markets)
+	assert.NotContains(t, details.Body, "2099")
+}
+
+func TestParsePage_NoBody(t *testing.T) {
+	_, err := notice.ParsePage([]byte(`<meta name="description" content="x" />`), time.Now())
+	assert.ErrorIs(t, err, notice.ErrBodyNotFound)
+}
+
+func TestParseTime(t *testing.T) {
+	reference := time.Date(2025, 12, 30, 12, 0, 0, 0, notice.KST)
+
+	testCases := []struct {
+		text     string
+		expected time.Time
+		ok       bool
+	}{
+		{"2025-06-05 18:00", time.Date(2025, 6, 5, 18, 0, 0, 0, notice.KST), true},
+		{"2025.06.05 18:00 (KST)", time.Date(2025, 6, 5, 18, 0, 0, 0, notice.KST), true},
+		{"2025년 6월 5일 (목) 오후 6시 30분", time.Date(2025, 6, 5, 18, 30, 0, 0, notice.KST), true},
+		{"오전 12시", time.Time{}, false},
+		{"12월 31일 오전 12:00", time.Date(2025, 12, 31, 0, 0, 0, 0, notice.KST), true},
+		{"1월 2일 10:00", time.Date(2026, 1, 2, 10, 0, 0, 0, notice.KST), true},
+		{"공지 후 2시간 이내", time.Time{}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			got, ok := notice.ParseTime(tc.text, reference)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package notice
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrDescriptionNotFound    = errors.New("description meta tag not found")
+	ErrDescriptionEndNotFound = errors.New("end of description content not found")
+	ErrBodyNotFound           = errors.New("notice body not found")
+	ErrListedAtNotFound       = errors.New("notice publication time not found")
+)
+
+var (
+	metaDescriptionStart = []byte(`<meta name="description" content="`)
+	metaDescriptionEnd   = []byte(`" />`)
+
+	nextDataPattern = regexp.MustCompile(`(?s)<script id="__NEXT_DATA__"[^>]*>(.*?)</script>`)
+	ldJSONPattern   = regexp.MustCompile(`(?s)<script type="application/ld\+json"[^>]*>(.*?)</script>`)
+	htmlBodyPattern = regexp.MustCompile(`(?is)<body[^>]*>(.*)</body>`)
+	// listedAtPatterns find the publication time in the embedded page data, the API field first
+	listedAtPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`"listed_at"\s*:\s*"([^"]+)"`),
+		regexp.MustCompile(`"datePublished"\s*:\s*"([^"]+)"`),
+	}
+
+	scriptPattern     = regexp.MustCompile(`(?is)<(script|style|noscript)[^>]*>.*?</(script|style|noscript)>`)
+	lineBreakPattern  = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6]|table|ul|ol)>`)
+	tagPattern        = regexp.MustCompile(`<[^>]*>`)
+	horizontalSpacing = regexp.MustCompile(`[ \t\x{00a0}]+`)
+)
+
+// bodyKeys are the keys of the embedded page data that may hold the notice body
+var bodyKeys = []string{"body", "content", "contents", "articleBody"}
+
+// ParseTitle extracts the notice title from the description meta tag of the notice page.
+// It is used on every polled response, so it only scans for the tag without parsing the page.
+func ParseTitle(page []byte) (string, error) {
+	startIndex := bytes.Index(page, metaDescriptionStart)
+	if startIndex == -1 {
+		return "", ErrDescriptionNotFound
+	}
+
+	startIndex += len(metaDescriptionStart)
+	endIndex := bytes.Index(page[startIndex:], metaDescriptionEnd)
+	if endIndex == -1 {
+		return "", ErrDescriptionEndNotFound
+	}
+
+	return string(page[startIndex : startIndex+endIndex]), nil
+}
+
+// ParseListedAt extracts the publication time of the notice from the embedded page data.
+// It is used on new notices only, before their body is parsed.
+func ParseListedAt(page []byte) (time.Time, error) {
+	for _, pattern := range listedAtPatterns {
+		match := pattern.FindSubmatch(page)
+		if match == nil {
+			continue
+		}
+
+		if listedAt, err := time.Parse(time.RFC3339Nano, string(match[1])); err == nil {
+			return listedAt, nil
+		}
+	}
+
+	return time.Time{}, ErrListedAtNotFound
+}
+
+// ExtractBody returns the plain text of the notice body from the notice page.
+// The body is looked up in the embedded page data first and in the rendered HTML after.
+func ExtractBody(page []byte) (string, error) {
+	for _, pattern := range []*regexp.Regexp{nextDataPattern, ldJSONPattern} {
+		match := pattern.FindSubmatch(page)
+		if match == nil {
+			continue
+		}
+
+		var data any
+		if err := json.Unmarshal(match[1], &data); err != nil {
+			continue
+		}
+
+		if body := longestBody(data); body != "" {
+			return HTMLToText(body), nil
+		}
+	}
+
+	match := htmlBodyPattern.FindSubmatch(page)
+	if match == nil {
+		return "", ErrBodyNotFound
+	}
+
+	text := HTMLToText(string(match[1]))
+	if text == "" {
+		return "", ErrBodyNotFound
+	}
+
+	return text, nil
+}
+
+// HTMLToText converts an HTML fragment to plain text keeping line structure.
+func HTMLToText(fragment string) string {
+	fragment = scriptPattern.ReplaceAllString(fragment, "")
+	fragment = lineBreakPattern.ReplaceAllString(fragment, "\n")
+	fragment = tagPattern.ReplaceAllString(fragment, "")
+	fragment = html.UnescapeString(fragment)
+
+	lines := strings.Split(fragment, "\n")
+	result := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		line = strings.TrimSpace(horizontalSpacing.ReplaceAllString(line, " "))
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// longestBody walks the decoded page data and returns the longest string stored under one of bodyKeys.
+func longestBody(data any) string {
+	longest := ""
+
+	switch value := data.(type) {
+	case map[string]any:
+		for key, nested := range value {
+			if s, ok := nested.(string); ok {
+				for _, bodyKey := range bodyKeys {
+					if key == bodyKey && len(s) > len(longest) {
+						longest = s
+					}
+				}
+
+				continue
+			}
+
+			if s := longestBody(nested); len(s) > len(longest) {
+				longest = s
+			}
+		}
+
+	case []any:
+		for _, nested := range value {
+			if s := longestBody(nested); len(s) > len(longest) {
+				longest = s
+			}
+		}
+	}
+
+	return longest
+}
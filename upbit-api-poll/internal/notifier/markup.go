@@ -0,0 +1,31 @@
+package notifier
+
+import (
+	"html"
+	"regexp"
+)
+
+var (
+	boldPattern = regexp.MustCompile(`(?i)</?(b|strong)>`)
+	codePattern = regexp.MustCompile(`(?i)</?(code|pre)>`)
+	linkPattern = regexp.MustCompile(`(?i)<a\s+href="([^"]*)"\s*>(.*?)</a>`)
+	tagPattern  = regexp.MustCompile(`<[^>]*>`)
+)
+
+// toMarkdown converts Telegram HTML to markdown with the given bold marker.
+func toMarkdown(message, bold string) string {
+	message = boldPattern.ReplaceAllString(message, bold)
+	message = codePattern.ReplaceAllString(message, "`")
+	message = linkPattern.ReplaceAllString(message, "$2 ($1)")
+	message = tagPattern.ReplaceAllString(message, "")
+
+	return html.UnescapeString(message)
+}
+
+// toText converts Telegram HTML to plain text.
+func toText(message string) string {
+	message = linkPattern.ReplaceAllString(message, "$2 ($1)")
+	message = tagPattern.ReplaceAllString(message, "")
+
+	return html.UnescapeString(message)
+}
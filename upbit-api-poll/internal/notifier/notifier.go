@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Backend delivers a message to one destination. Messages are formatted as Telegram HTML,
+// backends with another markup convert them.
+// Backends returning RetryAfterError are retried after the requested time.
+type Backend interface {
+	Name() string
+	Send(ctx context.Context, message string) error
+}
+
+// Alerter sends messages which are delivered ahead of all other queued messages
+type Alerter interface {
+	SendAlert(format string, args ...any)
+}
+
+// Registry fans out every message to the delivery queues of all registered backends.
+// It implements httptools.Notifier.
+type Registry struct {
+	source string
+	queues []*queue
+	logger *slog.Logger
+}
+
+// NewRegistry creates a registry for the backends and starts their delivery queues.
+// The source is put in the header of every message. Without backends messages are only logged.
+func NewRegistry(
+	source string,
+	timeout time.Duration,
+	queueConfig QueueConfig,
+	logger *slog.Logger,
+	backends ...Backend,
+) *Registry {
+	r := &Registry{
+		source: source,
+		queues: make([]*queue, 0, len(backends)),
+		logger: logger,
+	}
+
+	for _, backend := range backends {
+		r.queues = append(r.queues, newQueue(backend, queueConfig, timeout, r.render, logger))
+	}
+
+	return r
+}
+
+// Backends returns the names of the registered backends.
+func (r *Registry) Backends() []string {
+	names := make([]string, 0, len(r.queues))
+	for _, q := range r.queues {
+		names = append(names, q.backend.Name())
+	}
+
+	return names
+}
+
+// SendMessage queues an operational message. Repeated messages are coalesced.
+func (r *Registry) SendMessage(format string, args ...any) {
+	r.push(PriorityNormal, format, args...)
+}
+
+// SendAlert queues a message which is delivered ahead of operational messages.
+func (r *Registry) SendAlert(format string, args ...any) {
+	r.push(PriorityHigh, format, args...)
+}
+
+// Close stops accepting messages and flushes the queues until the context is done.
+func (r *Registry) Close(ctx context.Context) error {
+	undelivered := 0
+	for _, q := range r.queues {
+		undelivered += q.close(ctx)
+	}
+
+	if undelivered > 0 {
+		return fmt.Errorf("%d notifications were not delivered", undelivered)
+	}
+
+	return nil
+}
+
+func (r *Registry) push(priority Priority, format string, args ...any) {
+	m := message{
+		body:      fmt.Sprintf(format, args...),
+		priority:  priority,
+		createdAt: time.Now(),
+	}
+
+	if len(r.queues) == 0 {
+		r.logger.Info("notification", "message", r.render(m))
+		return
+	}
+
+	for _, q := range r.queues {
+		q.push(m)
+	}
+}
+
+func (r *Registry) render(m message) string {
+	text := fmt.Sprintf(
+		"<b>[%s]</b>\n<u>%s</u>\n\n%s",
+		r.source,
+		m.createdAt.Format("2006-01-02T15:04:05.000Z07:00"),
+		m.body,
+	)
+
+	if m.repeated > 0 {
+		text += fmt.Sprintf("\n\n<i>(repeated %d more times)</i>", m.repeated)
+	}
+
+	return text
+}
@@ -0,0 +1,314 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingBackend struct {
+	name string
+	// failures is the number of first sends returning err
+	failures int
+	err      error
+	// release blocks the first send until closed
+	release chan struct{}
+
+	guard    sync.Mutex
+	messages []string
+	attempts int
+}
+
+func (b *recordingBackend) Name() string {
+	return b.name
+}
+
+func (b *recordingBackend) Send(_ context.Context, message string) error {
+	b.guard.Lock()
+	b.attempts++
+	attempt := b.attempts
+	b.guard.Unlock()
+
+	if attempt == 1 && b.release != nil {
+		<-b.release
+	}
+
+	if attempt <= b.failures {
+		return b.err
+	}
+
+	b.guard.Lock()
+	defer b.guard.Unlock()
+
+	b.messages = append(b.messages, message)
+
+	return nil
+}
+
+func (b *recordingBackend) bodies() []string {
+	b.guard.Lock()
+	defer b.guard.Unlock()
+
+	bodies := make([]string, 0, len(b.messages))
+	for _, message := range b.messages {
+		_, body, _ := strings.Cut(message, "\n\n")
+		bodies = append(bodies, body)
+	}
+
+	return bodies
+}
+
+func (b *recordingBackend) attemptsCount() int {
+	b.guard.Lock()
+	defer b.guard.Unlock()
+
+	return b.attempts
+}
+
+func testQueueConfig() QueueConfig {
+	return QueueConfig{
+		Capacity:       16,
+		MaxRetries:     2,
+		RetryDelay:     time.Millisecond,
+		CoalesceWindow: time.Minute,
+		FlushTimeout:   time.Second,
+	}
+}
+
+func newTestRegistry(config QueueConfig, backends ...Backend) *Registry {
+	return NewRegistry(
+		"test",
+		time.Second,
+		config,
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		backends...,
+	)
+}
+
+func closeRegistry(t *testing.T, registry *Registry) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := registry.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestRegistry_FanOut(t *testing.T) {
+	first := &recordingBackend{name: "first"}
+	second := &recordingBackend{name: "second"}
+
+	registry := newTestRegistry(testQueueConfig(), first, second)
+	registry.SendMessage("listed %s", "LPT")
+	closeRegistry(t, registry)
+
+	for _, backend := range []*recordingBackend{first, second} {
+		if bodies := backend.bodies(); len(bodies) != 1 || bodies[0] != "listed LPT" {
+			t.Errorf("%s got %q, want [listed LPT]", backend.name, bodies)
+		}
+	}
+
+	if !strings.HasPrefix(first.messages[0], "<b>[test]</b>\n<u>") {
+		t.Errorf("message without header: %q", first.messages[0])
+	}
+
+	registry.SendMessage("after close")
+	if len(first.bodies()) != 1 {
+		t.Error("message accepted after Close()")
+	}
+}
+
+func TestRegistry_PriorityAndCoalescing(t *testing.T) {
+	backend := &recordingBackend{name: "blocked", release: make(chan struct{})}
+
+	registry := newTestRegistry(testQueueConfig(), backend)
+
+	registry.SendMessage("first")
+	// Wait until the first message blocks the delivery
+	for backend.attemptsCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	registry.SendMessage("proxy banned")
+	registry.SendMessage("slow poller")
+	registry.SendMessage("proxy banned")
+	registry.SendAlert("listing %s", "LPT")
+	registry.SendMessage("proxy banned")
+
+	close(backend.release)
+	closeRegistry(t, registry)
+
+	expected := []string{
+		"first",
+		"listing LPT",
+		"proxy banned\n\n<i>(repeated 2 more times)</i>",
+		"slow poller",
+	}
+
+	if got := backend.bodies(); strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("delivered %q, want %q", got, expected)
+	}
+}
+
+func TestRegistry_CoalesceWindow(t *testing.T) {
+	backend := &recordingBackend{name: "recording"}
+
+	registry := newTestRegistry(testQueueConfig(), backend)
+	registry.SendMessage("proxy banned")
+	for len(backend.bodies()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Sent within the window, counted but not delivered
+	registry.SendMessage("proxy banned")
+	registry.SendAlert("proxy banned")
+	closeRegistry(t, registry)
+
+	if got := backend.bodies(); len(got) != 2 {
+		t.Errorf("delivered %q, want the first message and the alert", got)
+	}
+}
+
+func TestRegistry_Retry(t *testing.T) {
+	retried := &recordingBackend{
+		name:     "retried",
+		failures: 2,
+		err:      &RetryAfterError{After: 10 * time.Millisecond, Err: errors.New("429")},
+	}
+	failing := &recordingBackend{name: "failing", failures: 10, err: errors.New("boom")}
+
+	registry := newTestRegistry(testQueueConfig(), retried, failing)
+	registry.SendAlert("listing")
+	closeRegistry(t, registry)
+
+	if retried.attemptsCount() != 3 || len(retried.bodies()) != 1 {
+		t.Errorf("retried backend: %d attempts, %q delivered", retried.attemptsCount(), retried.bodies())
+	}
+
+	if failing.attemptsCount() != 3 || len(failing.bodies()) != 0 {
+		t.Errorf("failing backend: %d attempts, want 3", failing.attemptsCount())
+	}
+}
+
+func TestRegistry_Capacity(t *testing.T) {
+	backend := &recordingBackend{name: "blocked", release: make(chan struct{})}
+
+	config := testQueueConfig()
+	config.Capacity = 2
+
+	registry := newTestRegistry(config, backend)
+	registry.SendMessage("first")
+	for backend.attemptsCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	registry.SendMessage("old")
+	registry.SendAlert("alert 1")
+	registry.SendAlert("alert 2")
+	registry.SendMessage("dropped")
+
+	close(backend.release)
+	closeRegistry(t, registry)
+
+	expected := []string{"first", "alert 1", "alert 2"}
+	if got := backend.bodies(); strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("delivered %q, want %q", got, expected)
+	}
+}
+
+func TestRegistry_CloseTimeout(t *testing.T) {
+	backend := &recordingBackend{name: "blocked", release: make(chan struct{})}
+	defer close(backend.release)
+
+	registry := newTestRegistry(testQueueConfig(), backend)
+	registry.SendMessage("first")
+	registry.SendMessage("second")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- registry.Close(ctx)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Close() returned before the blocked delivery finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	backend.release <- struct{}{}
+	if err := <-done; err == nil {
+		t.Error("Close() should report undelivered messages")
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	message := strings.Join([]string{
+		strings.Repeat("a", 6),
+		strings.Repeat("b", 6),
+		strings.Repeat("c", 20),
+		strings.Repeat("d", 2),
+	}, "\n")
+
+	parts, skipped := splitMessage(message, 10)
+
+	expected := []string{"aaaaaa\n", "bbbbbb\ndd\n"}
+	if skipped != 1 || strings.Join(parts, "|") != strings.Join(expected, "|") {
+		t.Errorf("splitMessage() = %q, %d, want %q, 1", parts, skipped, expected)
+	}
+}
+
+func TestToMarkdown(t *testing.T) {
+	got := toMarkdown(`<b>Title</b> &lt;LPT&gt; <a href="https://upbit.com">notice</a> <u>x</u>`, "**")
+	expected := "**Title** <LPT> notice (https://upbit.com) x"
+
+	if got != expected {
+		t.Errorf("toMarkdown() = %q, want %q", got, expected)
+	}
+}
+
+func TestWebhook_Send(t *testing.T) {
+	var (
+		payload map[string]any
+		header  string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Token")
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, map[string]string{"X-Token": "secret"})
+	if err := webhook.Send(context.Background(), "<b>LPT</b> &amp; POKT"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if header != "secret" || payload["text"] != "LPT & POKT" || payload["html"] != "<b>LPT</b> &amp; POKT" {
+		t.Errorf("webhook got header %q and payload %v", header, payload)
+	}
+
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer limited.Close()
+
+	var retryAfter *RetryAfterError
+	err := NewDiscord(limited.URL).Send(context.Background(), "x")
+	if !errors.As(err, &retryAfter) || retryAfter.After != 3*time.Second {
+		t.Errorf("Send() error = %v, want RetryAfterError of 3s", err)
+	}
+}
@@ -0,0 +1,357 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Priority is the delivery lane of a message. Messages of a higher priority are delivered first.
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh
+)
+
+// QueueConfig configures the delivery queue of every backend
+type QueueConfig struct {
+	Capacity       int           `mapstructure:"capacity"        validate:"required,gt=0" env:"NOTIFIER_QUEUE_CAPACITY"`
+	SendInterval   time.Duration `mapstructure:"send_interval"   validate:"gte=0"         env:"NOTIFIER_QUEUE_SEND_INTERVAL"`
+	MaxRetries     int           `mapstructure:"max_retries"     validate:"gte=0"         env:"NOTIFIER_QUEUE_MAX_RETRIES"`
+	RetryDelay     time.Duration `mapstructure:"retry_delay"     validate:"required"      env:"NOTIFIER_QUEUE_RETRY_DELAY"`
+	CoalesceWindow time.Duration `mapstructure:"coalesce_window" validate:"gte=0"         env:"NOTIFIER_QUEUE_COALESCE_WINDOW"`
+	FlushTimeout   time.Duration `mapstructure:"flush_timeout"   validate:"required"      env:"NOTIFIER_QUEUE_FLUSH_TIMEOUT"`
+}
+
+// RetryAfterError is returned by a backend which is asked to wait before the next attempt
+type RetryAfterError struct {
+	After time.Duration
+	Err   error
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// limited is implemented by backends with a maximum message length
+type limited interface {
+	Limit() int
+}
+
+type message struct {
+	body      string
+	priority  Priority
+	createdAt time.Time
+	// repeated is the number of identical messages coalesced into this one
+	repeated int
+}
+
+// queue delivers messages to one backend in order of priority and arrival,
+// no more often than the send interval.
+type queue struct {
+	backend Backend
+	config  QueueConfig
+	timeout time.Duration
+	render  func(message) string
+	logger  *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	guard sync.Mutex
+	lanes [PriorityHigh + 1][]*message
+	// pending holds the waiting normal messages by body for coalescing
+	pending map[string]*message
+	// recent holds the send time of normal messages within the coalesce window
+	recent     map[string]time.Time
+	suppressed map[string]int
+	closed     bool
+	dropped    int
+
+	wake     chan struct{}
+	done     chan struct{}
+	lastSent time.Time
+}
+
+func newQueue(
+	backend Backend,
+	config QueueConfig,
+	timeout time.Duration,
+	render func(message) string,
+	logger *slog.Logger,
+) *queue {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q := &queue{
+		backend:    backend,
+		config:     config,
+		timeout:    timeout,
+		render:     render,
+		logger:     logger.With("backend", backend.Name()),
+		ctx:        ctx,
+		cancel:     cancel,
+		pending:    make(map[string]*message),
+		recent:     make(map[string]time.Time),
+		suppressed: make(map[string]int),
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+
+	go q.run()
+
+	return q
+}
+
+// push adds the message to its lane. Normal messages identical to a waiting one or to one sent
+// within the coalesce window are counted instead of delivered again.
+// When the queue is full the oldest normal message is dropped.
+func (q *queue) push(m message) {
+	q.guard.Lock()
+	defer q.guard.Unlock()
+
+	if q.closed {
+		q.logger.Warn("notification queue is closed, message dropped")
+		return
+	}
+
+	if m.priority == PriorityNormal && !q.coalesce(m) {
+		return
+	}
+
+	if q.len() >= q.config.Capacity && !q.dropOldest(m.priority) {
+		q.dropped++
+		q.logger.Warn("notification queue is full, message dropped", "dropped", q.dropped)
+		return
+	}
+
+	entry := &m
+	q.lanes[m.priority] = append(q.lanes[m.priority], entry)
+	if m.priority == PriorityNormal {
+		q.pending[m.body] = entry
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// coalesce reports whether the normal message should be queued. The guard must be held.
+func (q *queue) coalesce(m message) bool {
+	if waiting, ok := q.pending[m.body]; ok {
+		waiting.repeated++
+		return false
+	}
+
+	if q.config.CoalesceWindow <= 0 {
+		return true
+	}
+
+	for body, sentAt := range q.recent {
+		if m.createdAt.Sub(sentAt) >= q.config.CoalesceWindow {
+			delete(q.recent, body)
+		}
+	}
+
+	if _, ok := q.recent[m.body]; ok {
+		q.suppressed[m.body]++
+		return false
+	}
+
+	return true
+}
+
+// dropOldest makes room for a message of the priority. The guard must be held.
+func (q *queue) dropOldest(priority Priority) bool {
+	lane := PriorityNormal
+	if len(q.lanes[PriorityNormal]) == 0 {
+		if priority == PriorityNormal {
+			return false
+		}
+
+		lane = PriorityHigh
+	}
+
+	oldest := q.lanes[lane][0]
+	q.lanes[lane] = q.lanes[lane][1:]
+	if lane == PriorityNormal {
+		delete(q.pending, oldest.body)
+	}
+
+	q.dropped++
+	q.logger.Warn("notification queue is full, oldest message dropped", "dropped", q.dropped)
+
+	return true
+}
+
+func (q *queue) len() int {
+	return len(q.lanes[PriorityNormal]) + len(q.lanes[PriorityHigh])
+}
+
+// pop takes the next message, high priority first. The guard must be held.
+func (q *queue) pop() (message, bool) {
+	for priority := PriorityHigh; priority >= PriorityNormal; priority-- {
+		if len(q.lanes[priority]) == 0 {
+			continue
+		}
+
+		m := q.lanes[priority][0]
+		q.lanes[priority] = q.lanes[priority][1:]
+
+		if priority == PriorityNormal {
+			delete(q.pending, m.body)
+
+			m.repeated += q.suppressed[m.body]
+			delete(q.suppressed, m.body)
+
+			if q.config.CoalesceWindow > 0 {
+				q.recent[m.body] = time.Now()
+			}
+		}
+
+		return *m, true
+	}
+
+	return message{}, false
+}
+
+func (q *queue) run() {
+	defer close(q.done)
+
+	for q.ctx.Err() == nil {
+		q.guard.Lock()
+		m, ok := q.pop()
+		closed := q.closed
+		q.guard.Unlock()
+
+		if !ok {
+			if closed {
+				return
+			}
+
+			select {
+			case <-q.wake:
+			case <-q.ctx.Done():
+				return
+			}
+
+			continue
+		}
+
+		q.deliver(m)
+	}
+}
+
+func (q *queue) deliver(m message) {
+	text := q.render(m)
+
+	limit := 0
+	if backend, ok := q.backend.(limited); ok {
+		limit = backend.Limit()
+	}
+
+	parts := []string{text}
+	if limit > 0 {
+		var skipped int
+		if parts, skipped = splitMessage(text, limit); skipped > 0 {
+			q.logger.Error("lines exceeding the message limit skipped", "lines", skipped)
+		}
+	}
+
+	for _, part := range parts {
+		if err := q.send(part); err != nil {
+			q.logger.Error("failed to send notification", "error", err)
+		}
+	}
+}
+
+// send delivers one part retrying on errors. The delay doubles after every attempt
+// unless the backend asks to wait for a specific time.
+func (q *queue) send(part string) error {
+	delay := q.config.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		if !q.sleep(time.Until(q.lastSent.Add(q.config.SendInterval))) {
+			return q.ctx.Err()
+		}
+
+		ctx, cancel := context.WithTimeout(q.ctx, q.timeout)
+		err := q.backend.Send(ctx, part)
+		cancel()
+
+		q.lastSent = time.Now()
+
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= q.config.MaxRetries {
+			return err
+		}
+
+		wait := delay
+		var retryAfter *RetryAfterError
+		if errors.As(err, &retryAfter) && retryAfter.After > 0 {
+			wait = retryAfter.After
+		}
+
+		q.logger.Warn("notification delivery failed, retrying", "error", err, "attempt", attempt+1, "wait", wait)
+
+		if !q.sleep(wait) {
+			return q.ctx.Err()
+		}
+
+		delay *= 2
+	}
+}
+
+// sleep waits for the duration and reports false if the queue was aborted meanwhile.
+func (q *queue) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return q.ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-q.ctx.Done():
+		return false
+	}
+}
+
+// close stops accepting messages and waits until the queued ones are delivered.
+// If the context is done first, the delivery is aborted and the number of undelivered messages is returned.
+func (q *queue) close(ctx context.Context) int {
+	q.guard.Lock()
+	q.closed = true
+	q.guard.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-q.done:
+		return 0
+	case <-ctx.Done():
+	}
+
+	q.cancel()
+	<-q.done
+
+	q.guard.Lock()
+	defer q.guard.Unlock()
+
+	return q.len()
+}
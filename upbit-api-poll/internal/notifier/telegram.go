@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegoapi"
+)
+
+// telegramMessageLimit is the maximum length of a Telegram message
+const telegramMessageLimit = 4096
+
+// Telegram sends messages to a Telegram chat
+type Telegram struct {
+	bot    *telego.Bot
+	chatID int64
+}
+
+func NewTelegram(bot *telego.Bot, chatID int64) *Telegram {
+	return &Telegram{
+		bot:    bot,
+		chatID: chatID,
+	}
+}
+
+func (t *Telegram) Name() string {
+	return "telegram"
+}
+
+func (t *Telegram) Limit() int {
+	return telegramMessageLimit
+}
+
+// Send sends the message, flood control errors are returned as RetryAfterError.
+func (t *Telegram) Send(ctx context.Context, message string) error {
+	_, err := t.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID:    telego.ChatID{ID: t.chatID},
+		Text:      message,
+		ParseMode: telego.ModeHTML,
+	})
+
+	var apiErr *telegoapi.Error
+	if errors.As(err, &apiErr) && apiErr.Parameters != nil && apiErr.Parameters.RetryAfter > 0 {
+		return &RetryAfterError{
+			After: time.Duration(apiErr.Parameters.RetryAfter) * time.Second,
+			Err:   err,
+		}
+	}
+
+	return err
+}
+
+// splitMessage splits the message on line boundaries into parts shorter than the limit.
+// It returns the number of lines which don't fit into a part alone.
+func splitMessage(message string, limit int) ([]string, int) {
+	parts := make([]string, 0, 1)
+	builder := strings.Builder{}
+	skipped := 0
+
+	for _, line := range strings.Split(message, "\n") {
+		if len(line) >= limit {
+			skipped++
+			continue
+		}
+
+		if builder.Len()+len(line) >= limit {
+			parts = append(parts, builder.String())
+			builder.Reset()
+		}
+
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+
+	if builder.Len() > 0 {
+		parts = append(parts, builder.String())
+	}
+
+	return parts, skipped
+}
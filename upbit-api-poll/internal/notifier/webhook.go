@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// discordMessageLimit is the maximum length of the content of a Discord message
+	discordMessageLimit = 2000
+	// slackMessageLimit is the length after which Slack truncates a message
+	slackMessageLimit = 40000
+)
+
+// Webhook posts messages as JSON to an HTTP endpoint. The payload depends on the flavour:
+// Discord and Slack incoming webhooks or a generic payload with both plain text and the original HTML.
+type Webhook struct {
+	name    string
+	url     string
+	headers map[string]string
+	limit   int
+	payload func(message string) any
+	client  *http.Client
+}
+
+// NewDiscord creates a backend for a Discord incoming webhook.
+func NewDiscord(url string) *Webhook {
+	return &Webhook{
+		name:  "discord",
+		url:   url,
+		limit: discordMessageLimit,
+		payload: func(message string) any {
+			return map[string]string{"content": toMarkdown(message, "**")}
+		},
+		client: &http.Client{},
+	}
+}
+
+// NewSlack creates a backend for a Slack incoming webhook.
+func NewSlack(url string) *Webhook {
+	return &Webhook{
+		name:  "slack",
+		url:   url,
+		limit: slackMessageLimit,
+		payload: func(message string) any {
+			return map[string]string{"text": toMarkdown(message, "*")}
+		},
+		client: &http.Client{},
+	}
+}
+
+// NewWebhook creates a backend for a generic HTTP webhook. The headers are added to every request.
+func NewWebhook(url string, headers map[string]string) *Webhook {
+	return &Webhook{
+		name:    "webhook",
+		url:     url,
+		headers: headers,
+		payload: func(message string) any {
+			return map[string]any{
+				"text":    toText(message),
+				"html":    message,
+				"sent_at": time.Now().UTC(),
+			}
+		},
+		client: &http.Client{},
+	}
+}
+
+func (w *Webhook) Name() string {
+	return w.name
+}
+
+// Limit returns the maximum message length, 0 if unlimited
+func (w *Webhook) Limit() int {
+	return w.limit
+}
+
+// Send posts the message, 429 responses with Retry-After are returned as RetryAfterError.
+func (w *Webhook) Send(ctx context.Context, message string) error {
+	body, err := json.Marshal(w.payload(message))
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range w.headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		text, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		err := fmt.Errorf("unexpected status %d: %s", response.StatusCode, text)
+
+		seconds, _ := strconv.Atoi(response.Header.Get("Retry-After"))
+		if response.StatusCode == http.StatusTooManyRequests && seconds > 0 {
+			return &RetryAfterError{After: time.Duration(seconds) * time.Second, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -1,156 +1,264 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// MetricUsageErrorsTotal counts the updates of undeclared metrics and the updates with other labels than declared
+const MetricUsageErrorsTotal = "metrics_usage_errors_total"
+
+type MetricKind int
+
+const (
+	KindCounter MetricKind = iota
+	KindGauge
+	KindHistogram
+)
+
+// Metric declares a metric with its help text, label names and, for histograms, buckets
+type Metric struct {
+	Name   string
+	Help   string
+	Kind   MetricKind
+	Labels []string
+	// Buckets are the histogram buckets, DefBuckets if empty
+	Buckets []float64
+}
+
+var (
+	ErrMetricUndeclared = errors.New("metric is not declared")
+	ErrMetricKind       = errors.New("metric is declared with another kind")
+	ErrMetricLabels     = errors.New("labels don't match the declared ones")
+)
+
+// DurationBuckets are the buckets of the durations in seconds, from sub-millisecond requests to slow polls
+var DurationBuckets = []float64{
+	0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+// PrometheusService updates the declared metrics. An update of an undeclared metric or with other labels
+// than declared is dropped and counted in metrics_usage_errors_total, it's safe for concurrent use.
 type PrometheusService struct {
+	guard      sync.RWMutex
+	registry   *prometheus.Registry
+	declared   map[string]Metric
 	counters   map[string]*prometheus.CounterVec
 	gauges     map[string]*prometheus.GaugeVec
 	histograms map[string]*prometheus.HistogramVec
+
+	usageErrors *prometheus.CounterVec
 }
 
 type PrometheusTimer struct {
-	timer prometheus.Timer
+	timer *prometheus.Timer
 }
 
 func (t *PrometheusTimer) ObserveDuration() {
-	t.timer.ObserveDuration()
+	if t.timer != nil {
+		t.timer.ObserveDuration()
+	}
 }
 
 func NewPrometheusService() *PrometheusService {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	usageErrors := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: MetricUsageErrorsTotal,
+			Help: "Updates of undeclared metrics or with undeclared labels, which were dropped",
+		},
+		[]string{"metric", "reason"},
+	)
+	registry.MustRegister(usageErrors)
+
 	return &PrometheusService{
-		counters:   make(map[string]*prometheus.CounterVec),
-		gauges:     make(map[string]*prometheus.GaugeVec),
-		histograms: make(map[string]*prometheus.HistogramVec),
+		registry:    registry,
+		declared:    make(map[string]Metric),
+		counters:    make(map[string]*prometheus.CounterVec),
+		gauges:      make(map[string]*prometheus.GaugeVec),
+		histograms:  make(map[string]*prometheus.HistogramVec),
+		usageErrors: usageErrors,
+	}
+}
+
+// Declare registers the metrics, a metric can't be declared twice
+func (p *PrometheusService) Declare(metrics ...Metric) error {
+	p.guard.Lock()
+	defer p.guard.Unlock()
+
+	for _, metric := range metrics {
+		if _, ok := p.declared[metric.Name]; ok {
+			return fmt.Errorf("metric %s is already declared", metric.Name)
+		}
+
+		var collector prometheus.Collector
+
+		switch metric.Kind {
+		case KindCounter:
+			counter := prometheus.NewCounterVec(
+				prometheus.CounterOpts{Name: metric.Name, Help: metric.Help},
+				metric.Labels,
+			)
+			p.counters[metric.Name], collector = counter, counter
+		case KindGauge:
+			gauge := prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{Name: metric.Name, Help: metric.Help},
+				metric.Labels,
+			)
+			p.gauges[metric.Name], collector = gauge, gauge
+		case KindHistogram:
+			buckets := metric.Buckets
+			if len(buckets) == 0 {
+				buckets = prometheus.DefBuckets
+			}
+
+			histogram := prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{Name: metric.Name, Help: metric.Help, Buckets: buckets},
+				metric.Labels,
+			)
+			p.histograms[metric.Name], collector = histogram, histogram
+		default:
+			return fmt.Errorf("metric %s has unknown kind %d", metric.Name, metric.Kind)
+		}
+
+		if err := p.registry.Register(collector); err != nil {
+			return fmt.Errorf("register metric %s: %w", metric.Name, err)
+		}
+
+		p.declared[metric.Name] = metric
+	}
+
+	return nil
+}
+
+// MustDeclare is Declare which panics on an error
+func (p *PrometheusService) MustDeclare(metrics ...Metric) {
+	if err := p.Declare(metrics...); err != nil {
+		panic(err)
 	}
 }
 
 func (p *PrometheusService) IncrementCounter(name string, labels ...string) {
-	counter := p.getOrCreateCounter(name, getLabelNames(labels))
-	if len(labels) == 0 {
-		counter.WithLabelValues().Inc()
-	} else {
-		counter.WithLabelValues(getLabelValues(labels)...).Inc()
+	p.guard.RLock()
+	counter, ok := p.counters[name]
+	p.guard.RUnlock()
+
+	if values, ok := p.usable(name, ok, labels); ok {
+		counter.WithLabelValues(values...).Inc()
 	}
 }
 
 func (p *PrometheusService) SetGauge(name string, value float64, labels ...string) {
-	gauge := p.getOrCreateGauge(name, getLabelNames(labels))
-	if len(labels) == 0 {
-		gauge.WithLabelValues().Set(value)
-	} else {
-		gauge.WithLabelValues(getLabelValues(labels)...).Set(value)
-	}
+	p.withGauge(name, labels, func(gauge prometheus.Gauge) { gauge.Set(value) })
 }
 
 func (p *PrometheusService) IncrementGauge(name string, labels ...string) {
-	gauge := p.getOrCreateGauge(name, getLabelNames(labels))
-	if len(labels) == 0 {
-		gauge.WithLabelValues().Inc()
-	} else {
-		gauge.WithLabelValues(getLabelValues(labels)...).Inc()
-	}
+	p.withGauge(name, labels, prometheus.Gauge.Inc)
 }
 
 func (p *PrometheusService) DecrementGauge(name string, labels ...string) {
-	gauge := p.getOrCreateGauge(name, getLabelNames(labels))
-	if len(labels) == 0 {
-		gauge.WithLabelValues().Dec()
-	} else {
-		gauge.WithLabelValues(getLabelValues(labels)...).Dec()
-	}
+	p.withGauge(name, labels, prometheus.Gauge.Dec)
 }
 
 func (p *PrometheusService) ObserveHistogram(name string, value float64, labels ...string) {
-	histogram := p.getOrCreateHistogram(name, getLabelNames(labels))
-	if len(labels) == 0 {
-		histogram.WithLabelValues().Observe(value)
-	} else {
-		histogram.WithLabelValues(getLabelValues(labels)...).Observe(value)
+	p.guard.RLock()
+	histogram, ok := p.histograms[name]
+	p.guard.RUnlock()
+
+	if values, ok := p.usable(name, ok, labels); ok {
+		histogram.WithLabelValues(values...).Observe(value)
 	}
 }
 
 func (p *PrometheusService) StartTimer(name string, labels ...string) *PrometheusTimer {
-	histogram := p.getOrCreateHistogram(name, getLabelNames(labels))
-	var timer prometheus.Timer
-	if len(labels) == 0 {
-		timer = *prometheus.NewTimer(histogram.WithLabelValues())
-	} else {
-		timer = *prometheus.NewTimer(histogram.WithLabelValues(getLabelValues(labels)...))
+	p.guard.RLock()
+	histogram, ok := p.histograms[name]
+	p.guard.RUnlock()
+
+	values, ok := p.usable(name, ok, labels)
+	if !ok {
+		return &PrometheusTimer{}
 	}
-	return &PrometheusTimer{timer: timer}
+
+	return &PrometheusTimer{timer: prometheus.NewTimer(histogram.WithLabelValues(values...))}
 }
 
-func (p *PrometheusService) getOrCreateCounter(
-	name string,
-	labelNames []string,
-) *prometheus.CounterVec {
-	if counter, exists := p.counters[name]; exists {
-		return counter
-	}
+func (p *PrometheusService) withGauge(name string, labels []string, update func(prometheus.Gauge)) {
+	p.guard.RLock()
+	gauge, ok := p.gauges[name]
+	p.guard.RUnlock()
 
-	counter := promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: name,
-			Help: name + " counter",
-		},
-		labelNames,
-	)
-	p.counters[name] = counter
-	return counter
+	if values, ok := p.usable(name, ok, labels); ok {
+		update(gauge.WithLabelValues(values...))
+	}
 }
 
-func (p *PrometheusService) getOrCreateGauge(
-	name string,
-	labelNames []string,
-) *prometheus.GaugeVec {
-	if gauge, exists := p.gauges[name]; exists {
-		return gauge
+// usable checks that the metric is declared with the kind and the label names of the update,
+// and returns the label values in the declared order
+func (p *PrometheusService) usable(name string, ofKind bool, labels []string) ([]string, bool) {
+	values, err := p.labelValues(name, ofKind, labels)
+	if err != nil {
+		reason := "labels"
+		switch {
+		case errors.Is(err, ErrMetricUndeclared):
+			reason = "undeclared"
+		case errors.Is(err, ErrMetricKind):
+			reason = "kind"
+		}
+
+		p.usageErrors.WithLabelValues(name, reason).Inc()
+
+		return nil, false
 	}
 
-	gauge := promauto.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: name,
-			Help: name + " gauge",
-		},
-		labelNames,
-	)
-	p.gauges[name] = gauge
-	return gauge
+	return values, true
 }
 
-func (p *PrometheusService) getOrCreateHistogram(
-	name string,
-	labelNames []string,
-) *prometheus.HistogramVec {
-	if histogram, exists := p.histograms[name]; exists {
-		return histogram
+func (p *PrometheusService) labelValues(name string, ofKind bool, labels []string) ([]string, error) {
+	p.guard.RLock()
+	metric, declared := p.declared[name]
+	p.guard.RUnlock()
+
+	if !declared {
+		return nil, fmt.Errorf("%w: %s", ErrMetricUndeclared, name)
 	}
 
-	histogram := promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    name,
-			Help:    name + " histogram",
-			Buckets: prometheus.DefBuckets,
-		},
-		labelNames,
-	)
-	p.histograms[name] = histogram
-	return histogram
-}
+	if !ofKind {
+		return nil, fmt.Errorf("%w: %s", ErrMetricKind, name)
+	}
+
+	names, values := getLabelNames(labels), getLabelValues(labels)
+	if len(labels)%2 != 0 || len(names) != len(metric.Labels) {
+		return nil, fmt.Errorf("%w: %s %v, declared %v", ErrMetricLabels, name, names, metric.Labels)
+	}
+
+	ordered := make([]string, len(metric.Labels))
+	for i, label := range metric.Labels {
+		j := slices.Index(names, label)
+		if j < 0 {
+			return nil, fmt.Errorf("%w: %s %v, declared %v", ErrMetricLabels, name, names, metric.Labels)
+		}
+
+		ordered[i] = values[j]
+	}
 
-func (p *PrometheusService) StartMetricsServer(port string) error {
-	http.Handle("/metrics", promhttp.Handler())
-	return http.ListenAndServe(":"+port, nil)
+	return ordered, nil
 }
 
 func (p *PrometheusService) GetHandler() http.Handler {
-	return promhttp.Handler()
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
 }
 
 // Helper functions to extract label names and values from alternating key-value pairs
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestService(t *testing.T) *PrometheusService {
+	t.Helper()
+
+	service := NewPrometheusService()
+	if err := service.Declare(
+		Metric{Name: "requests_total", Help: "Requests", Kind: KindCounter, Labels: []string{"proxy", "type"}},
+		Metric{Name: "latency_seconds", Help: "Latency", Kind: KindHistogram, Buckets: []float64{0.0005, 0.001}},
+	); err != nil {
+		t.Fatalf("Declare() error = %v", err)
+	}
+
+	return service
+}
+
+func TestPrometheusService_Labels(t *testing.T) {
+	service := newTestService(t)
+
+	service.IncrementCounter("requests_total", "proxy", "a", "type", "ok")
+	// The labels are matched by name
+	service.IncrementCounter("requests_total", "type", "ok", "proxy", "a")
+
+	if count := testutil.ToFloat64(service.counters["requests_total"].WithLabelValues("a", "ok")); count != 2 {
+		t.Errorf("requests_total = %v, expected 2", count)
+	}
+
+	service.IncrementCounter("requests_total", "proxy", "a")
+	service.IncrementCounter("requests_total", "proxy", "a", "status", "ok")
+	service.IncrementCounter("requests_unknown")
+	service.SetGauge("requests_total", 1, "proxy", "a", "type", "ok")
+
+	for reason, expected := range map[string]float64{"labels": 2, "undeclared": 0, "kind": 1} {
+		if count := testutil.ToFloat64(service.usageErrors.WithLabelValues("requests_total", reason)); count != expected {
+			t.Errorf("%s errors = %v, expected %v", reason, count, expected)
+		}
+	}
+
+	if count := testutil.ToFloat64(service.usageErrors.WithLabelValues("requests_unknown", "undeclared")); count != 1 {
+		t.Errorf("undeclared errors = %v, expected 1", count)
+	}
+
+	if err := service.Declare(Metric{Name: "requests_total", Kind: KindCounter}); err == nil {
+		t.Error("Declare() should fail on a declared metric")
+	}
+}
+
+func TestPrometheusService_Buckets(t *testing.T) {
+	service := newTestService(t)
+
+	service.ObserveHistogram("latency_seconds", 0.0003)
+
+	expected := `
+# HELP latency_seconds Latency
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.0005"} 1
+latency_seconds_bucket{le="0.001"} 1
+latency_seconds_bucket{le="+Inf"} 1
+latency_seconds_sum 0.0003
+latency_seconds_count 1
+`
+	if err := testutil.GatherAndCompare(service.registry, strings.NewReader(expected), "latency_seconds"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPrometheusService_Concurrent(t *testing.T) {
+	service := newTestService(t)
+
+	wg := sync.WaitGroup{}
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range 100 {
+				service.IncrementCounter("requests_total", "proxy", "a", "type", "ok")
+				service.StartTimer("latency_seconds").ObserveDuration()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if count := testutil.ToFloat64(service.counters["requests_total"].WithLabelValues("a", "ok")); count != 1000 {
+		t.Errorf("requests_total = %v, expected 1000", count)
+	}
+}
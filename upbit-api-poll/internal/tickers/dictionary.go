@@ -0,0 +1,443 @@
+package tickers
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// dictionaryMinNameLength protects from matching very short names (e.g. "쑨") inside unrelated words
+	dictionaryMinNameLength = 2
+	// dictionaryMinFuzzyLength is the minimal word length to try fuzzy matching on
+	dictionaryMinFuzzyLength = 4
+	// dictionaryMaxWordsInName is the maximal number of words in a Latin asset name
+	dictionaryMaxWordsInName = 3
+)
+
+// MatchKind tells how a ticker was found in a message
+type MatchKind string
+
+const (
+	// MatchTicker is a ticker written in parentheses, e.g. "라이브피어(LPT)"
+	MatchTicker MatchKind = "ticker"
+	// MatchExact is a whole word equal to an asset name of the symbol registry
+	MatchExact MatchKind = "exact"
+	// MatchAlias is a configured alias of an asset
+	MatchAlias MatchKind = "alias"
+	// MatchSubstring is a Korean asset name glued to other letters, e.g. to a particle
+	MatchSubstring MatchKind = "substring"
+	// MatchFuzzy is a word within a small edit distance of an asset name
+	MatchFuzzy MatchKind = "fuzzy"
+)
+
+// Match is a ticker found in a message with the way it was found
+type Match struct {
+	Ticker string
+	Kind   MatchKind
+}
+
+// Certain reports whether the match is reliable enough to trade on.
+// Substring and fuzzy matches are only good for alerts.
+func (m Match) Certain() bool {
+	return m.Kind == MatchTicker || m.Kind == MatchExact || m.Kind == MatchAlias
+}
+
+// MatchedTickers returns the tickers of the matches, the certain ones only if certain is set
+func MatchedTickers(matches []Match, certain bool) []string {
+	tickers := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if !certain || m.Certain() {
+			tickers = append(tickers, m.Ticker)
+		}
+	}
+
+	return tickers
+}
+
+// dictionaryStopWords are common notice words which must never be fuzzily matched to an asset name
+var dictionaryStopWords = map[string]struct{}{
+	"거래지원":    {},
+	"디지털":     {},
+	"최저가":     {},
+	"이벤트":     {},
+	"market":  {},
+	"markets": {},
+	"support": {},
+	"krw":     {},
+	"usdt":    {},
+}
+
+// Dictionary maps Korean and English asset names to ticker symbols.
+// Names seeded from the symbol registry are replaced on every seed,
+// configured aliases are kept and take precedence over seeded names.
+type Dictionary struct {
+	guard   sync.RWMutex
+	seeded  map[string]string
+	aliases map[string]string
+}
+
+func NewDictionary(aliases map[string]string) *Dictionary {
+	d := &Dictionary{
+		seeded:  make(map[string]string),
+		aliases: make(map[string]string, len(aliases)),
+	}
+
+	for name, ticker := range aliases {
+		if key := normalizeName(name); key != "" {
+			d.aliases[key] = Canonicalize(ticker)
+		}
+	}
+
+	return d
+}
+
+// Seed replaces the seeded names with the names of the given symbols.
+func (d *Dictionary) Seed(symbols []Symbol) {
+	seeded := make(map[string]string, len(symbols)*2)
+
+	for _, symbol := range symbols {
+		for _, name := range []string{symbol.KoreanName, symbol.EnglishName} {
+			if key := normalizeName(name); key != "" {
+				seeded[key] = Canonicalize(symbol.Ticker)
+			}
+		}
+	}
+
+	d.guard.Lock()
+	defer d.guard.Unlock()
+
+	d.seeded = seeded
+}
+
+func (d *Dictionary) Len() int {
+	d.guard.RLock()
+	defer d.guard.RUnlock()
+
+	return len(d.seeded) + len(d.aliases)
+}
+
+// Resolve returns the ticker of an asset name, first by exact (normalized) match and then fuzzily.
+func (d *Dictionary) Resolve(name string) (string, bool) {
+	key := normalizeName(name)
+	if key == "" {
+		return "", false
+	}
+
+	d.guard.RLock()
+	defer d.guard.RUnlock()
+
+	if m, ok := d.lookup(key); ok {
+		return m.Ticker, true
+	}
+
+	m, ok := d.fuzzyLookup(key)
+
+	return m.Ticker, ok
+}
+
+// Find returns tickers of all asset names mentioned in the message in order of appearance.
+// Korean names are searched as substrings, since Korean titles glue particles to names,
+// Latin names are matched against whole words, and the rest of the words are matched fuzzily.
+// A ticker found several times has the most certain kind of its matches.
+func (d *Dictionary) Find(message string) []Match {
+	type match struct {
+		Match
+		position int
+	}
+
+	d.guard.RLock()
+	defer d.guard.RUnlock()
+
+	matches := []match{}
+	normalized, offsets := normalizeWithOffsets(message)
+	covered := make([]bool, len(normalized))
+
+	// Longer names first, so "비트코인캐시" wins over "비트코인"
+	names := d.names()
+	slices.SortFunc(names, func(a, b string) int {
+		return len(b) - len(a)
+	})
+
+	for _, name := range names {
+		if isLatin(name) {
+			continue
+		}
+
+		for offset := 0; offset < len(normalized); {
+			i := strings.Index(normalized[offset:], name)
+			if i < 0 {
+				break
+			}
+
+			start, end := offset+i, offset+i+len(name)
+			offset = end
+
+			if slices.Contains(covered[start:end], true) {
+				continue
+			}
+
+			for j := start; j < end; j++ {
+				covered[j] = true
+			}
+
+			m, _ := d.lookup(name)
+			if m.Kind == MatchExact && !wholeWord(message, offsets[start], offsets[end-1]) {
+				m.Kind = MatchSubstring
+			}
+
+			matches = append(matches, match{Match: m, position: offsets[start]})
+		}
+	}
+
+	words := splitWords(message)
+
+	for i := 0; i < len(words); i++ {
+		// Multi-word Latin names, e.g. "Pudgy Penguins"
+		for n := min(dictionaryMaxWordsInName, len(words)-i); n > 0; n-- {
+			key := ""
+			for _, w := range words[i : i+n] {
+				key += normalizeName(w.text)
+			}
+
+			if !isLatin(key) {
+				continue
+			}
+
+			if m, ok := d.lookup(key); ok {
+				matches = append(matches, match{Match: m, position: words[i].position})
+				i += n - 1
+				break
+			}
+		}
+	}
+
+	for _, w := range words {
+		key := normalizeName(w.text)
+		if _, ok := dictionaryStopWords[key]; ok {
+			continue
+		}
+
+		if _, ok := d.lookup(key); ok {
+			continue
+		}
+
+		if m, ok := d.fuzzyLookup(key); ok {
+			matches = append(matches, match{Match: m, position: w.position})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return a.position - b.position
+	})
+
+	found := make([]Match, 0, len(matches))
+	for _, m := range matches {
+		i := slices.IndexFunc(found, func(f Match) bool { return f.Ticker == m.Ticker })
+		switch {
+		case i < 0:
+			found = append(found, m.Match)
+		case !found[i].Certain() && m.Certain():
+			found[i] = m.Match
+		}
+	}
+
+	return found
+}
+
+func (d *Dictionary) lookup(key string) (Match, bool) {
+	if ticker, ok := d.aliases[key]; ok {
+		return Match{Ticker: ticker, Kind: MatchAlias}, true
+	}
+
+	ticker, ok := d.seeded[key]
+	return Match{Ticker: ticker, Kind: MatchExact}, ok
+}
+
+func (d *Dictionary) names() []string {
+	names := make([]string, 0, len(d.aliases)+len(d.seeded))
+
+	for name := range d.aliases {
+		if utf8.RuneCountInString(name) >= dictionaryMinNameLength {
+			names = append(names, name)
+		}
+	}
+
+	for name := range d.seeded {
+		if _, ok := d.aliases[name]; ok {
+			continue
+		}
+
+		if utf8.RuneCountInString(name) >= dictionaryMinNameLength {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// fuzzyLookup returns the ticker of the single closest name within the allowed edit distance.
+// Ambiguous matches (two different tickers at the same distance) are rejected.
+func (d *Dictionary) fuzzyLookup(key string) (Match, bool) {
+	length := utf8.RuneCountInString(key)
+	if length < dictionaryMinFuzzyLength {
+		return Match{}, false
+	}
+
+	maxDistance := 1
+	if length >= 8 {
+		maxDistance = 2
+	}
+
+	bestTicker := ""
+	bestDistance := maxDistance + 1
+	ambiguous := false
+
+	for _, name := range d.names() {
+		nameLength := utf8.RuneCountInString(name)
+		if nameLength-length > maxDistance || length-nameLength > maxDistance {
+			continue
+		}
+
+		distance := levenshtein(key, name)
+		if distance > maxDistance {
+			continue
+		}
+
+		m, _ := d.lookup(name)
+		ticker := m.Ticker
+
+		switch {
+		case distance < bestDistance:
+			bestTicker, bestDistance, ambiguous = ticker, distance, false
+		case distance == bestDistance && ticker != bestTicker:
+			ambiguous = true
+		}
+	}
+
+	if bestTicker == "" || ambiguous {
+		return Match{}, false
+	}
+
+	return Match{Ticker: bestTicker, Kind: MatchFuzzy}, true
+}
+
+// wholeWord reports whether the part of the message from the rune at start to the rune at last
+// is neither preceded nor followed by a letter or a digit
+func wholeWord(message string, start, last int) bool {
+	before, _ := utf8.DecodeLastRuneInString(message[:start])
+	_, size := utf8.DecodeRuneInString(message[last:])
+	after, _ := utf8.DecodeRuneInString(message[last+size:])
+
+	return !isWordRune(before) && !isWordRune(after)
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// normalizeName lower-cases the name and drops everything except letters and digits.
+func normalizeName(name string) string {
+	builder := strings.Builder{}
+	builder.Grow(len(name))
+
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return builder.String()
+}
+
+// normalizeWithOffsets works like normalizeName and also returns the offset
+// in the original string of every byte of the normalized one.
+func normalizeWithOffsets(message string) (string, []int) {
+	builder := strings.Builder{}
+	builder.Grow(len(message))
+	offsets := make([]int, 0, len(message))
+
+	for i, r := range message {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+
+		before := builder.Len()
+		builder.WriteRune(unicode.ToLower(r))
+
+		for range builder.Len() - before {
+			offsets = append(offsets, i)
+		}
+	}
+
+	return builder.String(), offsets
+}
+
+type word struct {
+	text     string
+	position int
+}
+
+// splitWords splits a title into words on whitespace, punctuation and brackets.
+func splitWords(message string) []word {
+	result := []word{}
+	start := -1
+
+	for i, r := range message {
+		separator := unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '.' && r != '-')
+
+		switch {
+		case separator && start >= 0:
+			result = append(result, word{text: message[start:i], position: start})
+			start = -1
+		case !separator && start < 0:
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		result = append(result, word{text: message[start:], position: start})
+	}
+
+	return result
+}
+
+func isLatin(name string) bool {
+	for _, r := range name {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+
+	return true
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
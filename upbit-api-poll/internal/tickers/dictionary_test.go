@@ -0,0 +1,138 @@
+package tickers
+
+import (
+	"slices"
+	"testing"
+)
+
+func newTestDictionary() *Dictionary {
+	dictionary := NewDictionary(map[string]string{
+		"포켓네트워크": "POKT",
+		"io.net": "IO",
+	})
+
+	dictionary.Seed([]Symbol{
+		{Ticker: "KRW-LPT", KoreanName: "라이브피어", EnglishName: "Livepeer"},
+		{Ticker: "KRW-BTC", KoreanName: "비트코인", EnglishName: "Bitcoin"},
+		{Ticker: "KRW-BCH", KoreanName: "비트코인캐시", EnglishName: "Bitcoin Cash"},
+		{Ticker: "KRW-PENGU", KoreanName: "펏지펭귄", EnglishName: "Pudgy Penguins"},
+		{Ticker: "KRW-TIA", KoreanName: "셀레스티아", EnglishName: "Celestia"},
+	})
+
+	return dictionary
+}
+
+func TestDictionary_Resolve(t *testing.T) {
+	dictionary := newTestDictionary()
+
+	testCases := []struct {
+		name     string
+		expected string
+		found    bool
+	}{
+		{"라이브피어", "LPT", true},
+		{"라이브 피어", "LPT", true},
+		{"LIVEPEER", "LPT", true},
+		{"Livepear", "LPT", true},
+		{"셀레스티야", "TIA", true},
+		{"IO.NET", "IO", true},
+		{"포켓 네트워크", "POKT", true},
+		{"비트", "", false},
+		{"완전히다른코인", "", false},
+	}
+
+	for _, tc := range testCases {
+		got, found := dictionary.Resolve(tc.name)
+		if got != tc.expected || found != tc.found {
+			t.Errorf(
+				"Resolve(%q) = %q, %v, want %q, %v",
+				tc.name,
+				got,
+				found,
+				tc.expected,
+				tc.found,
+			)
+		}
+	}
+}
+
+func TestDictionary_Find(t *testing.T) {
+	dictionary := newTestDictionary()
+
+	testCases := []struct {
+		name     string
+		message  string
+		expected []Match
+	}{
+		{
+			name:     "Korean names without tickers",
+			message:  "라이브피어, 포켓네트워크 디지털 자산 추가 (KRW 마켓)",
+			expected: []Match{{"LPT", MatchExact}, {"POKT", MatchAlias}},
+		},
+		{
+			name:     "Longest name wins",
+			message:  "비트코인캐시 입출금 일시 중단 안내",
+			expected: []Match{{"BCH", MatchExact}},
+		},
+		{
+			name:     "Name with particle",
+			message:  "라이브피어의 KRW 마켓 신규 거래지원 안내",
+			expected: []Match{{"LPT", MatchSubstring}},
+		},
+		{
+			name:     "Multi-word english name",
+			message:  "Market Support for Pudgy Penguins (KRW Market)",
+			expected: []Match{{"PENGU", MatchExact}},
+		},
+		{
+			name:     "English name must be a whole word",
+			message:  "Bitcoiners meetup announcement",
+			expected: []Match{},
+		},
+		{
+			name:     "Misspelled korean name",
+			message:  "셀레스티야 신규 거래지원 안내",
+			expected: []Match{{"TIA", MatchFuzzy}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := dictionary.Find(tc.message)
+			if !slices.Equal(got, tc.expected) {
+				t.Errorf("Find() = %v, want %v, message: %s", got, tc.expected, tc.message)
+			}
+		})
+	}
+}
+
+func TestExtractKoreanTickersWithDictionary(t *testing.T) {
+	dictionary := newTestDictionary()
+
+	got := ExtractKoreanTickersWithDictionary("라이브피어(LPT) 신규 거래지원 안내 (KRW 마켓)", dictionary)
+	if !slices.Equal(got, []Match{{"LPT", MatchTicker}}) {
+		t.Errorf("with ticker in parentheses = %v, want [LPT]", got)
+	}
+
+	got = ExtractKoreanTickersWithDictionary("라이브피어 신규 거래지원 안내 (KRW 마켓)", dictionary)
+	if !slices.Equal(got, []Match{{"LPT", MatchExact}}) {
+		t.Errorf("with korean name only = %v, want [LPT]", got)
+	}
+
+	got = ExtractKoreanTickersWithDictionary("라이브피어 신규 거래지원 안내 (KRW 마켓)", nil)
+	if len(got) != 0 {
+		t.Errorf("without dictionary = %v, want []", got)
+	}
+}
+
+func TestMatchedTickers(t *testing.T) {
+	matches := []Match{{"LPT", MatchTicker}, {"TIA", MatchFuzzy}, {"POKT", MatchAlias}, {"BTC", MatchSubstring}}
+
+	if got := MatchedTickers(matches, false); !slices.Equal(got, []string{"LPT", "TIA", "POKT", "BTC"}) {
+		t.Errorf("MatchedTickers() = %v, want all tickers", got)
+	}
+
+	if got := MatchedTickers(matches, true); !slices.Equal(got, []string{"LPT", "POKT"}) {
+		t.Errorf("MatchedTickers() of certain = %v, want [LPT POKT]", got)
+	}
+}
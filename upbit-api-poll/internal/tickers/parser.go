@@ -103,3 +103,20 @@ func ExtractKoreanTickers(message string) []string {
 	}
 	return tickers
 }
+
+// ExtractKoreanTickersWithDictionary извлекает тикеры из корейских новостей, а если тикеров
+// в скобках нет (актив назван только по-корейски), ищет названия активов по словарю.
+// Вид совпадения показывает, можно ли по тикеру торговать (см. Match.Certain)
+func ExtractKoreanTickersWithDictionary(message string, dictionary *Dictionary) []Match {
+	tickers := ExtractKoreanTickers(message)
+	if len(tickers) > 0 || dictionary == nil {
+		matches := make([]Match, 0, len(tickers))
+		for _, ticker := range tickers {
+			matches = append(matches, Match{Ticker: ticker, Kind: MatchTicker})
+		}
+
+		return matches
+	}
+
+	return dictionary.Find(message)
+}
@@ -0,0 +1,263 @@
+package tickers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SourceUpbit = "upbit"
+	SourceVenue = "venue"
+)
+
+var ErrRegistryNotRefreshed = errors.New("symbol registry was never refreshed")
+
+// Symbol is a known asset symbol together with the places it is known from.
+type Symbol struct {
+	Ticker      string   `json:"ticker"`
+	KoreanName  string   `json:"korean_name,omitempty"`
+	EnglishName string   `json:"english_name,omitempty"`
+	Sources     []string `json:"sources"`
+}
+
+// ListedOn reports whether the symbol was seen in the given source.
+func (s Symbol) ListedOn(source string) bool {
+	return slices.Contains(s.Sources, source)
+}
+
+// Tradable reports whether the symbol can be traded on the execution venue.
+func (s Symbol) Tradable() bool {
+	return s.ListedOn(SourceVenue)
+}
+
+// SymbolSource provides a full list of symbols known to a single upstream.
+type SymbolSource interface {
+	Name() string
+	FetchSymbols(ctx context.Context) ([]Symbol, error)
+}
+
+// Registry keeps the set of known symbols merged from several sources.
+// Sources that fail to refresh keep their previous snapshot.
+type Registry struct {
+	sources []SymbolSource
+
+	guard       sync.RWMutex
+	snapshots   map[string][]Symbol
+	symbols     map[string]Symbol
+	refreshedAt time.Time
+
+	onRefresh []func(symbols []Symbol)
+}
+
+func NewRegistry(sources ...SymbolSource) *Registry {
+	return &Registry{
+		sources:   sources,
+		snapshots: make(map[string][]Symbol, len(sources)),
+		symbols:   make(map[string]Symbol),
+	}
+}
+
+// OnRefresh registers a callback that receives all symbols after every successful refresh.
+func (r *Registry) OnRefresh(fn func(symbols []Symbol)) {
+	r.guard.Lock()
+	defer r.guard.Unlock()
+
+	r.onRefresh = append(r.onRefresh, fn)
+}
+
+// Refresh fetches all sources and rebuilds the registry.
+// It returns a joined error of all failed sources.
+func (r *Registry) Refresh(ctx context.Context) error {
+	var errs []error
+
+	fetched := make(map[string][]Symbol, len(r.sources))
+	for _, source := range r.sources {
+		symbols, err := source.FetchSymbols(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+			continue
+		}
+
+		fetched[source.Name()] = symbols
+	}
+
+	if len(fetched) == 0 {
+		return errors.Join(errs...)
+	}
+
+	r.guard.Lock()
+
+	for name, symbols := range fetched {
+		r.snapshots[name] = symbols
+	}
+
+	r.symbols = mergeSnapshots(r.snapshots)
+	r.refreshedAt = time.Now()
+
+	symbols := r.list()
+	callbacks := slices.Clone(r.onRefresh)
+
+	r.guard.Unlock()
+
+	for _, fn := range callbacks {
+		fn(symbols)
+	}
+
+	return errors.Join(errs...)
+}
+
+// StartRefreshing refreshes the registry every interval until the context is done.
+func (r *Registry) StartRefreshing(
+	ctx context.Context,
+	interval time.Duration,
+	onError func(err error),
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Lookup returns the symbol for a ticker in any notation ("lpt", "KRW-LPT", "LPT_USDT").
+func (r *Registry) Lookup(ticker string) (Symbol, bool) {
+	r.guard.RLock()
+	defer r.guard.RUnlock()
+
+	symbol, ok := r.symbols[Canonicalize(ticker)]
+	return symbol, ok
+}
+
+// Validate splits tickers into known symbols and unknown canonical tickers.
+// Duplicates are dropped, order is preserved.
+func (r *Registry) Validate(tickers []string) ([]Symbol, []string) {
+	r.guard.RLock()
+	defer r.guard.RUnlock()
+
+	known := make([]Symbol, 0, len(tickers))
+	unknown := make([]string, 0)
+	seen := make(map[string]struct{}, len(tickers))
+
+	for _, ticker := range tickers {
+		canonical := Canonicalize(ticker)
+		if canonical == "" {
+			continue
+		}
+
+		if _, ok := seen[canonical]; ok {
+			continue
+		}
+		seen[canonical] = struct{}{}
+
+		if symbol, ok := r.symbols[canonical]; ok {
+			known = append(known, symbol)
+			continue
+		}
+
+		unknown = append(unknown, canonical)
+	}
+
+	return known, unknown
+}
+
+func (r *Registry) Symbols() []Symbol {
+	r.guard.RLock()
+	defer r.guard.RUnlock()
+
+	return r.list()
+}
+
+func (r *Registry) Len() int {
+	r.guard.RLock()
+	defer r.guard.RUnlock()
+
+	return len(r.symbols)
+}
+
+// RefreshedAt returns the time of the last successful refresh.
+func (r *Registry) RefreshedAt() (time.Time, error) {
+	r.guard.RLock()
+	defer r.guard.RUnlock()
+
+	if r.refreshedAt.IsZero() {
+		return time.Time{}, ErrRegistryNotRefreshed
+	}
+
+	return r.refreshedAt, nil
+}
+
+func (r *Registry) list() []Symbol {
+	symbols := make([]Symbol, 0, len(r.symbols))
+	for _, symbol := range r.symbols {
+		symbols = append(symbols, symbol)
+	}
+
+	slices.SortFunc(symbols, func(a, b Symbol) int {
+		return strings.Compare(a.Ticker, b.Ticker)
+	})
+
+	return symbols
+}
+
+func mergeSnapshots(snapshots map[string][]Symbol) map[string]Symbol {
+	merged := make(map[string]Symbol)
+
+	for source, symbols := range snapshots {
+		for _, symbol := range symbols {
+			ticker := Canonicalize(symbol.Ticker)
+			if ticker == "" {
+				continue
+			}
+
+			existing := merged[ticker]
+			existing.Ticker = ticker
+
+			if existing.KoreanName == "" {
+				existing.KoreanName = symbol.KoreanName
+			}
+
+			if existing.EnglishName == "" {
+				existing.EnglishName = symbol.EnglishName
+			}
+
+			if !existing.ListedOn(source) {
+				existing.Sources = append(existing.Sources, source)
+				slices.Sort(existing.Sources)
+			}
+
+			merged[ticker] = existing
+		}
+	}
+
+	return merged
+}
+
+// Canonicalize converts a ticker from Upbit ("KRW-LPT") or venue ("LPT_USDT")
+// notation to a bare upper-case symbol ("LPT").
+func Canonicalize(ticker string) string {
+	ticker = strings.ToUpper(strings.TrimSpace(ticker))
+
+	if i := strings.LastIndexByte(ticker, '-'); i >= 0 {
+		ticker = ticker[i+1:]
+	}
+
+	if i := strings.IndexByte(ticker, '_'); i >= 0 {
+		ticker = ticker[:i]
+	}
+
+	return ticker
+}
@@ -0,0 +1,107 @@
+package tickers
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type staticSource struct {
+	name    string
+	symbols []Symbol
+	err     error
+}
+
+func (s *staticSource) Name() string {
+	return s.name
+}
+
+func (s *staticSource) FetchSymbols(context.Context) ([]Symbol, error) {
+	return s.symbols, s.err
+}
+
+func TestCanonicalize(t *testing.T) {
+	testCases := []struct {
+		ticker   string
+		expected string
+	}{
+		{"LPT", "LPT"},
+		{" lpt ", "LPT"},
+		{"KRW-LPT", "LPT"},
+		{"LPT_USDT", "LPT"},
+		{"", ""},
+	}
+
+	for _, tc := range testCases {
+		if got := Canonicalize(tc.ticker); got != tc.expected {
+			t.Errorf("Canonicalize(%q) = %q, want %q", tc.ticker, got, tc.expected)
+		}
+	}
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	registry := NewRegistry(
+		&staticSource{name: SourceUpbit, symbols: []Symbol{
+			{Ticker: "KRW-LPT", KoreanName: "라이브피어", EnglishName: "Livepeer"},
+			{Ticker: "BTC-LPT", KoreanName: "라이브피어", EnglishName: "Livepeer"},
+			{Ticker: "KRW-BTC", KoreanName: "비트코인", EnglishName: "Bitcoin"},
+		}},
+		&staticSource{name: SourceVenue, symbols: []Symbol{
+			{Ticker: "LPT_USDT"},
+			{Ticker: "SOPH_USDT"},
+		}},
+	)
+
+	if _, err := registry.RefreshedAt(); !errors.Is(err, ErrRegistryNotRefreshed) {
+		t.Fatalf("RefreshedAt() error = %v, want %v", err, ErrRegistryNotRefreshed)
+	}
+
+	if err := registry.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	known, unknown := registry.Validate([]string{"LPT", "SOPH", "BTC", "LPT", "GARBAGE"})
+
+	if len(known) != 3 {
+		t.Fatalf("Validate() known = %v, want 3 symbols", known)
+	}
+
+	if known[0].Ticker != "LPT" || !known[0].Tradable() || !known[0].ListedOn(SourceUpbit) {
+		t.Errorf("LPT = %+v, want tradable symbol listed on upbit", known[0])
+	}
+
+	if known[0].KoreanName != "라이브피어" {
+		t.Errorf("LPT korean name = %q, want %q", known[0].KoreanName, "라이브피어")
+	}
+
+	if known[1].Ticker != "SOPH" || !known[1].Tradable() || known[1].ListedOn(SourceUpbit) {
+		t.Errorf("SOPH = %+v, want tradable symbol not listed on upbit", known[1])
+	}
+
+	if known[2].Ticker != "BTC" || known[2].Tradable() {
+		t.Errorf("BTC = %+v, want non-tradable symbol", known[2])
+	}
+
+	if len(unknown) != 1 || unknown[0] != "GARBAGE" {
+		t.Errorf("Validate() unknown = %v, want [GARBAGE]", unknown)
+	}
+}
+
+func TestRegistry_RefreshKeepsSnapshotOfFailedSource(t *testing.T) {
+	venue := &staticSource{name: SourceVenue, symbols: []Symbol{{Ticker: "LPT_USDT"}}}
+	registry := NewRegistry(venue)
+
+	if err := registry.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	venue.err = errors.New("venue is down")
+
+	if err := registry.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh() error = nil, want error")
+	}
+
+	if _, ok := registry.Lookup("LPT"); !ok {
+		t.Error("Lookup(LPT) after failed refresh = false, want true")
+	}
+}
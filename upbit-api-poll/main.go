@@ -3,18 +3,35 @@ package main
 import (
 	"context"
 	"flag"
-	_ "net/http/pprof" // Import pprof for profiling
+	"fmt"
+	"html"
 	"os"
 	"os/signal"
+	"strings"
+	"time"
 
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/config"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/control"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/core"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/di/setup"
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/entity"
+	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/gate"
 )
 
+// configReloadTimeout bounds waiting for the requests in flight of removed proxies on reload
+const configReloadTimeout = 30 * time.Second
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "config":
+			os.Exit(runConfigCommand(os.Args[2:], os.Stdout))
+		case "captures":
+			os.Exit(runCapturesCommand(os.Args[2:], os.Stdout))
+		}
+	}
+
 	configPath := flag.String("config", "configs/config.yaml", "path to config file")
 	flag.Parse()
 
@@ -25,33 +42,166 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
-	run(ctx, deps)
+	run(ctx, deps, *configPath)
 }
 
-func run(ctx context.Context, deps di.Container) {
-	newsChan := streamNews(ctx, deps)
+func run(ctx context.Context, deps di.Container, configPath string) {
+	dictionary := core.NewAssetDictionary(deps)
+
+	registry, err := core.NewSymbolRegistry(ctx, deps, dictionary)
+	if err != nil {
+		panic(err)
+	}
+
+	go registry.StartRefreshing(ctx, deps.Config.SymbolRegistry.RefreshInterval, func(err error) {
+		deps.Logger.Error("failed to refresh symbol registry", "error", err)
+	})
+
+	tradingSwitch := core.NewTradingSwitch(deps.Config.Trading)
+
+	scheduler, err := core.NewActionScheduler(
+		deps.Config.ActionScheduler,
+		core.NewActionExecutors(deps, tradingSwitch),
+		deps.Logger,
+		deps,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	go scheduler.Run(ctx)
+
+	newsChan, sources := streamNews(ctx, deps)
 
-	monitor := core.NewNewsMonitor(newsChan, deps.Metrics)
+	if interval := deps.Config.UpbitAPI.ReconcileInterval; interval > 0 {
+		reconciler, err := core.NewReconciler(deps)
+		if err != nil {
+			panic(err)
+		}
+
+		for _, source := range sources {
+			if fetcher, ok := source.Fetcher.(core.ReconciledFetcher); ok {
+				reconciler.Add(source.Name, fetcher)
+			}
+		}
+
+		go reconciler.Run(ctx, interval)
+	}
+
+	monitor := core.NewNewsMonitor(newsChan, registry, dictionary, scheduler, tradingSwitch, deps)
 	go monitor.StartMonitoring(ctx)
 
-	go func() {
-		deps.Logger.Info("Starting Prometheus metrics server on :8080")
+	controller := control.NewController(monitor, scheduler, tradingSwitch, deps.Logger, sources...)
 
-		if err := deps.Metrics.StartMetricsServer("8080"); err != nil {
-			deps.Logger.Error("Failed to start metrics server", "error", err)
+	watchConfig(ctx, deps, configPath, controller)
+
+	if deps.Telegram != nil && deps.Config.Telegram.Commands.Enabled {
+		commands := control.NewTelegramCommands(deps.Telegram, deps.Config.Telegram, controller, deps.Logger)
+
+		go func() {
+			if err := commands.Run(ctx); err != nil {
+				deps.Logger.Error("Failed to run telegram commands", "error", err)
+			}
+		}()
+	}
+
+	if deps.Config.Admin.Enabled {
+		api := control.NewHTTPAPI(deps.Config.Admin, controller, deps.Logger)
+
+		go func() {
+			if err := api.Run(ctx); err != nil {
+				deps.Logger.Error("Failed to run admin API", "error", err)
+			}
+		}()
+	}
+
+	health := control.NewHealth(deps.Config.Server, gate.Ping, sources...)
+	go health.RunExecutorChecks(ctx)
+
+	server := control.NewServer(deps.Config.Server, health, deps.Metrics.GetHandler(), deps.Logger)
+
+	go func() {
+		if err := server.Run(ctx); err != nil {
+			deps.Logger.Error("Failed to run metrics and health listeners", "error", err)
 		}
 	}()
 
 	<-ctx.Done()
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), deps.Config.Notifier.Queue.FlushTimeout)
+	defer cancel()
+
+	if err := deps.Notifier.Close(flushCtx); err != nil {
+		deps.Logger.Error("failed to flush notifications", "error", err)
+	}
+
+	if err := deps.Captures.Close(flushCtx); err != nil {
+		deps.Logger.Error("failed to write captures", "error", err)
+	}
+
+	if deps.Traces != nil {
+		if err := deps.Traces.Shutdown(flushCtx); err != nil {
+			deps.Logger.Error("failed to flush traces", "error", err)
+		}
+	}
+}
+
+// watchConfig applies the changes of the config file which can be changed at runtime
+// and reports the reloads to the notifier.
+func watchConfig(ctx context.Context, deps di.Container, configPath string, controller *control.Controller) {
+	actor := control.Actor{Channel: "config", ID: configPath}
+
+	err := config.Watch(
+		ctx,
+		configPath,
+		deps.Config,
+		func(previous, next config.Config) config.Config {
+			applyCtx, cancel := context.WithTimeout(ctx, configReloadTimeout)
+			defer cancel()
+
+			result, err := controller.ApplyConfig(applyCtx, actor, previous, next)
+
+			message := fmt.Sprintf(
+				"🔄 <b>Configuration reloaded</b>\nApplied: %s\nRestart required: %s",
+				keysOrNone(result.Applied),
+				keysOrNone(result.RestartRequired),
+			)
+			if err == nil {
+				deps.SendMessage("%s", message)
+				return next
+			}
+
+			message += "\n\n❌ Not applied:\n" + html.EscapeString(err.Error())
+			deps.SendMessage("%s", message)
+
+			// The failed changes are applied again with the next reload
+			return previous
+		},
+		func(err error) {
+			deps.Logger.Error("Configuration reload rejected", "error", err)
+			deps.SendMessage("❌ <b>Configuration reload rejected</b>\n%s", html.EscapeString(err.Error()))
+		},
+	)
+	if err != nil {
+		deps.Logger.Warn("Configuration reload disabled", "error", err)
+	}
 }
 
-func streamNews(ctx context.Context, deps di.Container) <-chan entity.NewsTitle {
+func keysOrNone(keys []string) string {
+	if len(keys) == 0 {
+		return "none"
+	}
+
+	return strings.Join(keys, ", ")
+}
+
+func streamNews(ctx context.Context, deps di.Container) (<-chan entity.NewsEvent, []control.Source) {
 	// announcementsFetcher, err := core.NewAnnouncementsFetcher(deps)
 	// if err != nil {
 	// 	panic(err)
 	// }
 
-	// announcementsNews, err := announcementsFetcher.StreamNewNewsTitles(ctx)
+	// announcementsNews, err := announcementsFetcher.StreamNewAnnouncements(ctx)
 	// if err != nil {
 	// 	panic(err)
 	// }
@@ -61,12 +211,16 @@ func streamNews(ctx context.Context, deps di.Container) <-chan entity.NewsTitle
 		panic(err)
 	}
 
-	noticeNews, err := noticeByIDFetcher.StreamNewNoticeTitles(ctx)
+	noticeNews, err := noticeByIDFetcher.StreamNewNotices(ctx)
 	if err != nil {
 		panic(err)
 	}
 
-	newsChan := make(chan entity.NewsTitle, 1024)
+	sources := []control.Source{
+		{Name: "notice_by_id", Poller: noticeByIDFetcher.Poller(), Fetcher: noticeByIDFetcher},
+	}
+
+	newsChan := make(chan entity.NewsEvent, 1024)
 
 	go func() {
 		var lastNews entity.NewsTitle
@@ -81,19 +235,19 @@ func streamNews(ctx context.Context, deps di.Container) <-chan entity.NewsTitle
 					continue
 				}
 
-				if news == lastNews {
+				if news.Title == lastNews {
 					continue
 				}
 
 				deps.Logger.Info(
 					"new notice title",
 					"title",
-					news,
+					news.Title,
 					"from",
 					"notice by id fetcher",
 				)
 
-				lastNews = news
+				lastNews = news.Title
 				newsChan <- news
 
 				// case news, ok := <-announcementsNews:
@@ -101,23 +255,23 @@ func streamNews(ctx context.Context, deps di.Container) <-chan entity.NewsTitle
 				// 		continue
 				// 	}
 
-				// 	if news == lastNews {
+				// 	if news.Title == lastNews {
 				// 		continue
 				// 	}
 
 				// 	deps.Logger.Info(
 				// 		"new announcement title",
 				// 		"title",
-				// 		news,
+				// 		news.Title,
 				// 		"from",
 				// 		"announcements fetcher",
 				// 	)
 
-				// 	lastNews = news
+				// 	lastNews = news.Title
 				// 	newsChan <- news
 			}
 		}
 	}()
 
-	return newsChan
+	return newsChan, sources
 }
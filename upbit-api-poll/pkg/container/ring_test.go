@@ -0,0 +1,34 @@
+package container
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRingTS_Last(t *testing.T) {
+	r := NewRingTS[int](3)
+
+	if got := r.Last(2); len(got) != 0 {
+		t.Errorf("Expected empty ring, got %v", got)
+	}
+
+	r.Push(1)
+	r.Push(2)
+	if got := r.Last(0); !slices.Equal(got, []int{2, 1}) {
+		t.Errorf("Expected [2 1], got %v", got)
+	}
+
+	r.Push(3)
+	r.Push(4)
+	if got := r.Last(0); !slices.Equal(got, []int{4, 3, 2}) {
+		t.Errorf("Expected [4 3 2], got %v", got)
+	}
+
+	if got := r.Last(2); !slices.Equal(got, []int{4, 3}) {
+		t.Errorf("Expected [4 3], got %v", got)
+	}
+
+	if r.Len() != 3 {
+		t.Errorf("Expected length 3, got %d", r.Len())
+	}
+}
@@ -0,0 +1,70 @@
+package container
+
+import "sync"
+
+const RingTSDefaultCapacity = 64
+
+// RingTS keeps the last pushed values, overwriting the oldest when full
+type RingTS[T any] struct {
+	values []T
+	next   int
+	full   bool
+	mu     sync.RWMutex
+}
+
+func NewRingTS[T any](capacity int) *RingTS[T] {
+	if capacity <= 0 {
+		capacity = RingTSDefaultCapacity
+	}
+
+	return &RingTS[T]{
+		values: make([]T, capacity),
+	}
+}
+
+// Push adds a value, overwriting the oldest one if the ring is full
+func (r *RingTS[T]) Push(value T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.values[r.next] = value
+	r.next = (r.next + 1) % len(r.values)
+
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Last returns up to n latest values, newest first. Non-positive n returns all values.
+func (r *RingTS[T]) Last(n int) []T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	size := r.len()
+	if n <= 0 || n > size {
+		n = size
+	}
+
+	result := make([]T, 0, n)
+	for i := 1; i <= n; i++ {
+		result = append(result, r.values[(r.next-i+len(r.values))%len(r.values)])
+	}
+
+	return result
+}
+
+// Len returns the number of stored values
+func (r *RingTS[T]) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.len()
+}
+
+func (r *RingTS[T]) len() int {
+	if r.full {
+		return len(r.values)
+	}
+
+	return r.next
+}
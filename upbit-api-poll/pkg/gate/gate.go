@@ -2,47 +2,70 @@ package gate
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/internal/service"
 	"github.com/antihax/optional"
 	gateapi "github.com/gateio/gateapi-go/v6"
 )
 
-const MetricGateOpenOrderDuration = "gate_open_order_duration_ms"
+const MetricGateOpenOrderDuration = "gate_open_order_duration_seconds"
+
+// Venue is the name of the exchange in the metrics and alerts
+const Venue = "gate"
+
+// OrderTimings are the times the order was sent to the exchange and acknowledged by it
+type OrderTimings struct {
+	SentAt  time.Time
+	AckedAt time.Time
+}
+
+// ErrNoCredentials is returned when an order is opened without the API credentials
+var ErrNoCredentials = errors.New("gate API credentials are not configured")
+
+// Credentials are the API key of the account the orders are opened on
+type Credentials struct {
+	Key    string
+	Secret string
+}
 
 // OpenFuturesOrder открывает фьючерсный ордер на Gate.io
 func OpenFuturesOrder(
 	metrics *service.PrometheusService,
+	credentials Credentials,
 	token string,
 	usdtAmount float64,
 	leverage string,
-) (map[string]interface{}, error) {
+) (map[string]interface{}, OrderTimings, error) {
+	if credentials.Key == "" || credentials.Secret == "" {
+		return nil, OrderTimings{}, ErrNoCredentials
+	}
+
 	// Используем StartTimer/ObserveDuration для метрики
 	timer := metrics.StartTimer(MetricGateOpenOrderDuration)
 	defer timer.ObserveDuration()
-	apiKey := "a9b6cf044fba82cedadac3c9d494f395"
-	apiSecret := "4fe9ebd4443ba1ef0660d35fd031ec3d2820137b27d9513faf411a9e3d6277f0"
 
 	contract := token + "_USDT"
 
 	cfg := gateapi.NewConfiguration()
-	cfg.Key = apiKey
-	cfg.Secret = apiSecret
+	cfg.Key = credentials.Key
+	cfg.Secret = credentials.Secret
 	client := gateapi.NewAPIClient(cfg)
 	ctx := context.Background()
 
 	// 1. Установить левередж для позиции (максимально быстро, игнорируем ошибку десериализации)
 	_, httpResp, _ := client.FuturesApi.UpdatePositionLeverage(ctx, "usdt", contract, leverage, nil)
 	if httpResp != nil && httpResp.StatusCode >= 400 {
-		return nil, fmt.Errorf("set leverage failed: %s", httpResp.Status)
+		return nil, OrderTimings{}, fmt.Errorf("set leverage failed: %s", httpResp.Status)
 	}
 
 	// 2. Получить инфо о контракте и цене
 	contractInfo, _, err := client.FuturesApi.GetFuturesContract(ctx, "usdt", contract)
 	if err != nil {
-		return nil, fmt.Errorf("get contract info: %w", err)
+		return nil, OrderTimings{}, fmt.Errorf("get contract info: %w", err)
 	}
 	tickers, _, err := client.FuturesApi.ListFuturesTickers(
 		ctx,
@@ -50,12 +73,12 @@ func OpenFuturesOrder(
 		&gateapi.ListFuturesTickersOpts{Contract: optional.NewString(contract)},
 	)
 	if err != nil || len(tickers) == 0 {
-		return nil, fmt.Errorf("get ticker: %w", err)
+		return nil, OrderTimings{}, fmt.Errorf("get ticker: %w", err)
 	}
 	currentPrice, _ := strconv.ParseFloat(tickers[0].Last, 64)
 	quantoMultiplier, err := strconv.ParseFloat(contractInfo.QuantoMultiplier, 64)
 	if err != nil {
-		return nil, fmt.Errorf("parse quanto multiplier: %w", err)
+		return nil, OrderTimings{}, fmt.Errorf("parse quanto multiplier: %w", err)
 	}
 
 	// 3. Считаем количество контрактов, чтобы эквивалент было usdtAmount USDT
@@ -73,10 +96,10 @@ func OpenFuturesOrder(
 	}
 
 	if contractInfo.OrderSizeMin > 0 && abs(contractSize) < contractInfo.OrderSizeMin {
-		return nil, fmt.Errorf("size too small")
+		return nil, OrderTimings{}, fmt.Errorf("size too small")
 	}
 	if contractInfo.OrderSizeMax > 0 && abs(contractSize) > contractInfo.OrderSizeMax {
-		return nil, fmt.Errorf("size too large")
+		return nil, OrderTimings{}, fmt.Errorf("size too large")
 	}
 
 	order := gateapi.FuturesOrder{
@@ -86,11 +109,15 @@ func OpenFuturesOrder(
 		Tif:      "ioc",
 	}
 
+	timings := OrderTimings{SentAt: time.Now()}
+
 	result, _, err := client.FuturesApi.CreateFuturesOrder(ctx, "usdt", order, nil)
 	if err != nil {
-		return nil, fmt.Errorf("create order: %w", err)
+		return nil, timings, fmt.Errorf("create order: %w", err)
 	}
 
+	timings.AckedAt = time.Now()
+
 	return map[string]interface{}{
 		"order": result,
 		"conversion_info": map[string]interface{}{
@@ -100,7 +127,39 @@ func OpenFuturesOrder(
 			"order_type":        "market",
 			"current_price":     currentPrice,
 		},
-	}, nil
+	}, timings, nil
+}
+
+// Ping checks that the Gate.io API is reachable with its public server time endpoint
+func Ping(ctx context.Context) error {
+	client := gateapi.NewAPIClient(gateapi.NewConfiguration())
+
+	if _, _, err := client.SpotApi.GetSystemTime(ctx); err != nil {
+		return fmt.Errorf("get system time: %w", err)
+	}
+
+	return nil
+}
+
+// ListFuturesContracts возвращает имена всех активных USDT-фьючерсов на Gate.io (например, "BTC_USDT")
+func ListFuturesContracts(ctx context.Context) ([]string, error) {
+	client := gateapi.NewAPIClient(gateapi.NewConfiguration())
+
+	contracts, _, err := client.FuturesApi.ListFuturesContracts(ctx, "usdt", nil)
+	if err != nil {
+		return nil, fmt.Errorf("list contracts: %w", err)
+	}
+
+	names := make([]string, 0, len(contracts))
+	for _, contract := range contracts {
+		if contract.InDelisting {
+			continue
+		}
+
+		names = append(names, contract.Name)
+	}
+
+	return names, nil
 }
 
 func abs(x int64) int64 {
@@ -0,0 +1,99 @@
+package httptools
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache-busting strategies of the polled URLs
+const (
+	// CacheBustingNone polls the URLs as they are
+	CacheBustingNone = "none"
+	// CacheBustingRandom adds a random value to every request
+	CacheBustingRandom = "random"
+	// CacheBustingTimestamp adds the request time in milliseconds to every request
+	CacheBustingTimestamp = "timestamp"
+)
+
+// cacheBusterParam is the query parameter added when the URL has no {buster} variable
+const cacheBusterParam = "_cb"
+
+// CacheBustingStrategies are the supported cache-busting strategies
+var CacheBustingStrategies = []string{CacheBustingNone, CacheBustingRandom, CacheBustingTimestamp}
+
+const MetricUpbitNewsCacheStatusTotal = "upbit_news_cache_status_total"
+
+// CacheBuster makes the polled URLs unique, so a CDN doesn't serve stale responses
+type CacheBuster struct {
+	strategy string
+	value    func() string
+}
+
+// NewCacheBuster returns the cache buster of the strategy
+func NewCacheBuster(strategy string) (CacheBuster, error) {
+	switch strategy {
+	case CacheBustingNone, "":
+		return CacheBuster{strategy: CacheBustingNone}, nil
+	case CacheBustingRandom:
+		return CacheBuster{
+			strategy: strategy,
+			value:    func() string { return strconv.FormatUint(rand.Uint64(), 36) },
+		}, nil
+	case CacheBustingTimestamp:
+		return CacheBuster{
+			strategy: strategy,
+			value:    func() string { return strconv.FormatInt(time.Now().UnixMilli(), 10) },
+		}, nil
+	default:
+		return CacheBuster{}, fmt.Errorf("unknown cache-busting strategy %q", strategy)
+	}
+}
+
+// Strategy returns the name of the strategy
+func (b CacheBuster) Strategy() string {
+	if b.strategy == "" {
+		return CacheBustingNone
+	}
+
+	return b.strategy
+}
+
+// Bust fills the {buster} variable of the URL or adds the cache-buster query parameter.
+// Without a strategy, the {buster} variable is left empty.
+func (b CacheBuster) Bust(url string) string {
+	placeholder := "{" + VarBuster + "}"
+
+	value := ""
+	if b.value != nil {
+		value = b.value()
+	}
+
+	if strings.Contains(url, placeholder) {
+		return strings.ReplaceAll(url, placeholder, value)
+	}
+
+	if value == "" {
+		return url
+	}
+
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+
+	return url + separator + cacheBusterParam + "=" + value
+}
+
+// CacheStatus returns the CDN cache status of the response in lower case, "none" without one
+func CacheStatus(headers http.Header) string {
+	status := headers.Get("Cf-Cache-Status")
+	if status == "" {
+		return "none"
+	}
+
+	return strings.ToLower(status)
+}
@@ -9,6 +9,10 @@ type ClientConfig struct {
 	Proxy   *Proxy
 	Metrics Metrics
 	Logger  *slog.Logger
+	// Headers are the browser header profiles of the requests
+	Headers HeaderRotation
+	// HeaderKind is the kind of the requests of the client, HeaderKindDocument if empty
+	HeaderKind string
 	ClientRetriesConfig
 }
 
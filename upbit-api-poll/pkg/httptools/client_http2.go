@@ -23,10 +23,9 @@ const (
 	ResponseTimeout        = 1 * time.Second
 	InitialCacheBufferSize = 512
 	MaxResponseBodySize    = 2 * 1024 * 1024 // 2MB, a safe upper limit for API responses
-	UserAgent              = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36"
 
 	// Metric names
-	MetricUpbitRequestDuration    = "upbit_client_request_duration"
+	MetricUpbitRequestDuration    = "upbit_client_request_duration_seconds"
 	MetricUpbitRequestsTotal      = "upbit_client_requests_total"
 	MetricUpbitErrorsTotal        = "upbit_client_errors_total"
 	MetricUpbitResponsesTotal     = "upbit_client_responses_total"
@@ -40,7 +39,9 @@ var ErrRequestTimeout = errors.New("request timeout")
 type ClientHTTP2 struct {
 	ipAddress  string
 	proxyAddr  string
+	proxyID    string
 	httpClient *http.Client
+	headers    *headerPicker
 
 	url                  string
 	maxRetries           int
@@ -94,6 +95,11 @@ func NewClientHTTP2(
 		Transport: transport,
 	}
 
+	headers, err := newHeaderPicker(cfg.Headers, cfg.HeaderKind)
+	if err != nil {
+		return nil, err
+	}
+
 	host := "127.0.0.1"
 	if cfg.Proxy != nil {
 		host = cfg.Proxy.Host
@@ -107,7 +113,9 @@ func NewClientHTTP2(
 		retryDelayMultiplier: cfg.RetryDelayMultiplier,
 		ipAddress:            host,
 		proxyAddr:            proxyAddr,
+		proxyID:              ProxyID(proxyAddr),
 		httpClient:           httpClient,
+		headers:              headers,
 	}
 
 	return client, nil
@@ -116,10 +124,10 @@ func NewClientHTTP2(
 func (c *ClientHTTP2) Request(ctx context.Context, url string) (Response, error) {
 	requestedAt := time.Now()
 
-	timer := c.metrics.StartTimer(MetricUpbitRequestDuration, "proxy", c.proxyAddr)
+	timer := c.metrics.StartTimer(MetricUpbitRequestDuration, "proxy", c.proxyID)
 	defer timer.ObserveDuration()
 
-	c.metrics.IncrementCounter(MetricUpbitRequestsTotal, "proxy", c.proxyAddr)
+	c.metrics.IncrementCounter(MetricUpbitRequestsTotal, "proxy", c.proxyID)
 
 	ctx, cancel := context.WithTimeout(ctx, ResponseTimeout)
 	defer cancel()
@@ -129,7 +137,7 @@ func (c *ClientHTTP2) Request(ctx context.Context, url string) (Response, error)
 		c.metrics.IncrementCounter(
 			MetricUpbitErrorsTotal,
 			"proxy",
-			c.proxyAddr,
+			c.proxyID,
 			"type",
 			"request_creation",
 		)
@@ -137,25 +145,18 @@ func (c *ClientHTTP2) Request(ctx context.Context, url string) (Response, error)
 		return Response{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", UserAgent)
-	req.Header.Set("Accept-Language", "ko-KR, ko;q=1, en-US;q=0.1")
-	req.Header.Set(
-		"Cache-Control",
-		"no-store, no-cache, must-revalidate, proxy-revalidate, max-age=0",
-	)
-	req.Header.Set("Pragma", "no-cache")
-	req.Header.Set("Expires", "0")
-	// req.Header.Set("Origin", "https://binance.com")
-	req.Header.Set("Priority", "u=0")
-	req.Header.Set("Accept-Encoding", "gzip, deflate, br, zstd")
+	// net/http sends the headers in its own order, only the values of the profile are kept
+	profile, headers := c.headers.pick()
+	for _, header := range headers {
+		req.Header.Set(header.Name, header.Value)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		c.metrics.IncrementCounter(
 			MetricUpbitErrorsTotal,
 			"proxy",
-			c.proxyAddr,
+			c.proxyID,
 			"type",
 			"request_failed",
 		)
@@ -170,7 +171,7 @@ func (c *ClientHTTP2) Request(ctx context.Context, url string) (Response, error)
 		c.metrics.IncrementCounter(
 			MetricUpbitErrorsTotal,
 			"proxy",
-			c.proxyAddr,
+			c.proxyID,
 			"type",
 			"body_read",
 		)
@@ -179,7 +180,7 @@ func (c *ClientHTTP2) Request(ctx context.Context, url string) (Response, error)
 	}
 
 	c.metrics.IncrementCounter(MetricUpbitResponsesTotal,
-		"proxy", c.proxyAddr,
+		"proxy", c.proxyID,
 		"status_code", strconv.Itoa(resp.StatusCode),
 		"status_class", c.getStatusClass(resp.StatusCode))
 
@@ -191,19 +192,20 @@ func (c *ClientHTTP2) Request(ctx context.Context, url string) (Response, error)
 		c.proxyAddr,
 		"http/2.0",
 	)
+	upbitResp.HeaderProfile = profile
 
 	switch resp.StatusCode {
 	case http.StatusTooManyRequests:
-		c.metrics.IncrementCounter(MetricUpbitRateLimitedTotal, "proxy", c.proxyAddr)
+		c.metrics.IncrementCounter(MetricUpbitRateLimitedTotal, "proxy", c.proxyID)
 		c.metrics.IncrementCounter(
 			MetricUpbitErrorsTotal,
 			"proxy",
-			c.proxyAddr,
+			c.proxyID,
 			"type",
 			"rate_limited",
 		)
 	case http.StatusOK:
-		c.metrics.IncrementCounter(MetricUpbitSuccessfulRequests, "proxy", c.proxyAddr)
+		c.metrics.IncrementCounter(MetricUpbitSuccessfulRequests, "proxy", c.proxyID)
 	}
 
 	return upbitResp, nil
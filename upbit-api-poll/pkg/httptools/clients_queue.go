@@ -1,84 +1,317 @@
 package httptools
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/ipscrap"
 )
 
+var (
+	ErrProxyExists  = errors.New("proxy is already in the pool")
+	ErrUnknownProxy = errors.New("proxy is not in the pool")
+)
+
+// ClientsQueuePool hands out groups of clients in different locations in turn, every group rests
+// for the rest interval after use. Among the rested groups, the one returning the freshest content
+// is preferred. Clients can be added and removed while the pool is in use.
 type ClientsQueuePool struct {
-	members            chan clientsQueueMember
+	guard sync.Mutex
+	// members are all groups in the order of creation, a new client joins the first group
+	// without a client in its location
+	members []*clientsQueueMember
+	// idle are the groups waiting to be acquired in the order of release
+	idle []*clientsQueueMember
+	// available is signaled when a group becomes idle
+	available chan struct{}
+
 	clientRestInterval time.Duration
+	locate             func(ip string) (string, error)
+	// staleness scores the clients by the proxy address, nil to hand out the groups in turn
+	staleness func(proxyAddr string) time.Duration
 }
 
 type clientsQueueMember struct {
-	clients        []Client
+	clients        []pooledClient
 	lastAcquiredAt time.Time
+	// released is closed when the acquired group is released, nil while the group is idle
+	released chan struct{}
+}
+
+type pooledClient struct {
+	client   Client
+	location string
 }
 
 func NewClientsQueuePool(flatClients []Client, clientRestInterval time.Duration) *ClientsQueuePool {
-	ipScrapper := ipscrap.New("abd01eca341ef3")
-	clientsByLocation := map[string][]Client{}
+	return newClientsQueuePool(flatClients, clientRestInterval, ipscrap.New("abd01eca341ef3").GetLocation)
+}
+
+func newClientsQueuePool(
+	flatClients []Client,
+	clientRestInterval time.Duration,
+	locate func(ip string) (string, error),
+) *ClientsQueuePool {
+	q := &ClientsQueuePool{
+		available:          make(chan struct{}, 1),
+		clientRestInterval: clientRestInterval,
+		locate:             locate,
+	}
+
 	for _, client := range flatClients {
-		location, err := ipScrapper.GetLocation(client.IPAddress())
-		if err != nil {
-			location = "unknown"
-		}
+		_ = q.Add(client)
+	}
 
-		clientsByLocation[location] = append(clientsByLocation[location], client)
+	for _, member := range q.members {
+		slog.Info("clients group", "count", len(member.clients), "locations", member.locations())
 	}
 
-	q := ClientsQueuePool{
-		members:            make(chan clientsQueueMember, len(flatClients)),
-		clientRestInterval: clientRestInterval,
+	return q
+}
+
+// Acquire waits for the next idle group and its rest interval. The group must be released after use.
+func (q *ClientsQueuePool) Acquire() ([]Client, ReleaseFunc) {
+	member, clients, lastAcquiredAt := q.next()
+	acquiredAt := time.Now()
+
+	elapsed := time.Since(lastAcquiredAt)
+	if rest := q.restInterval(); elapsed < rest {
+		time.Sleep(rest - elapsed)
 	}
 
-	initTime := time.Now()
+	release := func() {
+		q.release(member, acquiredAt)
+	}
 
-	for len(clientsByLocation) > 0 {
-		clientsGroup := []Client{}
-		locationsInGroup := []string{}
+	return clients, release
+}
 
-		for location, clients := range clientsByLocation {
-			if len(clients) == 0 {
-				delete(clientsByLocation, location)
-				continue
+func (q *ClientsQueuePool) next() (*clientsQueueMember, []Client, time.Time) {
+	for {
+		q.guard.Lock()
+		if len(q.idle) > 0 {
+			i := q.preferred()
+			member := q.idle[i]
+			q.idle = slices.Delete(q.idle, i, i+1)
+			member.released = make(chan struct{})
+
+			clients := make([]Client, 0, len(member.clients))
+			for _, pooled := range member.clients {
+				clients = append(clients, pooled.client)
 			}
 
-			clientsGroup = append(clientsGroup, clients[0])
-			locationsInGroup = append(locationsInGroup, location)
+			q.signal()
+			q.guard.Unlock()
 
-			clientsByLocation[location] = clients[1:]
+			return member, clients, member.lastAcquiredAt
 		}
+		q.guard.Unlock()
+
+		<-q.available
+	}
+}
+
+// preferred returns the index of the idle group with the lowest staleness among the rested ones,
+// the first idle group if none has rested yet. The guard must be held.
+func (q *ClientsQueuePool) preferred() int {
+	if q.staleness == nil {
+		return 0
+	}
 
-		if len(clientsGroup) == 0 {
+	best, bestStaleness := 0, time.Duration(-1)
+	for i, member := range q.idle {
+		if time.Since(member.lastAcquiredAt) < q.clientRestInterval {
 			continue
 		}
 
-		q.members <- clientsQueueMember{clients: clientsGroup, lastAcquiredAt: initTime}
-		slog.Info("clients group", "count", len(clientsGroup), "locations", locationsInGroup)
+		staleness := member.staleness(q.staleness)
+		if bestStaleness < 0 || staleness < bestStaleness {
+			best, bestStaleness = i, staleness
+		}
 	}
 
-	return &q
+	return best
 }
 
-func (q *ClientsQueuePool) Acquire() ([]Client, ReleaseFunc) {
-	member := <-q.members
-	acquiredAt := time.Now()
+func (q *ClientsQueuePool) release(member *clientsQueueMember, acquiredAt time.Time) {
+	q.guard.Lock()
+	defer q.guard.Unlock()
+
+	close(member.released)
+	member.released = nil
+	member.lastAcquiredAt = acquiredAt
 
-	elapsed := time.Since(member.lastAcquiredAt)
-	if elapsed < q.clientRestInterval {
-		time.Sleep(q.clientRestInterval - elapsed)
+	// A group emptied by removals while in use is gone
+	if len(member.clients) == 0 {
+		return
 	}
 
-	release := func() {
-		q.members <- clientsQueueMember{clients: member.clients, lastAcquiredAt: acquiredAt}
+	q.idle = append(q.idle, member)
+	q.signal()
+}
+
+// signal wakes up a waiting Acquire if there are idle groups. The guard must be held.
+func (q *ClientsQueuePool) signal() {
+	if len(q.idle) == 0 {
+		return
+	}
+
+	select {
+	case q.available <- struct{}{}:
+	default:
+	}
+}
+
+// Add puts the client into the first group without a client in its location
+// or into a new group. The client is used from the next acquisition of the group.
+func (q *ClientsQueuePool) Add(client Client) error {
+	location, err := q.locate(client.IPAddress())
+	if err != nil {
+		location = "unknown"
+	}
+
+	q.guard.Lock()
+	defer q.guard.Unlock()
+
+	if _, _, ok := q.find(client.ProxyAddress()); ok {
+		return ErrProxyExists
+	}
+
+	pooled := pooledClient{client: client, location: location}
+
+	for _, member := range q.members {
+		if !slices.Contains(member.locations(), location) {
+			member.clients = append(member.clients, pooled)
+			return nil
+		}
 	}
 
-	return member.clients, release
+	member := &clientsQueueMember{clients: []pooledClient{pooled}, lastAcquiredAt: time.Now()}
+	q.members = append(q.members, member)
+	q.idle = append(q.idle, member)
+	q.signal()
+
+	return nil
+}
+
+// Remove takes the client with the proxy address out of the pool. If its group is in use,
+// Remove waits until the requests in flight are completed or the context is done.
+func (q *ClientsQueuePool) Remove(ctx context.Context, proxyAddr string) error {
+	q.guard.Lock()
+
+	member, i, ok := q.find(proxyAddr)
+	if !ok {
+		q.guard.Unlock()
+		return ErrUnknownProxy
+	}
+
+	member.clients = slices.Delete(member.clients, i, i+1)
+	if len(member.clients) == 0 {
+		q.members = slices.DeleteFunc(q.members, func(m *clientsQueueMember) bool { return m == member })
+		q.idle = slices.DeleteFunc(q.idle, func(m *clientsQueueMember) bool { return m == member })
+	}
+
+	released := member.released
+	q.guard.Unlock()
+
+	if released == nil {
+		return nil
+	}
+
+	select {
+	case <-released:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// find returns the group of the client with the proxy address and its index. The guard must be held.
+func (q *ClientsQueuePool) find(proxyAddr string) (*clientsQueueMember, int, bool) {
+	for _, member := range q.members {
+		for i, pooled := range member.clients {
+			if pooled.client.ProxyAddress() == proxyAddr {
+				return member, i, true
+			}
+		}
+	}
+
+	return nil, 0, false
+}
+
+// Clients returns all clients in the pool
+func (q *ClientsQueuePool) Clients() []Client {
+	q.guard.Lock()
+	defer q.guard.Unlock()
+
+	clients := make([]Client, 0, len(q.members))
+	for _, member := range q.members {
+		for _, pooled := range member.clients {
+			clients = append(clients, pooled.client)
+		}
+	}
+
+	return clients
 }
 
+// Len returns the number of clients in the pool
 func (q *ClientsQueuePool) Len() int {
-	return len(q.members)
+	q.guard.Lock()
+	defer q.guard.Unlock()
+
+	count := 0
+	for _, member := range q.members {
+		count += len(member.clients)
+	}
+
+	return count
+}
+
+// SetRestInterval changes the time every group rests after use
+func (q *ClientsQueuePool) SetRestInterval(interval time.Duration) {
+	q.guard.Lock()
+	defer q.guard.Unlock()
+
+	q.clientRestInterval = interval
+}
+
+// SetStaleness makes the pool prefer the groups with the lowest mean staleness of the clients
+func (q *ClientsQueuePool) SetStaleness(staleness func(proxyAddr string) time.Duration) {
+	q.guard.Lock()
+	defer q.guard.Unlock()
+
+	q.staleness = staleness
+}
+
+func (q *ClientsQueuePool) restInterval() time.Duration {
+	q.guard.Lock()
+	defer q.guard.Unlock()
+
+	return q.clientRestInterval
+}
+
+func (m *clientsQueueMember) locations() []string {
+	locations := make([]string, 0, len(m.clients))
+	for _, pooled := range m.clients {
+		locations = append(locations, pooled.location)
+	}
+
+	return locations
+}
+
+func (m *clientsQueueMember) staleness(staleness func(proxyAddr string) time.Duration) time.Duration {
+	if len(m.clients) == 0 {
+		return 0
+	}
+
+	total := time.Duration(0)
+	for _, pooled := range m.clients {
+		total += staleness(pooled.client.ProxyAddress())
+	}
+
+	return total / time.Duration(len(m.clients))
 }
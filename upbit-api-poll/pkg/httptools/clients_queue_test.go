@@ -0,0 +1,145 @@
+package httptools
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type fakeClient struct {
+	proxy    string
+	location string
+}
+
+func (c fakeClient) Request(context.Context, string) (Response, error) {
+	return Response{}, nil
+}
+
+func (c fakeClient) ProxyAddress() string {
+	return c.proxy
+}
+
+func (c fakeClient) IPAddress() string {
+	return c.location
+}
+
+// locateByIP uses the fake client IP address as its location
+func locateByIP(ip string) (string, error) {
+	return ip, nil
+}
+
+func proxies(clients []Client) []string {
+	addresses := make([]string, 0, len(clients))
+	for _, client := range clients {
+		addresses = append(addresses, client.ProxyAddress())
+	}
+
+	return addresses
+}
+
+func TestClientsQueuePool_Groups(t *testing.T) {
+	pool := newClientsQueuePool([]Client{
+		fakeClient{"kr-1", "kr"},
+		fakeClient{"kr-2", "kr"},
+		fakeClient{"jp-1", "jp"},
+	}, 0, locateByIP)
+
+	first, releaseFirst := pool.Acquire()
+	second, releaseSecond := pool.Acquire()
+
+	if got := proxies(first); !slices.Equal(got, []string{"kr-1", "jp-1"}) {
+		t.Errorf("first group = %v", got)
+	}
+
+	if got := proxies(second); !slices.Equal(got, []string{"kr-2"}) {
+		t.Errorf("second group = %v", got)
+	}
+
+	// The new client joins the second group, the first one already has a client in Japan
+	if err := pool.Add(fakeClient{"jp-2", "jp"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if err := pool.Add(fakeClient{"jp-2", "jp"}); !errors.Is(err, ErrProxyExists) {
+		t.Errorf("Add() of a duplicate error = %v", err)
+	}
+
+	releaseFirst()
+	releaseSecond()
+
+	pool.Acquire()
+	if got, _ := pool.Acquire(); !slices.Equal(proxies(got), []string{"kr-2", "jp-2"}) {
+		t.Errorf("second group after Add() = %v", proxies(got))
+	}
+
+	if pool.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", pool.Len())
+	}
+}
+
+func TestClientsQueuePool_RemoveDrains(t *testing.T) {
+	pool := newClientsQueuePool([]Client{
+		fakeClient{"kr-1", "kr"},
+		fakeClient{"kr-2", "kr"},
+	}, 0, locateByIP)
+
+	_, release := pool.Acquire()
+
+	removed := make(chan error)
+	go func() {
+		removed <- pool.Remove(context.Background(), "kr-1")
+	}()
+
+	select {
+	case <-removed:
+		t.Fatal("Remove() returned before the group in use was released")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	if err := <-removed; err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	// The emptied group is not handed out again
+	if got, _ := pool.Acquire(); !slices.Equal(proxies(got), []string{"kr-2"}) {
+		t.Errorf("group after Remove() = %v", proxies(got))
+	}
+
+	if err := pool.Remove(context.Background(), "kr-1"); !errors.Is(err, ErrUnknownProxy) {
+		t.Errorf("Remove() of a removed proxy error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := pool.Remove(ctx, "kr-2"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Remove() of a proxy in use error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestClientsQueuePool_PrefersFresh(t *testing.T) {
+	pool := newClientsQueuePool([]Client{
+		fakeClient{"kr-1", "kr"},
+		fakeClient{"kr-2", "kr"},
+		fakeClient{"kr-3", "kr"},
+	}, 0, locateByIP)
+
+	staleness := map[string]time.Duration{"kr-1": time.Minute, "kr-2": 30 * time.Second, "kr-3": time.Second}
+	pool.SetStaleness(func(proxyAddr string) time.Duration {
+		return staleness[proxyAddr]
+	})
+
+	var acquired []string
+	for range 3 {
+		clients, release := pool.Acquire()
+		acquired = append(acquired, proxies(clients)...)
+		defer release()
+	}
+
+	if !slices.Equal(acquired, []string{"kr-3", "kr-2", "kr-1"}) {
+		t.Errorf("acquired %v, expected the freshest groups first", acquired)
+	}
+}
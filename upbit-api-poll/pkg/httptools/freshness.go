@@ -0,0 +1,105 @@
+package httptools
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	MetricUpbitNewsStaleness   = "upbit_news_staleness_seconds"
+	MetricUpbitNewsClockOffset = "upbit_news_clock_offset_seconds"
+
+	// freshnessSmoothing is the weight of a new sample in the moving averages of the staleness and the clock offset
+	freshnessSmoothing = 0.2
+)
+
+// StalenessBuckets are the histogram buckets of the staleness in seconds, CDN caches keep content for minutes
+var StalenessBuckets = []float64{0.5, 1, 2, 5, 10, 30, 60, 120, 300, 600}
+
+// Freshness tells how old the content of a response is
+type Freshness struct {
+	// Staleness is the time since the origin generated the content, on the local clock
+	Staleness time.Duration `json:"staleness"`
+	// Age is the time the content spent in the CDN cache
+	Age         time.Duration `json:"age"`
+	CacheStatus string        `json:"cache_status"`
+}
+
+// EvaluateFreshness estimates the freshness of the response from its Age, Date and Cf-Cache-Status headers.
+// The clock offset is the server clock minus the local clock.
+func EvaluateFreshness(response Response, clockOffset time.Duration) Freshness {
+	freshness := Freshness{CacheStatus: CacheStatus(response.Headers)}
+
+	if seconds, err := strconv.Atoi(response.Headers.Get("Age")); err == nil && seconds > 0 {
+		freshness.Age = time.Duration(seconds) * time.Second
+	}
+
+	freshness.Staleness = freshness.Age
+
+	date, err := http.ParseTime(response.Headers.Get("Date"))
+	if err != nil {
+		return freshness
+	}
+
+	// Date is when the response was served, the origin generated the content Age before it
+	generatedAt := date.Add(-clockOffset - freshness.Age)
+	if staleness := response.ReceivedAt.Sub(generatedAt); staleness > freshness.Staleness {
+		freshness.Staleness = staleness
+	}
+
+	return freshness
+}
+
+// clockOffsetEstimator estimates the offset of the server clock from the responses generated on request.
+// Date has a resolution of a second, so the samples are smoothed.
+type clockOffsetEstimator struct {
+	guard   sync.Mutex
+	offset  time.Duration
+	samples int
+}
+
+// observe takes a sample of the offset from a response which was not served from the cache
+func (e *clockOffsetEstimator) observe(response Response, freshness Freshness) {
+	if freshness.Age > 0 || isCacheHit(freshness.CacheStatus) {
+		return
+	}
+
+	date, err := http.ParseTime(response.Headers.Get("Date"))
+	if err != nil {
+		return
+	}
+
+	// The truncated Date is half a second earlier on average
+	midpoint := response.RequestedAt.Add(response.ReceivedAt.Sub(response.RequestedAt) / 2)
+	sample := date.Add(500 * time.Millisecond).Sub(midpoint)
+
+	e.guard.Lock()
+	defer e.guard.Unlock()
+
+	if e.samples == 0 {
+		e.offset = sample
+	} else {
+		e.offset += time.Duration(freshnessSmoothing * float64(sample-e.offset))
+	}
+
+	e.samples++
+}
+
+// Offset returns the estimated server clock minus the local clock
+func (e *clockOffsetEstimator) Offset() time.Duration {
+	e.guard.Lock()
+	defer e.guard.Unlock()
+
+	return e.offset
+}
+
+func isCacheHit(cacheStatus string) bool {
+	switch cacheStatus {
+	case "hit", "stale", "updating", "revalidated":
+		return true
+	default:
+		return false
+	}
+}
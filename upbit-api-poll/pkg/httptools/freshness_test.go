@@ -0,0 +1,76 @@
+package httptools
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEvaluateFreshness(t *testing.T) {
+	receivedAt := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	response := func(headers map[string]string) Response {
+		response := Response{
+			RequestedAt: receivedAt.Add(-100 * time.Millisecond),
+			ReceivedAt:  receivedAt,
+			Headers:     http.Header{},
+		}
+		for key, value := range headers {
+			response.Headers.Set(key, value)
+		}
+
+		return response
+	}
+
+	testCases := []struct {
+		name        string
+		headers     map[string]string
+		clockOffset time.Duration
+		expected    Freshness
+	}{
+		{
+			name:     "no headers",
+			expected: Freshness{CacheStatus: "none"},
+		},
+		{
+			name:     "cached",
+			headers:  map[string]string{"Age": "42", "Cf-Cache-Status": "HIT"},
+			expected: Freshness{Staleness: 42 * time.Second, Age: 42 * time.Second, CacheStatus: "hit"},
+		},
+		{
+			name:     "served a minute ago",
+			headers:  map[string]string{"Date": receivedAt.Add(-time.Minute).Format(http.TimeFormat)},
+			expected: Freshness{Staleness: time.Minute, CacheStatus: "none"},
+		},
+		{
+			name:        "server clock behind",
+			headers:     map[string]string{"Date": receivedAt.Add(-time.Minute).Format(http.TimeFormat), "Cf-Cache-Status": "DYNAMIC"},
+			clockOffset: -time.Minute,
+			expected:    Freshness{CacheStatus: "dynamic"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := EvaluateFreshness(response(tc.headers), tc.clockOffset); got != tc.expected {
+				t.Errorf("EvaluateFreshness() = %+v, expected %+v", got, tc.expected)
+			}
+		})
+	}
+
+	estimator := clockOffsetEstimator{}
+
+	behind := response(map[string]string{"Date": receivedAt.Add(-time.Minute).Format(http.TimeFormat)})
+	estimator.observe(behind, EvaluateFreshness(behind, 0))
+
+	if offset := estimator.Offset(); offset < -time.Minute || offset > -59*time.Second {
+		t.Errorf("Offset() = %s, expected about a minute behind", offset)
+	}
+
+	cached := response(map[string]string{"Date": receivedAt.Format(http.TimeFormat), "Age": "10"})
+	estimator.observe(cached, EvaluateFreshness(cached, 0))
+
+	if estimator.samples != 1 {
+		t.Errorf("samples = %d, expected the cached response to be ignored", estimator.samples)
+	}
+}
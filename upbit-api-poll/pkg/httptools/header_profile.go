@@ -0,0 +1,362 @@
+package httptools
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Header rotation modes
+const (
+	// HeaderRotationPerClient sends every request of a client with one profile picked at random
+	HeaderRotationPerClient = "per_client"
+	// HeaderRotationPerRequest picks a profile at random for every request
+	HeaderRotationPerRequest = "per_request"
+)
+
+// Kinds of the requests a header profile is sent with
+const (
+	// HeaderKindDocument is the browser opening a page, e.g. a notice
+	HeaderKindDocument = "document"
+	// HeaderKindAPI is a script of upbit.com fetching JSON from an API, e.g. the announcements
+	HeaderKindAPI = "api"
+)
+
+// HeaderProfile is a coherent set of browser headers. The headers are "Name: value" lines in the order they are sent.
+// Kind is the kind of the requests the profile is sent with, a document if empty.
+type HeaderProfile struct {
+	Name    string   `mapstructure:"name"    validate:"required"`
+	Kind    string   `mapstructure:"kind"    validate:"omitempty,oneof=document api"`
+	Headers []string `mapstructure:"headers" validate:"required,header_profile"`
+}
+
+// kind returns the kind of the requests of the profile
+func (p HeaderProfile) kind() string {
+	if p.Kind == "" {
+		return HeaderKindDocument
+	}
+
+	return p.Kind
+}
+
+// HeaderRotation assigns the header profiles to the clients.
+// DefaultHeaderProfiles are used for the kinds of requests without profiles.
+type HeaderRotation struct {
+	Mode     string          `mapstructure:"mode"     validate:"omitempty,oneof=per_client per_request"`
+	Profiles []HeaderProfile `mapstructure:"profiles" validate:"dive"`
+}
+
+// Header is a header of a profile
+type Header struct {
+	Name  string
+	Value string
+}
+
+// DefaultHeaderProfiles are the headers of current desktop browsers reloading a page or fetching
+// the API from upbit.com bypassing the cache
+var DefaultHeaderProfiles = []HeaderProfile{
+	{
+		Name: "chrome_136_windows",
+		Kind: HeaderKindDocument,
+		Headers: []string{
+			`Cache-Control: max-age=0`,
+			`sec-ch-ua: "Chromium";v="136", "Google Chrome";v="136", "Not.A/Brand";v="99"`,
+			`sec-ch-ua-mobile: ?0`,
+			`sec-ch-ua-platform: "Windows"`,
+			`Upgrade-Insecure-Requests: 1`,
+			`User-Agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36`,
+			`Accept: text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7`,
+			`Sec-Fetch-Site: none`,
+			`Sec-Fetch-Mode: navigate`,
+			`Sec-Fetch-User: ?1`,
+			`Sec-Fetch-Dest: document`,
+			`Accept-Encoding: gzip, deflate, br, zstd`,
+			`Accept-Language: ko-KR,ko;q=0.9,en-US;q=0.8,en;q=0.7`,
+			`Priority: u=0, i`,
+		},
+	},
+	{
+		Name: "chrome_136_macos",
+		Kind: HeaderKindDocument,
+		Headers: []string{
+			`Cache-Control: max-age=0`,
+			`sec-ch-ua: "Chromium";v="136", "Google Chrome";v="136", "Not.A/Brand";v="99"`,
+			`sec-ch-ua-mobile: ?0`,
+			`sec-ch-ua-platform: "macOS"`,
+			`Upgrade-Insecure-Requests: 1`,
+			`User-Agent: Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36`,
+			`Accept: text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7`,
+			`Sec-Fetch-Site: none`,
+			`Sec-Fetch-Mode: navigate`,
+			`Sec-Fetch-User: ?1`,
+			`Sec-Fetch-Dest: document`,
+			`Accept-Encoding: gzip, deflate, br, zstd`,
+			`Accept-Language: ko-KR,ko;q=0.9`,
+			`Priority: u=0, i`,
+		},
+	},
+	{
+		Name: "edge_136_windows",
+		Kind: HeaderKindDocument,
+		Headers: []string{
+			`Cache-Control: max-age=0`,
+			`sec-ch-ua: "Chromium";v="136", "Microsoft Edge";v="136", "Not.A/Brand";v="99"`,
+			`sec-ch-ua-mobile: ?0`,
+			`sec-ch-ua-platform: "Windows"`,
+			`Upgrade-Insecure-Requests: 1`,
+			`User-Agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36 Edg/136.0.0.0`,
+			`Accept: text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7`,
+			`Sec-Fetch-Site: none`,
+			`Sec-Fetch-Mode: navigate`,
+			`Sec-Fetch-User: ?1`,
+			`Sec-Fetch-Dest: document`,
+			`Accept-Encoding: gzip, deflate, br, zstd`,
+			`Accept-Language: ko,en;q=0.9,en-US;q=0.8`,
+			`Priority: u=0, i`,
+		},
+	},
+	{
+		Name: "firefox_138_windows",
+		Kind: HeaderKindDocument,
+		Headers: []string{
+			`User-Agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:138.0) Gecko/20100101 Firefox/138.0`,
+			`Accept: text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8`,
+			`Accept-Language: ko-KR,ko;q=0.8,en-US;q=0.5,en;q=0.3`,
+			`Accept-Encoding: gzip, deflate, br, zstd`,
+			`Upgrade-Insecure-Requests: 1`,
+			`Sec-Fetch-Dest: document`,
+			`Sec-Fetch-Mode: navigate`,
+			`Sec-Fetch-Site: none`,
+			`Sec-Fetch-User: ?1`,
+			`Priority: u=0, i`,
+			`Cache-Control: max-age=0`,
+		},
+	},
+	{
+		Name: "safari_18_macos",
+		Kind: HeaderKindDocument,
+		Headers: []string{
+			`Accept: text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8`,
+			`Sec-Fetch-Site: none`,
+			`Sec-Fetch-Mode: navigate`,
+			`User-Agent: Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.4 Safari/605.1.15`,
+			`Accept-Language: ko-KR,ko;q=0.9`,
+			`Sec-Fetch-Dest: document`,
+			`Accept-Encoding: gzip, deflate, br`,
+			`Cache-Control: max-age=0`,
+			`Priority: u=0, i`,
+		},
+	},
+	{
+		Name: "chrome_136_windows_api",
+		Kind: HeaderKindAPI,
+		Headers: []string{
+			`Pragma: no-cache`,
+			`Cache-Control: no-cache`,
+			`sec-ch-ua-platform: "Windows"`,
+			`User-Agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36`,
+			`Accept: application/json, text/plain, */*`,
+			`sec-ch-ua: "Chromium";v="136", "Google Chrome";v="136", "Not.A/Brand";v="99"`,
+			`sec-ch-ua-mobile: ?0`,
+			`Origin: https://upbit.com`,
+			`Sec-Fetch-Site: same-site`,
+			`Sec-Fetch-Mode: cors`,
+			`Sec-Fetch-Dest: empty`,
+			`Referer: https://upbit.com/`,
+			`Accept-Encoding: gzip, deflate, br, zstd`,
+			`Accept-Language: ko-KR,ko;q=0.9,en-US;q=0.8,en;q=0.7`,
+			`Priority: u=1, i`,
+		},
+	},
+	{
+		Name: "chrome_136_macos_api",
+		Kind: HeaderKindAPI,
+		Headers: []string{
+			`Pragma: no-cache`,
+			`Cache-Control: no-cache`,
+			`sec-ch-ua-platform: "macOS"`,
+			`User-Agent: Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36`,
+			`Accept: application/json, text/plain, */*`,
+			`sec-ch-ua: "Chromium";v="136", "Google Chrome";v="136", "Not.A/Brand";v="99"`,
+			`sec-ch-ua-mobile: ?0`,
+			`Origin: https://upbit.com`,
+			`Sec-Fetch-Site: same-site`,
+			`Sec-Fetch-Mode: cors`,
+			`Sec-Fetch-Dest: empty`,
+			`Referer: https://upbit.com/`,
+			`Accept-Encoding: gzip, deflate, br, zstd`,
+			`Accept-Language: ko-KR,ko;q=0.9`,
+			`Priority: u=1, i`,
+		},
+	},
+	{
+		Name: "edge_136_windows_api",
+		Kind: HeaderKindAPI,
+		Headers: []string{
+			`Pragma: no-cache`,
+			`Cache-Control: no-cache`,
+			`sec-ch-ua-platform: "Windows"`,
+			`User-Agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36 Edg/136.0.0.0`,
+			`Accept: application/json, text/plain, */*`,
+			`sec-ch-ua: "Chromium";v="136", "Microsoft Edge";v="136", "Not.A/Brand";v="99"`,
+			`sec-ch-ua-mobile: ?0`,
+			`Origin: https://upbit.com`,
+			`Sec-Fetch-Site: same-site`,
+			`Sec-Fetch-Mode: cors`,
+			`Sec-Fetch-Dest: empty`,
+			`Referer: https://upbit.com/`,
+			`Accept-Encoding: gzip, deflate, br, zstd`,
+			`Accept-Language: ko,en;q=0.9,en-US;q=0.8`,
+			`Priority: u=1, i`,
+		},
+	},
+	{
+		Name: "firefox_138_windows_api",
+		Kind: HeaderKindAPI,
+		Headers: []string{
+			`User-Agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:138.0) Gecko/20100101 Firefox/138.0`,
+			`Accept: application/json, text/plain, */*`,
+			`Accept-Language: ko-KR,ko;q=0.8,en-US;q=0.5,en;q=0.3`,
+			`Accept-Encoding: gzip, deflate, br, zstd`,
+			`Referer: https://upbit.com/`,
+			`Origin: https://upbit.com`,
+			`Sec-Fetch-Dest: empty`,
+			`Sec-Fetch-Mode: cors`,
+			`Sec-Fetch-Site: same-site`,
+			`Pragma: no-cache`,
+			`Cache-Control: no-cache`,
+			`Priority: u=4`,
+		},
+	},
+	{
+		Name: "safari_18_macos_api",
+		Kind: HeaderKindAPI,
+		Headers: []string{
+			`Accept: application/json, text/plain, */*`,
+			`Origin: https://upbit.com`,
+			`Sec-Fetch-Site: same-site`,
+			`Sec-Fetch-Mode: cors`,
+			`User-Agent: Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.4 Safari/605.1.15`,
+			`Referer: https://upbit.com/`,
+			`Sec-Fetch-Dest: empty`,
+			`Accept-Language: ko-KR,ko;q=0.9`,
+			`Cache-Control: no-cache`,
+			`Pragma: no-cache`,
+			`Priority: u=3, i`,
+			`Accept-Encoding: gzip, deflate, br`,
+		},
+	},
+}
+
+// supportedEncodings are the content encodings the clients decode
+var supportedEncodings = []string{"gzip", "deflate", "br", "zstd", "identity"}
+
+// ParseHeaders parses the header lines of a profile. A profile must send a User-Agent
+// and accept only the encodings the clients decode.
+func ParseHeaders(lines []string) ([]Header, error) {
+	headers := make([]Header, 0, len(lines))
+	userAgent := false
+
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("header %q is not in the \"Name: value\" format", line)
+		}
+
+		switch http.CanonicalHeaderKey(name) {
+		case "User-Agent":
+			userAgent = value != ""
+		case "Accept-Encoding":
+			for _, encoding := range strings.Split(value, ",") {
+				encoding, _, _ = strings.Cut(strings.TrimSpace(encoding), ";")
+				if !slices.ContainsFunc(supportedEncodings, func(supported string) bool {
+					return strings.EqualFold(supported, encoding)
+				}) {
+					return nil, fmt.Errorf("encoding %q is not supported by the clients", encoding)
+				}
+			}
+		case "Host", "Content-Length", "Connection":
+			return nil, fmt.Errorf("header %s is set by the clients", name)
+		}
+
+		headers = append(headers, Header{Name: name, Value: value})
+	}
+
+	if !userAgent {
+		return nil, errors.New("profile has no User-Agent")
+	}
+
+	return headers, nil
+}
+
+// headerPicker picks the header profile of the requests of a client
+type headerPicker struct {
+	names    []string
+	profiles [][]Header
+	// profile is the index of the profile of the client, -1 when a profile is picked per request
+	profile int
+}
+
+// newHeaderPicker picks from the profiles of the kind of requests, a document if the kind is empty
+func newHeaderPicker(rotation HeaderRotation, kind string) (*headerPicker, error) {
+	if kind == "" {
+		kind = HeaderKindDocument
+	}
+
+	profiles := profilesOfKind(rotation.Profiles, kind)
+	if len(profiles) == 0 {
+		profiles = profilesOfKind(DefaultHeaderProfiles, kind)
+	}
+
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no header profiles for %s requests", kind)
+	}
+
+	picker := &headerPicker{
+		names:    make([]string, 0, len(profiles)),
+		profiles: make([][]Header, 0, len(profiles)),
+		profile:  -1,
+	}
+
+	for _, profile := range profiles {
+		headers, err := ParseHeaders(profile.Headers)
+		if err != nil {
+			return nil, fmt.Errorf("header profile %s: %w", profile.Name, err)
+		}
+
+		picker.names = append(picker.names, profile.Name)
+		picker.profiles = append(picker.profiles, headers)
+	}
+
+	if rotation.Mode != HeaderRotationPerRequest {
+		picker.profile = rand.IntN(len(picker.profiles))
+	}
+
+	return picker, nil
+}
+
+func profilesOfKind(profiles []HeaderProfile, kind string) []HeaderProfile {
+	ofKind := make([]HeaderProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		if profile.kind() == kind {
+			ofKind = append(ofKind, profile)
+		}
+	}
+
+	return ofKind
+}
+
+// pick returns the name and the headers of the profile of the next request
+func (p *headerPicker) pick() (string, []Header) {
+	i := p.profile
+	if i < 0 {
+		i = rand.IntN(len(p.profiles))
+	}
+
+	return p.names[i], p.profiles[i]
+}
@@ -0,0 +1,215 @@
+package httptools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestParseHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		want    []Header
+		wantErr bool
+	}{
+		{
+			name:  "keeps order and values",
+			lines: []string{`sec-ch-ua: "Chromium";v="136"`, "User-Agent: Mozilla/5.0", "Accept:  */*"},
+			want: []Header{
+				{Name: "sec-ch-ua", Value: `"Chromium";v="136"`},
+				{Name: "User-Agent", Value: "Mozilla/5.0"},
+				{Name: "Accept", Value: "*/*"},
+			},
+		},
+		{
+			name:  "colon in value",
+			lines: []string{"User-Agent: Mozilla/5.0 (rv:138.0)"},
+			want:  []Header{{Name: "User-Agent", Value: "Mozilla/5.0 (rv:138.0)"}},
+		},
+		{
+			name:    "no user agent",
+			lines:   []string{"Accept: */*"},
+			wantErr: true,
+		},
+		{
+			name:    "not a header",
+			lines:   []string{"User-Agent: Mozilla/5.0", "Accept */*"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported encoding",
+			lines:   []string{"User-Agent: Mozilla/5.0", "Accept-Encoding: gzip, compress"},
+			wantErr: true,
+		},
+		{
+			name:    "header set by the clients",
+			lines:   []string{"User-Agent: Mozilla/5.0", "host: upbit.com"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHeaders(tt.lines)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("parsed %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("header %d is %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultHeaderProfiles(t *testing.T) {
+	for _, profile := range DefaultHeaderProfiles {
+		headers, err := ParseHeaders(profile.Headers)
+		if err != nil {
+			t.Errorf("profile %s: %v", profile.Name, err)
+			continue
+		}
+
+		values := http.Header{}
+		for _, header := range headers {
+			values.Add(header.Name, header.Value)
+		}
+
+		want := map[string]string{
+			HeaderKindDocument: "navigate",
+			HeaderKindAPI:      "cors",
+		}[profile.Kind]
+		if mode := values.Get("Sec-Fetch-Mode"); mode != want {
+			t.Errorf("%s profile %s fetches in %q mode, want %q", profile.Kind, profile.Name, mode, want)
+		}
+
+		if api := profile.Kind == HeaderKindAPI; api != (values.Get("Origin") != "") || api != (values.Get("Referer") != "") {
+			t.Errorf("%s profile %s has Origin %q and Referer %q", profile.Kind, profile.Name, values.Get("Origin"), values.Get("Referer"))
+		}
+	}
+}
+
+func TestHeaderPicker_Kinds(t *testing.T) {
+	profiles := []HeaderProfile{
+		{Name: "page", Headers: []string{"User-Agent: page"}},
+		{Name: "api", Kind: HeaderKindAPI, Headers: []string{"User-Agent: api"}},
+	}
+
+	for kind, want := range map[string]string{"": "page", HeaderKindDocument: "page", HeaderKindAPI: "api"} {
+		picker, err := newHeaderPicker(HeaderRotation{Mode: HeaderRotationPerRequest, Profiles: profiles}, kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for range 10 {
+			if name, _ := picker.pick(); name != want {
+				t.Fatalf("picked %s for %q requests, want %s", name, kind, want)
+			}
+		}
+	}
+
+	// The kinds without configured profiles use the default ones
+	picker, err := newHeaderPicker(HeaderRotation{Profiles: profiles[:1]}, HeaderKindAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, _ := picker.pick()
+	if !slices.ContainsFunc(DefaultHeaderProfiles, func(profile HeaderProfile) bool {
+		return profile.Name == name && profile.Kind == HeaderKindAPI
+	}) {
+		t.Errorf("picked %s for API requests without API profiles, want a default API profile", name)
+	}
+}
+
+func TestHeaderPicker(t *testing.T) {
+	profiles := []HeaderProfile{
+		{Name: "a", Headers: []string{"User-Agent: a"}},
+		{Name: "b", Headers: []string{"User-Agent: b"}},
+		{Name: "c", Headers: []string{"User-Agent: c"}},
+	}
+
+	perClient, err := newHeaderPicker(HeaderRotation{Mode: HeaderRotationPerClient, Profiles: profiles}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := perClient.pick()
+	for range 20 {
+		if name, _ := perClient.pick(); name != first {
+			t.Fatalf("per client picker switched from %s to %s", first, name)
+		}
+	}
+
+	perRequest, err := newHeaderPicker(HeaderRotation{Mode: HeaderRotationPerRequest, Profiles: profiles}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	picked := map[string]bool{}
+	for range 100 {
+		name, headers := perRequest.pick()
+		if headers[0].Value != name {
+			t.Fatalf("profile %s has the headers of %s", name, headers[0].Value)
+		}
+
+		picked[name] = true
+	}
+
+	if len(picked) != len(profiles) {
+		t.Errorf("per request picker used %d of %d profiles", len(picked), len(profiles))
+	}
+
+	if _, err := newHeaderPicker(HeaderRotation{Profiles: []HeaderProfile{{Name: "bad", Headers: []string{"Accept: */*"}}}}, ""); err == nil {
+		t.Error("picker accepted a profile without User-Agent")
+	}
+}
+
+func TestClientHTTP2_SendsProfile(t *testing.T) {
+	received := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client, err := NewClientHTTP2(ClientConfig{
+		Metrics: noopMetrics{},
+		Headers: HeaderRotation{Profiles: []HeaderProfile{{
+			Name:    "test_browser",
+			Headers: []string{`sec-ch-ua-platform: "Windows"`, "User-Agent: TestBrowser/1.0", "Accept-Language: ko-KR"},
+		}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.Request(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.HeaderProfile != "test_browser" {
+		t.Errorf("response profile is %q, want test_browser", response.HeaderProfile)
+	}
+
+	headers := <-received
+	for name, want := range map[string]string{
+		"Sec-Ch-Ua-Platform": `"Windows"`,
+		"User-Agent":         "TestBrowser/1.0",
+		"Accept-Language":    "ko-KR",
+		"Origin":             "",
+	} {
+		if got := headers.Get(name); got != want {
+			t.Errorf("header %s is %q, want %q", name, got, want)
+		}
+	}
+}
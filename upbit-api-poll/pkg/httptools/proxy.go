@@ -1,6 +1,11 @@
 package httptools
 
-import "fmt"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+)
 
 type Proxy struct {
 	Username string `json:"username" validate:"required"`
@@ -12,3 +17,32 @@ type Proxy struct {
 func (p Proxy) String() string {
 	return fmt.Sprintf("http://%s:%s@%s:%d", p.Username, p.Password, p.Host, p.Port)
 }
+
+// Redacted returns the proxy address without credentials, safe for logs and messages
+func (p Proxy) Redacted() string {
+	return fmt.Sprintf("%s:%d", p.Host, p.Port)
+}
+
+// RedactProxyAddress removes credentials from a proxy URL as returned by Client.ProxyAddress.
+func RedactProxyAddress(address string) string {
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return address
+	}
+
+	return u.Host
+}
+
+// ProxyID identifies a proxy in the metric labels. It is the host of the proxy with a short hash
+// of the whole address, so the proxies sharing a host with different credentials are told apart
+// without exposing the credentials.
+func ProxyID(address string) string {
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return address
+	}
+
+	hash := sha256.Sum256([]byte(address))
+
+	return u.Host + "#" + hex.EncodeToString(hash[:4])
+}
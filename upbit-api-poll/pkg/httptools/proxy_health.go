@@ -0,0 +1,147 @@
+package httptools
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProxyHealth is the request statistics of one proxy. The address is redacted.
+type ProxyHealth struct {
+	Proxy           string    `json:"proxy"`
+	Requests        uint64    `json:"requests"`
+	Errors          uint64    `json:"errors"`
+	TooManyRequests uint64    `json:"too_many_requests"`
+	LastStatus      int       `json:"last_status"`
+	LastError       string    `json:"last_error,omitempty"`
+	LastRequestAt   time.Time `json:"last_request_at"`
+	LastSuccessAt   time.Time `json:"last_success_at"`
+	// Staleness is the moving average of the staleness of the responses through the proxy
+	Staleness time.Duration `json:"staleness"`
+	// freshnessSamples is the number of responses in the staleness
+	freshnessSamples int
+}
+
+// Healthy reports whether the last request through the proxy succeeded.
+// A proxy which has not been used yet is considered healthy.
+func (h ProxyHealth) Healthy() bool {
+	if h.LastRequestAt.IsZero() {
+		return true
+	}
+
+	return h.LastError == "" &&
+		h.LastStatus != http.StatusTooManyRequests &&
+		h.LastStatus < http.StatusInternalServerError
+}
+
+type proxyHealthTracker struct {
+	guard   sync.Mutex
+	proxies map[string]*ProxyHealth
+}
+
+func newProxyHealthTracker(clients []Client) *proxyHealthTracker {
+	t := &proxyHealthTracker{proxies: make(map[string]*ProxyHealth, len(clients))}
+	for _, client := range clients {
+		t.add(client.ProxyAddress())
+	}
+
+	return t
+}
+
+func (t *proxyHealthTracker) add(proxyAddr string) *ProxyHealth {
+	health, ok := t.proxies[proxyAddr]
+	if !ok {
+		health = &ProxyHealth{Proxy: RedactProxyAddress(proxyAddr)}
+		t.proxies[proxyAddr] = health
+	}
+
+	return health
+}
+
+// track starts tracking a proxy added to the pool
+func (t *proxyHealthTracker) track(proxyAddr string) {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+
+	t.add(proxyAddr)
+}
+
+// forget stops tracking a proxy removed from the pool
+func (t *proxyHealthTracker) forget(proxyAddr string) {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+
+	delete(t.proxies, proxyAddr)
+}
+
+func (t *proxyHealthTracker) record(proxyAddr string, response Response, err error) {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+
+	health := t.add(proxyAddr)
+	health.Requests++
+	health.LastRequestAt = time.Now()
+
+	if err != nil {
+		health.Errors++
+		health.LastError = err.Error()
+		health.LastStatus = 0
+		return
+	}
+
+	health.LastError = ""
+	health.LastStatus = response.StatusCode
+
+	if response.IsTooManyRequests() {
+		health.TooManyRequests++
+	}
+
+	if (ProxyHealth{LastRequestAt: health.LastRequestAt, LastStatus: response.StatusCode}).Healthy() {
+		health.LastSuccessAt = health.LastRequestAt
+	}
+}
+
+// recordFreshness adds the freshness of a response through the proxy to its staleness
+func (t *proxyHealthTracker) recordFreshness(proxyAddr string, freshness Freshness) {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+
+	health := t.add(proxyAddr)
+	if health.freshnessSamples == 0 {
+		health.Staleness = freshness.Staleness
+	} else {
+		health.Staleness += time.Duration(freshnessSmoothing * float64(freshness.Staleness-health.Staleness))
+	}
+
+	health.freshnessSamples++
+}
+
+// staleness returns the staleness of the responses through the proxy, zero for an unknown proxy
+func (t *proxyHealthTracker) staleness(proxyAddr string) time.Duration {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+
+	if health, ok := t.proxies[proxyAddr]; ok {
+		return health.Staleness
+	}
+
+	return 0
+}
+
+func (t *proxyHealthTracker) snapshot() []ProxyHealth {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+
+	proxies := make([]ProxyHealth, 0, len(t.proxies))
+	for _, health := range t.proxies {
+		proxies = append(proxies, *health)
+	}
+
+	slices.SortFunc(proxies, func(a, b ProxyHealth) int {
+		return strings.Compare(a.Proxy, b.Proxy)
+	})
+
+	return proxies
+}
@@ -4,40 +4,73 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html"
 	"log/slog"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
-	ErrAlreadyPolling = errors.New("already polling")
-	ErrNoProxies      = errors.New("no proxies were provided")
+	ErrAlreadyPolling   = errors.New("already polling")
+	ErrNoProxies        = errors.New("no proxies were provided")
+	ErrTargetRPSTooHigh = errors.New("target rps exceeds the capacity of the proxies")
 )
 
 const (
 	MetricUpbitNewsRequestsTotal   = "upbit_news_requests_total"
 	MetricUpbitNewsErrorsTotal     = "upbit_news_errors_total"
 	MetricUpbitNewsFetchedTotal    = "upbit_news_fetched_total"
-	MetricUpbitNewsRequestDuration = "upbit_news_request_duration"
-	MetricUpbitNewsPollDuration    = "upbit_news_poll_duration"
+	MetricUpbitNewsRequestDuration = "upbit_news_request_duration_seconds"
+	MetricUpbitNewsPollDuration    = "upbit_news_poll_duration_seconds"
+	MetricUpbitNewsRPSProfile      = "upbit_news_rps_profile"
+	MetricUpbitNewsTargetRPS       = "upbit_news_target_rps"
 )
 
+// DefaultRPSProfile is the profile of a poller without a work schedule
+const DefaultRPSProfile = "default"
+
 // ProxyRotatingPoller is a poller that rotates through a list of proxies to poll news.
 // To initialize it, use ProxyRotatingPollerBuilder.
 type ProxyRotatingPoller struct {
+	// name is the polled endpoint in the metrics
+	name string
+
 	urlGuard sync.RWMutex
-	url      string
+	// urls are polled in turn, one per request
+	urls        []string
+	urlsCursor  atomic.Uint64
+	cacheBuster CacheBuster
 
+	rpsGuard          sync.RWMutex
 	targetRPS         float64
 	singleProxyMaxRPS float64
+	// profile is the RPS profile of the work schedule polled with,
+	// its target RPS takes the place of the configured one unless it's zero
+	profile          string
+	profileTargetRPS float64
 
+	// membershipGuard serializes changes of the proxies
+	membershipGuard   sync.Mutex
 	clientsByLocation ClientsPool
-	totalClientsCount int
+	initProxyClientFn func(proxy Proxy) (Client, error)
+	proxiesHealth     *proxyHealthTracker
+	clockOffset       clockOffsetEstimator
+
+	pauseGuard sync.Mutex
+	// resumed is closed on resume, nil while the poller is not paused
+	resumed chan struct{}
 
 	notifier Notifier
 
-	workSchedule WorkSchedule
+	workScheduleGuard sync.RWMutex
+	workSchedule      WorkSchedule
+	// wakeup interrupts the sleep till the next work session when the schedule is changed or the poller is resumed
+	wakeup chan struct{}
 
 	running atomic.Bool
 
@@ -53,6 +86,11 @@ type Client interface {
 
 type ClientsPool interface {
 	Acquire() ([]Client, ReleaseFunc)
+	Add(client Client) error
+	Remove(ctx context.Context, proxyAddr string) error
+	Clients() []Client
+	SetRestInterval(interval time.Duration)
+	SetStaleness(staleness func(proxyAddr string) time.Duration)
 	Len() int
 }
 
@@ -79,6 +117,9 @@ type Metrics interface {
 type WorkSchedule interface {
 	WorkNow() bool
 	NextWorkSession() (time.Duration, error)
+	// ProfileNow returns the name and the target RPS of the current work window,
+	// zero target RPS keeps the configured one
+	ProfileNow() (string, float64)
 }
 
 type Timer interface {
@@ -86,20 +127,371 @@ type Timer interface {
 }
 
 func (p *ProxyRotatingPoller) SetURL(url string) {
+	p.SetURLs(url)
+}
+
+// SetURLs replaces the polled URLs, the requests are spread across them in the order.
+// A URL listed several times is polled more often.
+func (p *ProxyRotatingPoller) SetURLs(urls ...string) {
 	p.urlGuard.Lock()
 	defer p.urlGuard.Unlock()
 
-	p.url = url
+	p.urls = slices.Clone(urls)
+	p.urlsCursor.Store(0)
 
 	// p.notifier.SendMessage("Polling URL changed to %s", url)
 }
 
+// URL returns the first polled URL
+func (p *ProxyRotatingPoller) URL() string {
+	p.urlGuard.RLock()
+	defer p.urlGuard.RUnlock()
+
+	return p.urls[0]
+}
+
+// URLs returns the polled URLs
+func (p *ProxyRotatingPoller) URLs() []string {
+	p.urlGuard.RLock()
+	defer p.urlGuard.RUnlock()
+
+	return slices.Clone(p.urls)
+}
+
+// nextURL returns the URL for the next request
+func (p *ProxyRotatingPoller) nextURL() string {
+	p.urlGuard.RLock()
+	defer p.urlGuard.RUnlock()
+
+	return p.urls[(p.urlsCursor.Add(1)-1)%uint64(len(p.urls))]
+}
+
+// TargetRPS returns the current target requests per second
+func (p *ProxyRotatingPoller) TargetRPS() float64 {
+	p.rpsGuard.RLock()
+	defer p.rpsGuard.RUnlock()
+
+	return p.targetRPS
+}
+
+// SingleProxyMaxRPS returns the highest RPS of one proxy
+func (p *ProxyRotatingPoller) SingleProxyMaxRPS() float64 {
+	p.rpsGuard.RLock()
+	defer p.rpsGuard.RUnlock()
+
+	return p.singleProxyMaxRPS
+}
+
+// MaxRPS returns the highest target RPS the proxies can sustain
+func (p *ProxyRotatingPoller) MaxRPS() float64 {
+	return float64(p.clientsByLocation.Len()) * p.SingleProxyMaxRPS()
+}
+
+// SetTargetRPS changes the target RPS, the new polling interval is applied from the next poll.
+func (p *ProxyRotatingPoller) SetTargetRPS(targetRPS float64) error {
+	if targetRPS <= 0 {
+		return ErrTargetRPSRequired
+	}
+
+	p.membershipGuard.Lock()
+	defer p.membershipGuard.Unlock()
+
+	if maxRPS := p.MaxRPS(); targetRPS > maxRPS {
+		return fmt.Errorf("%w: %.2f > %.2f", ErrTargetRPSTooHigh, targetRPS, maxRPS)
+	}
+
+	p.rpsGuard.Lock()
+	p.targetRPS = targetRPS
+	p.rpsGuard.Unlock()
+
+	p.reportTargetRPS()
+
+	return nil
+}
+
+// SetSingleProxyMaxRPS changes the highest RPS of one proxy, every proxy rests 1/rps between requests.
+func (p *ProxyRotatingPoller) SetSingleProxyMaxRPS(singleProxyMaxRPS float64) error {
+	if singleProxyMaxRPS <= 0 {
+		return ErrSingleProxyMaxRPSRequired
+	}
+
+	p.membershipGuard.Lock()
+	defer p.membershipGuard.Unlock()
+
+	maxRPS := float64(p.clientsByLocation.Len()) * singleProxyMaxRPS
+	if targetRPS := p.TargetRPS(); targetRPS > maxRPS {
+		return fmt.Errorf("%w: %.2f > %.2f", ErrTargetRPSTooHigh, targetRPS, maxRPS)
+	}
+
+	p.rpsGuard.Lock()
+	p.singleProxyMaxRPS = singleProxyMaxRPS
+	p.rpsGuard.Unlock()
+
+	p.clientsByLocation.SetRestInterval(time.Duration(float64(time.Second) / singleProxyMaxRPS))
+
+	p.reportTargetRPS()
+
+	return nil
+}
+
+// AddProxies creates clients for the proxies and adds them to the pool. They are used from the next poll.
+// Proxies added before an error stay in the pool.
+func (p *ProxyRotatingPoller) AddProxies(proxies ...Proxy) error {
+	p.membershipGuard.Lock()
+	defer p.membershipGuard.Unlock()
+
+	for _, proxy := range proxies {
+		client, err := p.initProxyClientFn(proxy)
+		if err != nil {
+			return fmt.Errorf("init client of proxy %s: %w", proxy.Redacted(), err)
+		}
+
+		if err := p.clientsByLocation.Add(client); err != nil {
+			return fmt.Errorf("add proxy %s: %w", proxy.Redacted(), err)
+		}
+
+		p.proxiesHealth.track(client.ProxyAddress())
+		p.logger.Info("Proxy added", "proxy", proxy.Redacted())
+	}
+
+	return nil
+}
+
+// RemoveProxies takes the proxies with the redacted addresses (host:port) out of the pool
+// and waits until their requests in flight are completed or the context is done.
+// The proxies left must sustain the target RPS.
+func (p *ProxyRotatingPoller) RemoveProxies(ctx context.Context, addresses ...string) error {
+	p.membershipGuard.Lock()
+	defer p.membershipGuard.Unlock()
+
+	clients := p.clientsByLocation.Clients()
+
+	proxyAddrs := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		i := slices.IndexFunc(clients, func(client Client) bool {
+			return RedactProxyAddress(client.ProxyAddress()) == address
+		})
+		if i < 0 {
+			return fmt.Errorf("%w: %s", ErrUnknownProxy, address)
+		}
+
+		proxyAddrs = append(proxyAddrs, clients[i].ProxyAddress())
+	}
+
+	maxRPS := float64(len(clients)-len(proxyAddrs)) * p.SingleProxyMaxRPS()
+	if targetRPS := p.TargetRPS(); targetRPS > maxRPS {
+		return fmt.Errorf("%w: %.2f > %.2f", ErrTargetRPSTooHigh, targetRPS, maxRPS)
+	}
+
+	for _, proxyAddr := range proxyAddrs {
+		if err := p.clientsByLocation.Remove(ctx, proxyAddr); err != nil {
+			return fmt.Errorf("remove proxy %s: %w", RedactProxyAddress(proxyAddr), err)
+		}
+
+		p.proxiesHealth.forget(proxyAddr)
+		p.logger.Info("Proxy removed", "proxy", RedactProxyAddress(proxyAddr))
+	}
+
+	return nil
+}
+
+// Profile returns the name of the RPS profile of the work schedule polled with
+func (p *ProxyRotatingPoller) Profile() string {
+	p.rpsGuard.RLock()
+	defer p.rpsGuard.RUnlock()
+
+	return p.profile
+}
+
+// EffectiveTargetRPS returns the RPS polled with: the target RPS of the profile or the configured one,
+// limited by the capacity of the proxies
+func (p *ProxyRotatingPoller) EffectiveTargetRPS() float64 {
+	p.rpsGuard.RLock()
+	targetRPS := p.targetRPS
+	if p.profileTargetRPS > 0 {
+		targetRPS = p.profileTargetRPS
+	}
+	p.rpsGuard.RUnlock()
+
+	if maxRPS := p.MaxRPS(); maxRPS > 0 {
+		return min(targetRPS, maxRPS)
+	}
+
+	return targetRPS
+}
+
+// applyProfile switches to the RPS profile of the current work window
+func (p *ProxyRotatingPoller) applyProfile() {
+	profile, targetRPS := p.schedule().ProfileNow()
+
+	p.rpsGuard.Lock()
+	previous := p.profile
+	p.profile = profile
+	p.profileTargetRPS = targetRPS
+	p.rpsGuard.Unlock()
+
+	if profile == previous {
+		return
+	}
+
+	if previous != "" {
+		p.metrics.SetGauge(MetricUpbitNewsRPSProfile, 0, "endpoint", p.name, "profile", previous)
+	}
+
+	p.metrics.SetGauge(MetricUpbitNewsRPSProfile, 1, "endpoint", p.name, "profile", profile)
+	p.reportTargetRPS()
+
+	effectiveTargetRPS := p.EffectiveTargetRPS()
+	if targetRPS > effectiveTargetRPS {
+		p.logger.Warn(
+			"RPS profile exceeds the capacity of the proxies",
+			"profile", profile,
+			"target_rps", targetRPS,
+			"max_rps", effectiveTargetRPS,
+		)
+	}
+
+	p.logger.Info("RPS profile switched", "profile", profile, "previous", previous, "target_rps", effectiveTargetRPS)
+}
+
+func (p *ProxyRotatingPoller) reportTargetRPS() {
+	p.metrics.SetGauge(MetricUpbitNewsTargetRPS, p.EffectiveTargetRPS(), "endpoint", p.name)
+}
+
+func (p *ProxyRotatingPoller) pollingInterval() time.Duration {
+	return time.Duration(float64(time.Second) / p.EffectiveTargetRPS())
+}
+
+// Pause stops sending new requests until Resume. Requests in flight are completed.
+// It returns false if the poller is already paused.
+func (p *ProxyRotatingPoller) Pause() bool {
+	p.pauseGuard.Lock()
+	defer p.pauseGuard.Unlock()
+
+	if p.resumed != nil {
+		return false
+	}
+
+	p.resumed = make(chan struct{})
+
+	return true
+}
+
+// Resume continues polling after Pause. It returns false if the poller is not paused.
+func (p *ProxyRotatingPoller) Resume() bool {
+	p.pauseGuard.Lock()
+	defer p.pauseGuard.Unlock()
+
+	if p.resumed == nil {
+		return false
+	}
+
+	close(p.resumed)
+	p.resumed = nil
+	p.wake()
+
+	return true
+}
+
+// Paused reports whether the poller is paused
+func (p *ProxyRotatingPoller) Paused() bool {
+	p.pauseGuard.Lock()
+	defer p.pauseGuard.Unlock()
+
+	return p.resumed != nil
+}
+
+// Working reports whether the poller polls now, so it's not paused and within the work schedule
+func (p *ProxyRotatingPoller) Working() bool {
+	if p.Paused() {
+		return false
+	}
+
+	schedule := p.schedule()
+
+	return schedule == nil || schedule.WorkNow()
+}
+
+// SetWorkSchedule replaces the work schedule, it is checked before the next poll
+func (p *ProxyRotatingPoller) SetWorkSchedule(workSchedule WorkSchedule) {
+	p.workScheduleGuard.Lock()
+	defer p.workScheduleGuard.Unlock()
+
+	p.workSchedule = workSchedule
+	p.wake()
+}
+
+// wake interrupts the sleep till the next work session, so the poller checks the schedule again
+func (p *ProxyRotatingPoller) wake() {
+	select {
+	case p.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// sleep waits for the duration or until the poller is woken up.
+// It returns false if the context is done.
+func (p *ProxyRotatingPoller) sleep(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	case <-p.wakeup:
+	}
+
+	return true
+}
+
+func (p *ProxyRotatingPoller) schedule() WorkSchedule {
+	p.workScheduleGuard.RLock()
+	defer p.workScheduleGuard.RUnlock()
+
+	return p.workSchedule
+}
+
+// ProxyAddresses returns the addresses of the proxies the poller rotates through, with their credentials
+func (p *ProxyRotatingPoller) ProxyAddresses() []string {
+	clients := p.clientsByLocation.Clients()
+
+	addresses := make([]string, 0, len(clients))
+	for _, client := range clients {
+		addresses = append(addresses, client.ProxyAddress())
+	}
+
+	return addresses
+}
+
+// ProxiesCount returns the number of proxies the poller rotates through
+func (p *ProxyRotatingPoller) ProxiesCount() int {
+	return p.clientsByLocation.Len()
+}
+
+// ProxiesHealth returns the request statistics of every proxy ordered by redacted address
+func (p *ProxyRotatingPoller) ProxiesHealth() []ProxyHealth {
+	return p.proxiesHealth.snapshot()
+}
+
+// LastSuccessAt returns the time of the last successful response through any proxy, zero without one
+func (p *ProxyRotatingPoller) LastSuccessAt() time.Time {
+	last := time.Time{}
+	for _, health := range p.proxiesHealth.snapshot() {
+		if health.LastSuccessAt.After(last) {
+			last = health.LastSuccessAt
+		}
+	}
+
+	return last
+}
+
 func (p *ProxyRotatingPoller) StartPolling(ctx context.Context) (<-chan Response, error) {
 	if !p.running.CompareAndSwap(false, true) {
 		return nil, ErrAlreadyPolling
 	}
 
-	responsesChan := make(chan Response, p.totalClientsCount<<1)
+	responsesChan := make(chan Response, p.clientsByLocation.Len()<<1)
 
 	go func() {
 		defer close(responsesChan)
@@ -146,23 +538,25 @@ func (p *ProxyRotatingPoller) populateWithResponses(
 	ctx context.Context,
 	responsesChan chan<- Response,
 ) error {
-	pollingInterval := time.Duration(float64(time.Second) / p.targetRPS)
+	p.applyProfile()
+
+	pollingInterval := p.pollingInterval()
 	p.logger.Info("Polling news with interval", "interval", pollingInterval.String())
 
 	p.notifier.SendMessage(
 		"Service is running.\nTarget RPS: %f\nSingle proxy max RPS: %f\nProxies count: %d\nPolling interval: %s\nURL: %s",
-		p.targetRPS,
-		p.singleProxyMaxRPS,
-		p.totalClientsCount,
+		p.TargetRPS(),
+		p.SingleProxyMaxRPS(),
+		p.clientsByLocation.Len(),
 		pollingInterval.String(),
-		p.url,
+		html.EscapeString(p.URL()),
 	)
 
 	wg := sync.WaitGroup{}
 	workDebt := time.Duration(0)
 
 	nextWorkSession := func() time.Duration {
-		sleepTillNextWorkTime, err := p.workSchedule.NextWorkSession()
+		sleepTillNextWorkTime, err := p.schedule().NextWorkSession()
 		if err != nil {
 			p.logger.Error("Failed to get next work session", "error", err)
 			return 0
@@ -176,8 +570,28 @@ func (p *ProxyRotatingPoller) populateWithResponses(
 	failedToFetchResponseCount := atomic.Int64{}
 
 	for {
-		if !p.workSchedule.WorkNow() {
-			sleepTillNextWorkTime, err := p.workSchedule.NextWorkSession()
+		p.pauseGuard.Lock()
+		resumed := p.resumed
+		p.pauseGuard.Unlock()
+
+		if resumed != nil {
+			p.logger.Info("Polling paused")
+
+			select {
+			case <-ctx.Done():
+				wg.Wait()
+				return ctx.Err()
+			case <-resumed:
+			}
+
+			p.logger.Info("Polling resumed")
+
+			lastPollStartTime = time.Now()
+			workDebt = 0
+		}
+
+		if schedule := p.schedule(); !schedule.WorkNow() {
+			sleepTillNextWorkTime, err := schedule.NextWorkSession()
 			if err != nil {
 				p.logger.Error("Failed to get next work session", "error", err)
 				return nil
@@ -188,13 +602,26 @@ func (p *ProxyRotatingPoller) populateWithResponses(
 				sleepTillNextWorkTime.String(),
 			)
 
-			time.Sleep(sleepTillNextWorkTime)
+			if !p.sleep(ctx, sleepTillNextWorkTime) {
+				wg.Wait()
+				return ctx.Err()
+			}
 
-			p.notifier.SendMessage(
-				"Poller was resumed after sleep",
-			)
+			if p.schedule().WorkNow() {
+				p.notifier.SendMessage(
+					"Poller was resumed after sleep",
+				)
+			}
+
+			lastPollStartTime = time.Now()
+			workDebt = 0
+
+			// The poller may be paused or the schedule changed meanwhile
+			continue
 		}
 
+		p.applyProfile()
+
 		select {
 		case <-ctx.Done():
 			p.logger.Info(
@@ -202,7 +629,7 @@ func (p *ProxyRotatingPoller) populateWithResponses(
 			)
 			wg.Wait()
 
-			return nil
+			return ctx.Err()
 
 		default:
 			clients, releaseClients := p.clientsByLocation.Acquire()
@@ -215,6 +642,12 @@ func (p *ProxyRotatingPoller) populateWithResponses(
 				defer wg.Done()
 				defer releaseClients()
 
+				ctx, span := tracer.Start(ctx, SpanPoll, trace.WithAttributes(
+					attribute.String("endpoint", p.name),
+					attribute.Int("proxies", len(clients)),
+				))
+				defer span.End()
+
 				localWg := sync.WaitGroup{}
 
 				for _, client := range clients {
@@ -247,7 +680,7 @@ func (p *ProxyRotatingPoller) populateWithResponses(
 			// In this case, the poller will sleep for 0 seconds.
 			// If the time to sleep before next poll is positive, it means that the poller is behind the target RPS.
 			elapsed := time.Since(lastPollStartTime)
-			timeToSleepBeforeNextPoll := pollingInterval - elapsed - workDebt
+			timeToSleepBeforeNextPoll := p.pollingInterval() - elapsed - workDebt
 			workDebt = 0
 			if timeToSleepBeforeNextPoll < 0 {
 				workDebt = -timeToSleepBeforeNextPoll
@@ -272,16 +705,47 @@ func (p *ProxyRotatingPoller) fetchResponse(
 
 	p.metrics.IncrementCounter(MetricUpbitNewsRequestsTotal)
 
-	p.urlGuard.RLock()
-	defer p.urlGuard.RUnlock()
+	url := p.cacheBuster.Bust(p.nextURL())
 
-	response, err := client.Request(ctx, p.url)
+	ctx, span := tracer.Start(ctx, SpanRequest, trace.WithAttributes(
+		attribute.String("endpoint", p.name),
+		attribute.String("proxy.id", ProxyID(client.ProxyAddress())),
+		attribute.String("url.full", url),
+	))
+
+	response, err := client.Request(ctx, url)
+	p.proxiesHealth.record(client.ProxyAddress(), response, err)
 	if err != nil {
+		endRequestSpan(span, response, err)
 		p.metrics.IncrementCounter(MetricUpbitNewsErrorsTotal)
 		return Response{}, fmt.Errorf("failed to request news: %w", err)
 	}
 
+	response.URL = url
+	response.Freshness = EvaluateFreshness(response, p.clockOffset.Offset())
+	response.SpanContext = span.SpanContext()
+	endRequestSpan(span, response, nil)
+
+	p.clockOffset.observe(response, response.Freshness)
+	p.proxiesHealth.recordFreshness(client.ProxyAddress(), response.Freshness)
+
 	p.metrics.IncrementCounter(MetricUpbitNewsFetchedTotal)
+	p.metrics.ObserveHistogram(
+		MetricUpbitNewsStaleness,
+		response.Freshness.Staleness.Seconds(),
+		"proxy",
+		ProxyID(client.ProxyAddress()),
+		"endpoint",
+		p.name,
+	)
+	p.metrics.SetGauge(MetricUpbitNewsClockOffset, p.clockOffset.Offset().Seconds(), "endpoint", p.name)
+	p.metrics.IncrementCounter(
+		MetricUpbitNewsCacheStatusTotal,
+		"strategy",
+		p.cacheBuster.Strategy(),
+		"status",
+		CacheStatus(response.Headers),
+	)
 
 	return response, nil
 }
@@ -17,6 +17,7 @@ var (
 )
 
 type ProxyRotatingPollerBuilder struct {
+	name                string
 	url                 string
 	targetRPS           float64
 	singleProxyMaxRPS   float64
@@ -27,10 +28,12 @@ type ProxyRotatingPollerBuilder struct {
 	workSchedule        WorkSchedule
 	notifier            Notifier
 	metrics             Metrics
+	cacheBuster         CacheBuster
 }
 
 func NewProxyRotatingPollerBuilder() *ProxyRotatingPollerBuilder {
 	return &ProxyRotatingPollerBuilder{
+		name:     "default",
 		logger:   slog.New(slog.DiscardHandler),
 		notifier: noopNotifier{},
 		metrics:  noopMetrics{},
@@ -93,24 +96,43 @@ func (b *ProxyRotatingPollerBuilder) Build() (*ProxyRotatingPoller, error) {
 		)
 	}
 
+	proxiesHealth := newProxyHealthTracker(clients)
+	clientsByLocation.SetStaleness(proxiesHealth.staleness)
+
 	return &ProxyRotatingPoller{
-		url:               b.url,
+		name:              b.name,
+		urls:              []string{b.url},
 		targetRPS:         b.targetRPS,
 		singleProxyMaxRPS: b.singleProxyMaxRPS,
 		clientsByLocation: clientsByLocation,
-		totalClientsCount: len(clients),
+		initProxyClientFn: b.initProxyClientFn,
+		proxiesHealth:     proxiesHealth,
 		workSchedule:      b.workSchedule,
+		wakeup:            make(chan struct{}, 1),
 		logger:            b.logger,
 		notifier:          b.notifier,
 		metrics:           b.metrics,
+		cacheBuster:       b.cacheBuster,
 	}, nil
 }
 
+// WithName names the polled endpoint in the metrics
+func (b *ProxyRotatingPollerBuilder) WithName(name string) *ProxyRotatingPollerBuilder {
+	b.name = name
+	return b
+}
+
 func (b *ProxyRotatingPollerBuilder) WithURL(url string) *ProxyRotatingPollerBuilder {
 	b.url = url
 	return b
 }
 
+// WithCacheBuster makes the poller bust the CDN cache of the URLs on every request
+func (b *ProxyRotatingPollerBuilder) WithCacheBuster(cacheBuster CacheBuster) *ProxyRotatingPollerBuilder {
+	b.cacheBuster = cacheBuster
+	return b
+}
+
 func (b *ProxyRotatingPollerBuilder) WithTargetRPS(targetRPS float64) *ProxyRotatingPollerBuilder {
 	b.targetRPS = targetRPS
 	return b
@@ -170,6 +192,8 @@ func (b *ProxyRotatingPollerBuilder) UseFastHTTP(
 			Logger:              slog.New(slog.DiscardHandler),
 			Metrics:             noopMetrics{},
 			ClientRetriesConfig: cfg.ClientRetriesConfig,
+			Headers:             cfg.Headers,
+			HeaderKind:          cfg.HeaderKind,
 		})
 	}
 
@@ -197,6 +221,8 @@ func (b *ProxyRotatingPollerBuilder) UseHTTP2(config ...ClientConfig) *ProxyRota
 			Logger:              slog.New(slog.DiscardHandler),
 			Metrics:             noopMetrics{},
 			ClientRetriesConfig: cfg.ClientRetriesConfig,
+			Headers:             cfg.Headers,
+			HeaderKind:          cfg.HeaderKind,
 		})
 	}
 
@@ -238,6 +264,10 @@ func (n noopWorkSchedule) WorkNow() bool {
 	return true
 }
 
+func (n noopWorkSchedule) ProfileNow() (string, float64) {
+	return DefaultRPSProfile, 0
+}
+
 func (n noopWorkSchedule) NextWorkSession() (time.Duration, error) {
 	return 0, nil
 }
@@ -0,0 +1,91 @@
+package httptools
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// offSchedule is a work schedule with the next session far away
+type offSchedule struct{}
+
+func (offSchedule) WorkNow() bool {
+	return false
+}
+
+func (offSchedule) NextWorkSession() (time.Duration, error) {
+	return time.Hour, nil
+}
+
+func (offSchedule) ProfileNow() (string, float64) {
+	return DefaultRPSProfile, 0
+}
+
+func newSleepingPoller() *ProxyRotatingPoller {
+	clients := []Client{
+		respondingClient{fakeClient: fakeClient{proxy: "http://10.0.0.1:8080"}, status: http.StatusOK},
+	}
+
+	return &ProxyRotatingPoller{
+		name:              "notices",
+		urls:              []string{"https://example.com/notices"},
+		targetRPS:         1,
+		singleProxyMaxRPS: 1,
+		clientsByLocation: newClientsQueuePool(clients, time.Millisecond, locateByIP),
+		proxiesHealth:     newProxyHealthTracker(clients),
+		workSchedule:      offSchedule{},
+		wakeup:            make(chan struct{}, 1),
+		logger:            slog.New(slog.DiscardHandler),
+		notifier:          noopNotifier{},
+		metrics:           noopMetrics{},
+	}
+}
+
+func TestProxyRotatingPoller_SetWorkScheduleWakesUp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := newSleepingPoller()
+
+	responses, err := p.StartPolling(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-responses:
+		t.Fatal("polled outside of the work schedule")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	p.SetWorkSchedule(noopWorkSchedule{})
+
+	select {
+	case <-responses:
+	case <-time.After(time.Second):
+		t.Fatal("did not poll after the work schedule was changed")
+	}
+}
+
+func TestProxyRotatingPoller_CancelWhileSleeping(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	responses, err := newSleepingPoller().StartPolling(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case _, ok := <-responses:
+		if ok {
+			t.Fatal("polled outside of the work schedule")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("did not stop after the context was cancelled")
+	}
+}
@@ -0,0 +1,28 @@
+package httptools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProxyID(t *testing.T) {
+	first := Proxy{Username: "user", Password: "secret", Host: "proxy.example.com", Port: 8080}
+	second := Proxy{Username: "user-session-2", Password: "secret", Host: "proxy.example.com", Port: 8080}
+
+	id := ProxyID(first.String())
+	if strings.Contains(id, "secret") || strings.Contains(id, "user") {
+		t.Errorf("ProxyID() = %s, expected no credentials", id)
+	}
+
+	if !strings.HasPrefix(id, "proxy.example.com:8080#") {
+		t.Errorf("ProxyID() = %s, expected the host", id)
+	}
+
+	if id == ProxyID(second.String()) {
+		t.Error("ProxyID() should tell apart the proxies sharing a host")
+	}
+
+	if id := ProxyID("direct"); id != "direct" {
+		t.Errorf("ProxyID() = %s, expected direct", id)
+	}
+}
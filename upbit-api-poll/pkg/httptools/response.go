@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Response struct {
@@ -17,6 +18,14 @@ type Response struct {
 	Body        []byte      `json:"body"`
 	ProxyAddr   string      `json:"proxy_addr"`
 	ClientName  string      `json:"client_name"`
+	// HeaderProfile is the name of the browser header profile the request was sent with
+	HeaderProfile string `json:"header_profile"`
+	// URL is the polled URL with the cache-buster, set by ProxyRotatingPoller
+	URL string `json:"url"`
+	// Freshness is evaluated by ProxyRotatingPoller
+	Freshness Freshness `json:"freshness"`
+	// SpanContext is the span of the request, the parent of the spans processing the response
+	SpanContext trace.SpanContext `json:"-"`
 }
 
 func NewHTTPResponse(
@@ -0,0 +1,43 @@
+package httptools
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Spans of the poller, a poll tick is the root of its request and parse spans, news events found in the responses start their own trace linked to the parse span
+const (
+	SpanPoll    = "poll"
+	SpanRequest = "request"
+)
+
+// tracer delegates to the global provider, which may be set after the package is loaded
+var tracer = otel.Tracer("github.com/Shadow-Web3-development-studio/listings/upbit-api-poll/pkg/httptools")
+
+// endRequestSpan records the result of the request on its span and ends it
+func endRequestSpan(span trace.Span, response Response, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("http.response.status_code", response.StatusCode),
+		attribute.String("cache.status", response.Freshness.CacheStatus),
+		attribute.Float64("staleness_seconds", response.Freshness.Staleness.Seconds()),
+		attribute.String("header_profile", response.HeaderProfile),
+	)
+
+	if response.StatusCode >= 400 {
+		span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+	}
+
+	span.End()
+}
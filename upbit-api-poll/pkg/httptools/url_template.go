@@ -0,0 +1,104 @@
+package httptools
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Variables of the URL templates, written as {name}
+const (
+	VarID      = "id"
+	VarPage    = "page"
+	VarPerPage = "per_page"
+	VarLocale  = "locale"
+	// VarBuster is filled with the cache-buster of the poller on every request
+	VarBuster = "buster"
+)
+
+var (
+	knownURLVars = []string{VarID, VarPage, VarPerPage, VarLocale, VarBuster}
+
+	urlVarPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+)
+
+// URLTemplate is an endpoint with {name} variables. The variables are bound with With,
+// the unbound ones are left in the URL, so {buster} is filled by the poller per request.
+type URLTemplate struct {
+	raw    string
+	values map[string]string
+	// pattern matches the URLs of the template to extract the variables
+	pattern *regexp.Regexp
+}
+
+// NewURLTemplate parses the template. For the endpoints written for fmt.Sprintf,
+// a %d in the template is the legacy variable.
+func NewURLTemplate(raw, legacyVar string) URLTemplate {
+	if legacyVar != "" {
+		raw = strings.Replace(raw, "%d", "{"+legacyVar+"}", 1)
+	}
+
+	pattern := strings.Builder{}
+	pattern.WriteString("^")
+
+	last := 0
+	for _, match := range urlVarPattern.FindAllStringSubmatchIndex(raw, -1) {
+		pattern.WriteString(regexp.QuoteMeta(raw[last:match[0]]))
+		pattern.WriteString(fmt.Sprintf("(?P<%s>[^&/?#]*)", raw[match[2]:match[3]]))
+		last = match[1]
+	}
+
+	pattern.WriteString(regexp.QuoteMeta(raw[last:]))
+
+	// A variable used twice can't be a named group twice, such a template only can't be matched
+	compiled, _ := regexp.Compile(pattern.String())
+
+	return URLTemplate{raw: raw, values: map[string]string{}, pattern: compiled}
+}
+
+// CheckURLTemplate returns an error if the template has unknown variables
+func CheckURLTemplate(raw string) error {
+	for _, match := range urlVarPattern.FindAllStringSubmatch(raw, -1) {
+		if !slices.Contains(knownURLVars, match[1]) {
+			return fmt.Errorf("unknown variable {%s}, known are %v", match[1], knownURLVars)
+		}
+	}
+
+	return nil
+}
+
+// With returns the template with the variable bound to the value
+func (t URLTemplate) With(name string, value any) URLTemplate {
+	t.values = maps.Clone(t.values)
+	t.values[name] = fmt.Sprint(value)
+
+	return t
+}
+
+// String expands the bound variables
+func (t URLTemplate) String() string {
+	return urlVarPattern.ReplaceAllStringFunc(t.raw, func(variable string) string {
+		if value, ok := t.values[variable[1:len(variable)-1]]; ok {
+			return value
+		}
+
+		return variable
+	})
+}
+
+// Extract returns the value of the variable in the URL of the template.
+// Query parameters appended by a cache-buster are ignored.
+func (t URLTemplate) Extract(url, name string) (string, error) {
+	if t.pattern == nil || !slices.Contains(t.pattern.SubexpNames(), name) {
+		return "", fmt.Errorf("template %s has no variable {%s}", t.raw, name)
+	}
+
+	match := t.pattern.FindStringSubmatch(url)
+	if match == nil {
+		return "", fmt.Errorf("%s doesn't match the template %s", url, t.raw)
+	}
+
+	return match[t.pattern.SubexpIndex(name)], nil
+}
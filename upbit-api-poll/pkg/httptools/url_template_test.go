@@ -0,0 +1,95 @@
+package httptools
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestURLTemplate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		raw      string
+		legacy   string
+		expected string
+	}{
+		{"variables", "https://example.com/notices/{id}?lang={locale}", VarID, "https://example.com/notices/42?lang=ko"},
+		{"legacy %d", "https://example.com/notices?id=%d", VarID, "https://example.com/notices?id=42"},
+		{"unbound buster", "https://example.com/notices/{id}?v={buster}", VarID, "https://example.com/notices/42?v={buster}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			template := NewURLTemplate(tc.raw, tc.legacy)
+
+			url := template.With(VarID, 42).With(VarLocale, "ko").String()
+			if url != tc.expected {
+				t.Errorf("String() = %s, expected %s", url, tc.expected)
+			}
+
+			for _, strategy := range CacheBustingStrategies {
+				cacheBuster, err := NewCacheBuster(strategy)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				busted := cacheBuster.Bust(url)
+				if strings.Contains(busted, "{buster}") {
+					t.Errorf("%s Bust() = %s, expected the buster variable to be filled", strategy, busted)
+				}
+
+				if id, err := template.Extract(busted, VarID); err != nil || id != "42" {
+					t.Errorf("%s Extract(%s) = %q, %v, expected 42", strategy, busted, id, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheBuster_Bust(t *testing.T) {
+	random, _ := NewCacheBuster(CacheBustingRandom)
+
+	first := random.Bust("https://example.com/notices?id=1")
+	if !strings.HasPrefix(first, "https://example.com/notices?id=1&_cb=") {
+		t.Errorf("Bust() = %s, expected the cache-buster parameter", first)
+	}
+
+	if first == random.Bust("https://example.com/notices?id=1") {
+		t.Error("Bust() should return a unique URL on every call")
+	}
+
+	if url := random.Bust("https://example.com/notices/1"); !strings.HasPrefix(url, "https://example.com/notices/1?_cb=") {
+		t.Errorf("Bust() = %s, expected the query to be started", url)
+	}
+
+	none, _ := NewCacheBuster(CacheBustingNone)
+	if url := none.Bust("https://example.com/notices/1"); url != "https://example.com/notices/1" {
+		t.Errorf("Bust() = %s, expected the URL as is", url)
+	}
+
+	if _, err := NewCacheBuster("header"); err == nil {
+		t.Error("NewCacheBuster() should fail on an unknown strategy")
+	}
+}
+
+func TestCheckURLTemplate(t *testing.T) {
+	if err := CheckURLTemplate("https://example.com/notices/{id}?page={page}&per_page={per_page}&v={buster}"); err != nil {
+		t.Errorf("CheckURLTemplate() error = %v", err)
+	}
+
+	if err := CheckURLTemplate("https://example.com/notices/{notice}"); err == nil {
+		t.Error("CheckURLTemplate() should fail on an unknown variable")
+	}
+}
+
+func TestCacheStatus(t *testing.T) {
+	headers := http.Header{}
+	if status := CacheStatus(headers); status != "none" {
+		t.Errorf("CacheStatus() = %s, expected none", status)
+	}
+
+	headers.Set("Cf-Cache-Status", "HIT")
+	if status := CacheStatus(headers); status != "hit" {
+		t.Errorf("CacheStatus() = %s, expected hit", status)
+	}
+}